@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/strategy"
+	"agentic-llm-gateway/pkg/tokenizer"
+)
+
+// batchCase is one line of a batch dataset: a conversation with the expected scores of
+// its dimensions and, optionally, the provider the resolution strategy should pick.
+type batchCase struct {
+	Name     string             `json:"name,omitempty"`
+	Text     string             `json:"text,omitempty"` // shorthand for a single user message
+	Messages []models.Message   `json:"messages,omitempty"`
+	Model    string             `json:"model,omitempty"`  // for estimated_tokens
+	Vector   map[string]float64 `json:"vector,omitempty"` // router dimensions, e.g. hour
+	Labels   map[string]float64 `json:"labels,omitempty"` // expected score per dimension
+	Score    *float64           `json:"score,omitempty"`  // expected score of every selected evaluator
+	Expect   string             `json:"expect,omitempty"` // expected provider
+}
+
+// batchReport is the result of `eval-cli batch`, printed as text or JSON.
+type batchReport struct {
+	Cases      int               `json:"cases"`
+	Threshold  float64           `json:"threshold"`
+	Evaluators []evaluatorReport `json:"evaluators"`
+	Dimensions []dimensionReport `json:"dimensions"`
+	Routing    *routingReport    `json:"routing,omitempty"`
+}
+
+type evaluatorReport struct {
+	Name     string  `json:"name"`
+	Runs     int     `json:"runs"`
+	Failures int     `json:"failures"`
+	P50Ms    float64 `json:"p50_ms"`
+	P90Ms    float64 `json:"p90_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+	MaxMs    float64 `json:"max_ms"`
+	MeanMs   float64 `json:"mean_ms"`
+}
+
+// dimensionReport scores a dimension as a binary classifier: a label >= 0.5 is positive
+// and a score >= the threshold predicts positive.
+type dimensionReport struct {
+	Name      string  `json:"name"`
+	Labeled   int     `json:"labeled"`
+	Missing   int     `json:"missing"` // labeled cases whose evaluator failed
+	TP        int     `json:"tp"`
+	FP        int     `json:"fp"`
+	FN        int     `json:"fn"`
+	TN        int     `json:"tn"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Accuracy  float64 `json:"accuracy"`
+	MAE       float64 `json:"mae"` // mean absolute error between score and label
+}
+
+type routingReport struct {
+	Labeled  int     `json:"labeled"`
+	Correct  int     `json:"correct"`
+	Accuracy float64 `json:"accuracy"`
+	// Confusion counts the cases expecting a provider (first key) by resolved provider.
+	Confusion map[string]map[string]int `json:"confusion"`
+}
+
+// runBatch implements `eval-cli batch`: it runs the selected evaluators, all by default,
+// over a JSONL dataset and reports accuracy, latency and failures, so that prompt or
+// logit_bias changes can be compared on the same data.
+func runBatch(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	dataPath := fs.String("data", "", "Path to the JSONL dataset ({\"messages\" or \"text\": ..., \"labels\": {dimension: 0|1}, \"expect\": provider})")
+	names := fs.String("evaluators", "", "Comma-separated evaluators to run (default all)")
+	concurrency := fs.Int("concurrency", 4, "Cases evaluated in parallel")
+	threshold := fs.Float64("threshold", 0.5, "Score at or above which a dimension counts as positive")
+	format := fs.String("format", "text", "Report format: text or json")
+	outPath := fs.String("out", "", "Path of the report to write (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dataPath == "" {
+		return fmt.Errorf("please specify a dataset using -data")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown report format %q", *format)
+	}
+
+	conf, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if conf.GenerativeRouting == nil {
+		return fmt.Errorf("config does not have a generative_routing section")
+	}
+	var evalCfgs []config.EvaluatorConfig
+	if *names == "" {
+		evalCfgs = conf.GenerativeRouting.Evaluators
+	} else {
+		for _, name := range strings.Split(*names, ",") {
+			evalCfg, err := findEvaluator(conf, strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			evalCfgs = append(evalCfgs, evalCfg)
+		}
+	}
+	counter := tokenizer.NewCounter(conf.Tokenizer)
+	evals := make([]evaluator.Evaluator, 0, len(evalCfgs))
+	for _, evalCfg := range evalCfgs {
+		ev, err := newEvaluator(evalCfg, counter)
+		if err != nil {
+			return fmt.Errorf("failed to init evaluator %s: %w", evalCfg.Name, err)
+		}
+		evals = append(evals, ev)
+	}
+	cases, err := loadBatchCases(*dataPath)
+	if err != nil {
+		return err
+	}
+
+	var resolver strategy.Resolver
+	if conf.GenerativeRouting.Resolution.Type != "" {
+		resolver, err = strategy.NewResolver(conf.GenerativeRouting.Resolution, catalog.New(conf.ModelCatalog))
+		if err != nil {
+			return fmt.Errorf("invalid resolution strategy: %w", err)
+		}
+	}
+
+	// Run the cases
+	vectors := make([]map[string]float64, len(cases))
+	outcomes := make([][]evaluator.Outcome, len(cases))
+	sem := make(chan struct{}, max(*concurrency, 1))
+	var wg sync.WaitGroup
+	for i, c := range cases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			base := map[string]float64{strategy.DimEstimatedTokens: float64(counter.CountMessages(c.Model, c.Messages))}
+			maps.Copy(base, c.Vector)
+			vectors[i], outcomes[i] = evaluator.EvaluateStaged(context.Background(), c.Messages, conf.GenerativeRouting.GlobalTimeoutMs,
+				[][]evaluator.Evaluator{evals}, evaluator.StageOptions{Base: base})
+		}()
+	}
+	wg.Wait()
+
+	report := buildBatchReport(evalCfgs, cases, vectors, outcomes, resolver, *threshold)
+	var out bytes.Buffer
+	if *format == "json" {
+		enc := json.NewEncoder(&out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		writeBatchReport(&out, report)
+	}
+	if *outPath == "" {
+		_, err = stdout.Write(out.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*outPath, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// loadBatchCases reads a JSONL batch dataset. Blank lines are skipped.
+func loadBatchCases(path string) ([]batchCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	var cases []batchCase
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var c batchCase
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if len(c.Messages) == 0 && c.Text != "" {
+			c.Messages = []models.Message{{Role: "user", Content: c.Text}}
+		}
+		if len(c.Messages) == 0 {
+			return nil, fmt.Errorf("%s:%d: no messages", path, line)
+		}
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("line %d", line)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no cases in %s", path)
+	}
+	return cases, nil
+}
+
+func buildBatchReport(evalCfgs []config.EvaluatorConfig, cases []batchCase, vectors []map[string]float64, outcomes [][]evaluator.Outcome, resolver strategy.Resolver, threshold float64) *batchReport {
+	report := &batchReport{Cases: len(cases), Threshold: threshold}
+
+	// Latency and failures per evaluator
+	for j, evalCfg := range evalCfgs {
+		er := evaluatorReport{Name: evalCfg.Name}
+		var latencies []float64
+		for i := range cases {
+			o := outcomes[i][j]
+			er.Runs++
+			if o.Err != nil {
+				er.Failures++
+			}
+			latencies = append(latencies, float64(o.Latency.Microseconds())/1000)
+		}
+		slices.Sort(latencies)
+		er.P50Ms, er.P90Ms, er.P99Ms = percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99)
+		er.MaxMs = latencies[len(latencies)-1]
+		for _, l := range latencies {
+			er.MeanMs += l / float64(len(latencies))
+		}
+		report.Evaluators = append(report.Evaluators, er)
+	}
+
+	// Confusion per labeled dimension, in evaluator order
+	for _, evalCfg := range evalCfgs {
+		for _, dim := range evaluator.Dimensions(evalCfg) {
+			if dr, ok := dimensionConfusion(dim, dim == evalCfg.Name, cases, vectors, threshold); ok {
+				report.Dimensions = append(report.Dimensions, dr)
+			}
+		}
+	}
+
+	// Provider decisions of the resolution strategy
+	if resolver == nil {
+		return report
+	}
+	rr := &routingReport{Confusion: make(map[string]map[string]int)}
+	for i, c := range cases {
+		if c.Expect == "" {
+			continue
+		}
+		var got string
+		if tr, ok := resolver.(strategy.TargetResolver); ok {
+			got, _ = tr.ResolveTarget(vectors[i])
+		} else {
+			got = resolver.Resolve(vectors[i])
+		}
+		rr.Labeled++
+		if got == c.Expect {
+			rr.Correct++
+		}
+		if rr.Confusion[c.Expect] == nil {
+			rr.Confusion[c.Expect] = make(map[string]int)
+		}
+		rr.Confusion[c.Expect][got]++
+	}
+	if rr.Labeled > 0 {
+		rr.Accuracy = ratio(rr.Correct, rr.Labeled)
+		report.Routing = rr
+	}
+	return report
+}
+
+// dimensionConfusion scores dim over the cases labeling it, explicitly or, when
+// shorthand is set, through their score. ok is false when no case does.
+func dimensionConfusion(dim string, shorthand bool, cases []batchCase, vectors []map[string]float64, threshold float64) (dr dimensionReport, ok bool) {
+	dr.Name = dim
+	absErr := 0.0
+	for i, c := range cases {
+		label, labeled := c.Labels[dim]
+		if !labeled && shorthand && c.Score != nil {
+			label, labeled = *c.Score, true
+		}
+		if !labeled {
+			continue
+		}
+		dr.Labeled++
+		score, scored := vectors[i][dim]
+		if !scored {
+			dr.Missing++
+			continue
+		}
+		absErr += math.Abs(score - label)
+		switch expected, predicted := label >= 0.5, score >= threshold; {
+		case expected && predicted:
+			dr.TP++
+		case !expected && predicted:
+			dr.FP++
+		case expected && !predicted:
+			dr.FN++
+		default:
+			dr.TN++
+		}
+	}
+	if dr.Labeled == 0 {
+		return dr, false
+	}
+	dr.Precision = ratio(dr.TP, dr.TP+dr.FP)
+	dr.Recall = ratio(dr.TP, dr.TP+dr.FN)
+	if dr.Precision+dr.Recall > 0 {
+		dr.F1 = 2 * dr.Precision * dr.Recall / (dr.Precision + dr.Recall)
+	}
+	if scored := dr.Labeled - dr.Missing; scored > 0 {
+		dr.Accuracy = ratio(dr.TP+dr.TN, scored)
+		dr.MAE = absErr / float64(scored)
+	}
+	return dr, true
+}
+
+func writeBatchReport(w io.Writer, r *batchReport) {
+	fmt.Fprintln(w, "=== Batch Evaluation Report ===")
+	fmt.Fprintf(w, "Cases: %d, threshold %.2f\n\n", r.Cases, r.Threshold)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "evaluator\truns\tfailed\tp50 ms\tp90 ms\tp99 ms\tmax ms\tmean ms\t")
+	for _, e := range r.Evaluators {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n", e.Name, e.Runs, e.Failures, e.P50Ms, e.P90Ms, e.P99Ms, e.MaxMs, e.MeanMs)
+	}
+	tw.Flush()
+
+	if len(r.Dimensions) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "dimension\tlabeled\tmissing\ttp\tfp\tfn\ttn\tprecision\trecall\tf1\taccuracy\tmae\t")
+		for _, d := range r.Dimensions {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t\n",
+				d.Name, d.Labeled, d.Missing, d.TP, d.FP, d.FN, d.TN, d.Precision, d.Recall, d.F1, d.Accuracy, d.MAE)
+		}
+		tw.Flush()
+	}
+
+	if r.Routing != nil {
+		fmt.Fprintf(w, "\nRouting: %d of %d cases resolved to the expected provider (%.1f%%)\n", r.Routing.Correct, r.Routing.Labeled, 100*r.Routing.Accuracy)
+		providers := slices.Collect(maps.Keys(r.Routing.Confusion))
+		for _, got := range r.Routing.Confusion {
+			for p := range got {
+				if !slices.Contains(providers, p) {
+					providers = append(providers, p)
+				}
+			}
+		}
+		slices.Sort(providers)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(tw, "expected \\ resolved\t%s\t\n", strings.Join(providers, "\t"))
+		for _, expected := range providers {
+			row, ok := r.Routing.Confusion[expected]
+			if !ok {
+				continue
+			}
+			counts := make([]string, len(providers))
+			for k, p := range providers {
+				counts[k] = fmt.Sprint(row[p])
+			}
+			fmt.Fprintf(tw, "%s\t%s\t\n", expected, strings.Join(counts, "\t"))
+		}
+		tw.Flush()
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
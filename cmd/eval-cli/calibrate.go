@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/tokenizer"
+)
+
+// runCalibrate implements `eval-cli calibrate`: it runs an evaluator over a labeled JSONL
+// dataset, fits a calibration of its raw scores, suggests rule thresholds for the
+// precision targets and writes the calibration block of the evaluator config.
+func runCalibrate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	configPath := fs.String("config", "config.yaml", "Path to config file")
+	evaluatorName := fs.String("evaluator", "", "Name of the evaluator to calibrate")
+	dataPath := fs.String("data", "", "Path to the labeled JSONL dataset ({\"text\" or \"messages\": ..., \"score\": 0|1})")
+	method := fs.String("method", evaluator.CalibrationPlatt, "Calibration method: platt or isotonic")
+	precisions := fs.String("precision", "0.8,0.9,0.95", "Comma-separated precision targets to suggest thresholds for")
+	outPath := fs.String("out", "", "Path of the calibration block to write (default stdout)")
+	concurrency := fs.Int("concurrency", 4, "Evaluations run in parallel")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *evaluatorName == "" || *dataPath == "" {
+		return fmt.Errorf("please specify an evaluator using -evaluator and a dataset using -data")
+	}
+	var targets []float64
+	for _, p := range strings.Split(*precisions, ",") {
+		target, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || target <= 0 || target > 1 {
+			return fmt.Errorf("invalid precision target %q", p)
+		}
+		targets = append(targets, target)
+	}
+
+	conf, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	evalCfg, err := findEvaluator(conf, *evaluatorName)
+	if err != nil {
+		return err
+	}
+	evalCfg.Calibration = nil // fit the raw scores
+	ev, err := newEvaluator(evalCfg, tokenizer.NewCounter(conf.Tokenizer))
+	if err != nil {
+		return fmt.Errorf("failed to init evaluator: %w", err)
+	}
+	examples, err := evaluator.LoadExamples(*dataPath)
+	if err != nil {
+		return err
+	}
+
+	raw, positive, failed := scoreExamples(ev, examples, *concurrency)
+	if len(raw) == 0 {
+		return fmt.Errorf("all %d evaluations failed", failed)
+	}
+	var calibration *evaluator.Calibration
+	switch *method {
+	case evaluator.CalibrationPlatt:
+		calibration = evaluator.FitPlatt(raw, positive)
+	case evaluator.CalibrationIsotonic:
+		calibration = evaluator.FitIsotonic(raw, positive)
+	default:
+		return fmt.Errorf("unknown calibration method %q", *method)
+	}
+	calibrated := make([]float64, len(raw))
+	for i, s := range raw {
+		calibrated[i] = calibration.Apply(s)
+	}
+
+	fmt.Fprintln(os.Stderr, "=== Calibration Result ===")
+	fmt.Fprintf(os.Stderr, "Examples:            %d (%d failed)\n", len(raw), failed)
+	fmt.Fprintf(os.Stderr, "Brier Score (raw):   %.4f\n", evaluator.BrierScore(raw, positive))
+	fmt.Fprintf(os.Stderr, "Brier Score (calib): %.4f\n", evaluator.BrierScore(calibrated, positive))
+	fmt.Fprintln(os.Stderr, "Suggested thresholds (calibrated score >= threshold):")
+	for _, target := range targets {
+		if s, ok := evaluator.SuggestThreshold(calibrated, positive, target); ok {
+			fmt.Fprintf(os.Stderr, "  precision >= %.2f: %s >= %.4f (precision %.3f, recall %.3f)\n", target, evalCfg.Name, s.Threshold, s.Precision, s.Recall)
+		} else {
+			fmt.Fprintf(os.Stderr, "  precision >= %.2f: not reachable on this dataset\n", target)
+		}
+	}
+
+	block, err := yaml.Marshal(map[string]interface{}{"calibration": calibration.Config()})
+	if err != nil {
+		return err
+	}
+	if *outPath == "" {
+		_, err = stdout.Write(block)
+		return err
+	}
+	if err := os.WriteFile(*outPath, block, 0644); err != nil {
+		return fmt.Errorf("failed to write calibration: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Calibration:         %s\n", *outPath)
+	return nil
+}
+
+// scoreExamples evaluates every example with up to concurrency evaluations in flight. It
+// returns the scores and labels of the successful evaluations and the failure count.
+func scoreExamples(ev evaluator.Evaluator, examples []evaluator.LabeledExample, concurrency int) (scores []float64, positive []bool, failed int) {
+	results := make([]*evaluator.EvaluationResult, len(examples))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, ex := range examples {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := ev.Evaluate(context.Background(), ex.Conversation())
+			if err != nil {
+				logger.Warnf("Example %d failed: %v", i+1, err)
+				return
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	for i, res := range results {
+		if res == nil {
+			failed++
+			continue
+		}
+		scores = append(scores, res.Score)
+		positive = append(positive, examples[i].Score >= 0.5)
+	}
+	return scores, positive, failed
+}
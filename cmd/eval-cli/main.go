@@ -6,69 +6,78 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"agentic-llm-gateway/internal/config"
 	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/router"
 	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/tokenizer"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "train" {
+		if err := runTrain(os.Args[2:]); err != nil {
+			logger.Fatalf("Training failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		if err := runBatch(os.Args[2:], os.Stdout); err != nil {
+			logger.Fatalf("Batch evaluation failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "calibrate" {
+		if err := runCalibrate(os.Args[2:], os.Stdout); err != nil {
+			logger.Fatalf("Calibration failed: %v", err)
+		}
+		return
+	}
+
 	var configPath string
 	var evaluatorName string
 	var inputPath string
+	var validate bool
 
 	flag.StringVar(&configPath, "config", "config.yaml", "Path to config file")
 	flag.StringVar(&evaluatorName, "evaluator", "", "Name of the evaluator to run")
 	flag.StringVar(&inputPath, "input", "mock_chat.json", "Path to mock chat history JSON")
+	flag.BoolVar(&validate, "validate", false, "Validate the routing config and run the resolution_strategy tests, then exit")
 	flag.Parse()
 
-	if evaluatorName == "" {
+	if !validate && evaluatorName == "" {
 		logger.Fatal("Please specify an evaluator using --evaluator")
 	}
 
 	// 1. Load config manually to skip full system defaults
-	f, err := os.Open(configPath)
+	conf, err := loadConfig(configPath)
 	if err != nil {
-		logger.Fatalf("Failed to open config %s: %v", configPath, err)
-	}
-	defer f.Close()
-
-	var conf config.Config
-	if err := yaml.NewDecoder(f).Decode(&conf); err != nil {
-		logger.Fatalf("Failed to decode config: %v", err)
+		logger.Fatal(err)
 	}
 
-	if conf.GenerativeRouting == nil {
-		logger.Fatal("Config does not have a generative_routing section")
+	if validate {
+		if err := validateConfig(conf); err != nil {
+			fmt.Fprintf(os.Stderr, "Config is invalid:\n%v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Config is valid")
+		return
 	}
 
 	// 2. Find Evaluator
-	var evalCfg config.EvaluatorConfig
-	found := false
-	for _, e := range conf.GenerativeRouting.Evaluators {
-		if e.Name == evaluatorName {
-			evalCfg = e
-			found = true
-			break
-		}
-	}
-	if !found {
-		logger.Fatalf("Evaluator %s not found in config", evaluatorName)
+	evalCfg, err := findEvaluator(conf, evaluatorName)
+	if err != nil {
+		logger.Fatal(err)
 	}
 
 	// 3. Initialize Evaluator
-	var ev evaluator.Evaluator
-	if evalCfg.Type == "llm_api" {
-		ev, err = evaluator.NewLLMAPIEvaluator(evalCfg)
-	} else if evalCfg.Type == "builtin" {
-		ev = evaluator.NewBuiltinLengthEvaluator(evalCfg)
-	} else {
-		logger.Fatalf("Unknown evaluator type: %s", evalCfg.Type)
-	}
+	ev, err := newEvaluator(evalCfg, tokenizer.NewCounter(conf.Tokenizer))
 	if err != nil {
 		logger.Fatalf("Failed to init evaluator: %v", err)
 	}
@@ -98,6 +107,59 @@ func main() {
 	// 6. Output Result
 	fmt.Println("=== Evaluation Result ===")
 	fmt.Printf("Evaluator Dimension: %s\n", res.Dimension)
-	fmt.Printf("Score:               %v\n", res.Score)
+	if res.Dimensions != nil {
+		for _, dim := range slices.Sorted(maps.Keys(res.Dimensions)) {
+			fmt.Printf("  %-18s %v\n", dim+":", res.Dimensions[dim])
+		}
+	} else {
+		fmt.Printf("Score:               %v\n", res.Score)
+	}
 	fmt.Printf("Time Taken (TTFT):   %s\n", elapsed)
 }
+
+// loadConfig decodes the config file at path without applying the server defaults.
+func loadConfig(path string) (*config.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var conf config.Config
+	if err := yaml.NewDecoder(f).Decode(&conf); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return &conf, nil
+}
+
+// findEvaluator returns the generative_routing evaluator of conf called name.
+func findEvaluator(conf *config.Config, name string) (config.EvaluatorConfig, error) {
+	if conf.GenerativeRouting == nil {
+		return config.EvaluatorConfig{}, fmt.Errorf("config does not have a generative_routing section")
+	}
+	for _, e := range conf.GenerativeRouting.Evaluators {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return config.EvaluatorConfig{}, fmt.Errorf("evaluator %s not found in config", name)
+}
+
+// newEvaluator creates the evaluator of cfg and waits for its background setup, e.g. the
+// example embeddings of embedding_knn, which the gateway does not wait for.
+func newEvaluator(cfg config.EvaluatorConfig, counter *tokenizer.Counter) (evaluator.Evaluator, error) {
+	ev, err := evaluator.New(cfg, evaluator.Deps{Counter: counter})
+	if err != nil {
+		return nil, err
+	}
+	if err := evaluator.WaitReady(context.Background(), ev); err != nil {
+		return nil, err
+	}
+	return ev, nil
+}
+
+// validateConfig validates conf as the server does at startup, taking every configured
+// provider as available.
+func validateConfig(conf *config.Config) error {
+	return router.ValidateConfig(conf, slices.Collect(maps.Keys(conf.Providers)))
+}
@@ -1,9 +1,19 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/evaluator"
 )
 
 func TestEvalCli_Main_HappyPath(t *testing.T) {
@@ -51,3 +61,222 @@ generative_routing:
 	// Will print strictly to stdout, but we just want to ensure it completes without fataling.
 	main()
 }
+
+func TestEvalCli_ValidateConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configYAML := `
+providers:
+  local_vllm: {base_url: "http://localhost:8000/v1"}
+  google: {api_key: "x"}
+generative_routing:
+  enabled: true
+  evaluators:
+    - name: complexity
+      type: builtin
+  resolution_strategy:
+    type: dynamic_expression
+    rules:
+      - condition: "complexity > 0.5"
+        target_provider: google
+    default_provider: local_vllm
+    tests:
+      - vector: {complexity: 0.9}
+        expect: google
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	os.Args = []string{"eval-cli", "-config", configPath, "-validate"}
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError) // main registers its flags again
+	main()
+
+	var conf config.Config
+	if err := yaml.Unmarshal([]byte(configYAML), &conf); err != nil {
+		t.Fatal(err)
+	}
+	conf.GenerativeRouting.Resolution.Rules[0].TargetProvider = "openai"
+	if err := validateConfig(&conf); err == nil {
+		t.Error("expected an unconfigured target provider to be rejected")
+	}
+
+	// the rest of the routing config is reported too
+	conf.GenerativeRouting.Resolution.Rules[0].TargetProvider = "google"
+	conf.Routes = []config.RouteConfig{{Name: "typo", Match: config.RouteMatch{Expression: "complexty > 0.5"}}}
+	conf.Schedules = []config.Schedule{{Name: "night", TimeWindow: config.TimeWindow{Start: "25:00"}}}
+	conf.SessionAffinity = config.SessionAffinityConfig{Enabled: true, Escalation: []string{"complexity >"}}
+	conf.Cascade.Verifiers = []config.VerifierConfig{{Type: "regex", Patterns: []string{"("}}}
+	conf.Shadow = config.ShadowConfig{Enabled: true, Provider: "google", SampleRate: 1, OutputPath: "shadow.jsonl", Filter: "Req.Nope"}
+	err := validateConfig(&conf)
+	for _, want := range []string{"route typo", "schedule night", "escalation rule 0", "cascade.verifiers[0]", "shadow filter"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q to be reported, got %v", want, err)
+		}
+	}
+}
+
+func TestEvalCli_Train(t *testing.T) {
+	tmpDir := t.TempDir()
+	dataPath := filepath.Join(tmpDir, "data.jsonl")
+	modelPath := filepath.Join(tmpDir, "model.json")
+	data := `{"text": "fix my code", "score": 1}
+{"text": "tell me a joke", "score": 0}
+`
+	if err := os.WriteFile(dataPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runTrain([]string{"-data", dataPath, "-out", modelPath, "-bits", "8"}); err != nil {
+		t.Fatalf("train failed: %v", err)
+	}
+	if _, err := os.Stat(modelPath); err != nil {
+		t.Errorf("expected the model file to be written: %v", err)
+	}
+	if err := runTrain([]string{"-out", modelPath}); err == nil {
+		t.Error("expected a missing dataset to be rejected")
+	}
+}
+
+func TestEvalCli_Calibrate(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	dataPath := filepath.Join(tmpDir, "data.jsonl")
+	outPath := filepath.Join(tmpDir, "calibration.yaml")
+	configYAML := `
+generative_routing:
+  enabled: true
+  evaluators:
+    - name: legal
+      type: builtin_keywords
+      rules:
+        - keywords: ["contract"]
+`
+	data := `{"text": "review this contract", "score": 1}
+{"text": "contract law question", "score": 1}
+{"text": "a contract bridge hand", "score": 0}
+{"messages": [{"role": "user", "content": "tell me a joke"}], "score": 0}
+{"text": "what is the weather", "score": 0}
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dataPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"-config", configPath, "-evaluator", "legal", "-data", dataPath, "-method", "isotonic", "-out", outPath}
+	if err := runCalibrate(args, io.Discard); err != nil {
+		t.Fatalf("calibrate failed: %v", err)
+	}
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block struct {
+		Calibration config.CalibrationConfig `yaml:"calibration"`
+	}
+	if err := yaml.Unmarshal(raw, &block); err != nil {
+		t.Fatal(err)
+	}
+	// two of the three keyword matches are legal
+	cal, err := evaluator.NewCalibration(block.Calibration)
+	if err != nil {
+		t.Fatalf("expected a valid calibration block, got %v: %s", err, raw)
+	}
+	if p := cal.Apply(1); math.Abs(p-2.0/3) > 1e-9 {
+		t.Errorf("expected P(legal | match) = 2/3, got %v", p)
+	}
+
+	if err := runCalibrate([]string{"-config", configPath, "-evaluator", "legal", "-data", dataPath, "-method", "magic"}, io.Discard); err == nil {
+		t.Error("expected an unknown method to be rejected")
+	}
+	if err := runCalibrate([]string{"-config", configPath, "-data", dataPath}, io.Discard); err == nil {
+		t.Error("expected a missing evaluator to be rejected")
+	}
+}
+
+func TestEvalCli_Batch(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	dataPath := filepath.Join(tmpDir, "data.jsonl")
+	reportPath := filepath.Join(tmpDir, "report.json")
+	configYAML := `
+generative_routing:
+  enabled: true
+  evaluators:
+    - name: legal
+      type: builtin_keywords
+      rules:
+        - keywords: ["contract"]
+    - name: code
+      type: builtin_code
+  resolution_strategy:
+    type: dynamic_expression
+    rules:
+      - condition: "legal > 0.5"
+        target_provider: google
+    default_provider: local_vllm
+`
+	data := "{\"text\": \"review this contract\", \"labels\": {\"legal\": 1, \"code\": 0}, \"expect\": \"google\"}\n" +
+		"{\"text\": \"a contract bridge hand\", \"score\": 0, \"expect\": \"local_vllm\"}\n" +
+		"{\"messages\": [{\"role\": \"user\", \"content\": \"my GDPR question\"}], \"labels\": {\"legal\": 1}, \"expect\": \"google\"}\n" +
+		"{\"text\": \"```go\\nfmt.Println()\\n```\", \"labels\": {\"code\": 1}}\n"
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dataPath, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var text strings.Builder
+	if err := runBatch([]string{"-config", configPath, "-data", dataPath}, &text); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	for _, want := range []string{"legal", "code", "expected \\ resolved", "1 of 3"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("expected %q in the text report:\n%s", want, text.String())
+		}
+	}
+
+	args := []string{"-config", configPath, "-data", dataPath, "-evaluators", "legal", "-format", "json", "-out", reportPath, "-concurrency", "2"}
+	if err := runBatch(args, io.Discard); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	raw, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report batchReport
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Cases != 4 || len(report.Evaluators) != 1 || report.Evaluators[0].Runs != 4 || report.Evaluators[0].Failures != 0 {
+		t.Errorf("unexpected evaluator report %+v", report)
+	}
+	// the bridge hand is a false positive, the GDPR question a false negative
+	legal := report.Dimensions[0]
+	if legal.Name != "legal" || legal.Labeled != 3 || legal.TP != 1 || legal.FP != 1 || legal.FN != 1 || legal.Precision != 0.5 {
+		t.Errorf("unexpected legal confusion %+v", legal)
+	}
+	if report.Routing == nil || report.Routing.Labeled != 3 || report.Routing.Correct != 1 || report.Routing.Confusion["local_vllm"]["google"] != 1 {
+		t.Errorf("unexpected routing report %+v", report.Routing)
+	}
+
+	if err := runBatch([]string{"-config", configPath, "-data", dataPath, "-evaluators", "nope"}, io.Discard); err == nil {
+		t.Error("expected an unknown evaluator to be rejected")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if p := percentile(sorted, 50); p != 5 {
+		t.Errorf("p50 = %v, want 5", p)
+	}
+	if p := percentile(sorted, 99); p != 10 {
+		t.Errorf("p99 = %v, want 10", p)
+	}
+	if p := percentile(nil, 50); p != 0 {
+		t.Errorf("p50 of nothing = %v, want 0", p)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"agentic-llm-gateway/pkg/evaluator"
+)
+
+// runTrain implements `eval-cli train`: it fits a classifier evaluator model to a
+// labeled JSONL dataset and saves it for the "classifier" evaluator type.
+func runTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ContinueOnError)
+	dataPath := fs.String("data", "", "Path to the labeled JSONL dataset ({\"text\": ..., \"score\": 0..1})")
+	outPath := fs.String("out", "classifier.json", "Path of the model file to write")
+	var opts evaluator.TrainOptions
+	fs.IntVar(&opts.Bits, "bits", evaluator.DefaultClassifierBits, "Feature hash size as a power of two")
+	fs.IntVar(&opts.NGrams, "ngrams", evaluator.DefaultClassifierNGrams, "Longest word n-gram used as a feature")
+	fs.IntVar(&opts.Epochs, "epochs", evaluator.DefaultClassifierEpochs, "Passes over the dataset")
+	fs.Float64Var(&opts.LearningRate, "rate", evaluator.DefaultClassifierRate, "Initial learning rate")
+	fs.Float64Var(&opts.L2, "l2", 0, "L2 regularization strength")
+	fs.Int64Var(&opts.Seed, "seed", 1, "Shuffle seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dataPath == "" {
+		return fmt.Errorf("please specify a dataset using -data")
+	}
+
+	examples, err := evaluator.LoadExamples(*dataPath)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	model, err := evaluator.TrainClassifier(examples, opts)
+	if err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+	if err := model.Save(*outPath); err != nil {
+		return fmt.Errorf("failed to save model: %w", err)
+	}
+
+	correct := 0
+	for _, ex := range examples {
+		if (model.Predict(ex.Text) >= 0.5) == (ex.Score >= 0.5) {
+			correct++
+		}
+	}
+	fmt.Println("=== Training Result ===")
+	fmt.Printf("Examples:          %d\n", len(examples))
+	fmt.Printf("Training Accuracy: %.1f%%\n", 100*float64(correct)/float64(len(examples)))
+	fmt.Printf("Time Taken:        %s\n", elapsed)
+	fmt.Printf("Model:             %s\n", *outPath)
+	return nil
+}
@@ -28,9 +28,10 @@ func (p *MockProvider) ChatCompletion(ctx context.Context, req *models.ChatCompl
 		Created: time.Now().Unix(),
 		Model:   req.Model,
 		Choices: []struct {
-			Index        int            `json:"index"`
-			Message      models.Message `json:"message"`
-			FinishReason string         `json:"finish_reason"`
+			Index        int              `json:"index"`
+			Message      models.Message   `json:"message"`
+			FinishReason string           `json:"finish_reason"`
+			Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 		}{{
 			Index: 0,
 			Message: models.Message{
@@ -70,7 +71,8 @@ func (p *MockProvider) ChatCompletionStream(ctx context.Context, req *models.Cha
 							Role    string `json:"role,omitempty"`
 							Content string `json:"content,omitempty"`
 						} `json:"delta"`
-						FinishReason *string `json:"finish_reason"`
+						FinishReason *string          `json:"finish_reason"`
+						Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 					}{{
 						Index: 0,
 						Delta: struct {
@@ -92,7 +94,10 @@ func main() {
 		"mock": &MockProvider{},
 	}
 
-	engine := router.NewEngine(providerMap)
+	engine, err := router.NewEngine(providerMap)
+	if err != nil {
+		logger.Fatalf("Mock strategy engine failed: %v", err)
+	}
 
 	// Create a dummy remote manager that just returns the mock provider
 	rm := config.NewRemoteManager("", 0, nil)
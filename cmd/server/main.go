@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 
 	"agentic-llm-gateway/internal/config"
 	"agentic-llm-gateway/internal/providers"
@@ -10,7 +12,10 @@ import (
 	"agentic-llm-gateway/internal/providers/openai"
 	"agentic-llm-gateway/internal/router"
 	"agentic-llm-gateway/internal/server"
+	"agentic-llm-gateway/internal/shadow"
+	"agentic-llm-gateway/pkg/catalog"
 	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/tokenizer"
 )
 
 func main() {
@@ -66,12 +71,29 @@ func main() {
 	anthropic.SetFallbackGetter(fallbackGetter)
 	google.SetFallbackGetter(fallbackGetter)
 
+	// Refuse to start with routing rules that would silently never fire.
+	if err := router.ValidateConfig(cfg, slices.Collect(maps.Keys(providerMap))); err != nil {
+		logger.Fatalf("Fatal validating routing config: %v", err)
+	}
+
 	// Init strategy engine and remote strategy manager.
-	engine := router.NewEngine(providerMap)
+	engine, err := router.NewEngine(providerMap)
+	if err != nil {
+		logger.Fatalf("Fatal initialising strategy engine: %v", err)
+	}
 	rm.Start()
 
+	mirror, err := shadow.New(cfg.Shadow, providerMap)
+	if err != nil {
+		logger.Fatalf("Fatal initialising shadow mirroring: %v", err)
+	}
+
 	// Init and start HTTP server.
-	srv := server.NewServer(rm, engine)
+	srv := server.NewServer(rm, engine,
+		server.WithCatalog(catalog.New(cfg.ModelCatalog)),
+		server.WithTokenCounter(tokenizer.NewCounter(cfg.Tokenizer)),
+		server.WithShadow(mirror),
+	)
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	if err := srv.Start(addr); err != nil {
 		logger.Fatalf("Server stopped: %v", err)
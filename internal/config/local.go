@@ -16,6 +16,170 @@ type Config struct {
 	RemoteStrategy    RemoteStrategyConfig      `yaml:"remote_strategy"`
 	Providers         map[string]ProviderConfig `yaml:"providers"`
 	GenerativeRouting *GenerativeRoutingConfig  `yaml:"generative_routing,omitempty"`
+	ModelCatalog      []ModelCatalogEntry       `yaml:"model_catalog,omitempty"`
+	Tokenizer         TokenizerConfig           `yaml:"tokenizer,omitempty"`
+	ContextGuard      ContextGuardConfig        `yaml:"context_guard,omitempty"`
+	SessionAffinity   SessionAffinityConfig     `yaml:"session_affinity,omitempty"`
+	Shadow            ShadowConfig              `yaml:"shadow,omitempty"`
+	RoutingHints      RoutingHintsConfig        `yaml:"routing_hints,omitempty"`
+	ModelAliases      map[string]ModelAlias     `yaml:"model_aliases,omitempty"`
+	Routes            []RouteConfig             `yaml:"routes,omitempty"`
+	Schedules         []Schedule                `yaml:"schedules,omitempty"`
+	Cascade           CascadeConfig             `yaml:"cascade,omitempty"`
+
+	// KeyLabels names callers by API key (the bearer token) for routing expressions.
+	KeyLabels map[string]string `yaml:"key_labels,omitempty"`
+
+	// Timezone is the IANA zone of the time-of-day routing variables and of time windows
+	// without their own timezone. Empty means UTC.
+	Timezone string `yaml:"timezone,omitempty"`
+}
+
+// CascadeConfig configures the "cascade" strategy: requests go to local_vllm first and
+// are re-sent to the remote provider when a verifier rejects the local answer.
+type CascadeConfig struct {
+	// BufferTokens is the streaming decision point: this many tokens of the local
+	// answer are buffered and verified before anything reaches the client. Default 64.
+	BufferTokens int              `yaml:"buffer_tokens,omitempty"`
+	Verifiers    []VerifierConfig `yaml:"verifiers,omitempty"` // all must accept; default refusal/uncertainty regexes
+}
+
+// VerifierConfig configures one check of a cascaded local answer.
+type VerifierConfig struct {
+	Type          string           `yaml:"type"`                     // "regex", "logprob" or "judge"
+	Patterns      []string         `yaml:"patterns,omitempty"`       // regex: answers matching any pattern are rejected
+	MinConfidence float64          `yaml:"min_confidence,omitempty"` // logprob: minimum geometric mean token probability
+	Judge         *EvaluatorConfig `yaml:"judge,omitempty"`          // judge: scores the conversation ending with the answer
+	MinScore      float64          `yaml:"min_score,omitempty"`      // judge: minimum score to accept
+}
+
+// RouteConfig is one entry of the ordered route table. The first route that matches and
+// whose target resolves decides; the built-in routes (generative, expression, split and
+// strategy, in that order) run after the table.
+type RouteConfig struct {
+	Name       string          `yaml:"name"`
+	Match      RouteMatch      `yaml:"match,omitempty"`
+	Target     RouteTarget     `yaml:"target"`
+	Transforms RouteTransforms `yaml:"transforms,omitempty"`
+}
+
+// RouteMatch lists the conditions a request must all meet. Empty conditions match anything.
+type RouteMatch struct {
+	Models      []string          `yaml:"models,omitempty"`       // requested model globs
+	Paths       []string          `yaml:"paths,omitempty"`        // request path globs
+	Headers     map[string]string `yaml:"headers,omitempty"`      // exact header values; "*" requires any value
+	Keys        []string          `yaml:"keys,omitempty"`         // caller API keys (Authorization bearer tokens)
+	MinMessages int               `yaml:"min_messages,omitempty"` // inclusive
+	MaxMessages int               `yaml:"max_messages,omitempty"` // inclusive
+	Expression  string            `yaml:"expression,omitempty"`   // condition over the intent vector
+	TimeWindow  *TimeWindow       `yaml:"time_window,omitempty"`
+}
+
+// RouteTarget says where a matching request goes. Exactly one kind should be set.
+type RouteTarget struct {
+	Provider string         `yaml:"provider,omitempty"`
+	Model    string         `yaml:"model,omitempty"` // with provider; empty uses the strategy model
+	Fallback []AliasTarget  `yaml:"fallback,omitempty"`
+	Splits   []TrafficSplit `yaml:"splits,omitempty"`
+	Builtin  string         `yaml:"builtin,omitempty"` // "generative", "expression", "split" or "strategy"
+}
+
+// RouteTransforms adjust the request once its route is chosen.
+type RouteTransforms struct {
+	MaxTokens    int      `yaml:"max_tokens,omitempty"`    // caps max_tokens, also for unbounded requests
+	Temperature  *float64 `yaml:"temperature,omitempty"`   // overrides temperature
+	SystemPrompt string   `yaml:"system_prompt,omitempty"` // prepended as a system message
+}
+
+// Model alias policies.
+const (
+	AliasFixed      = "fixed"      // always the first target
+	AliasFallback   = "fallback"   // targets in order, moving on when the upstream fails
+	AliasGenerative = "generative" // generative routing restricted to the targets' providers
+)
+
+// ModelAlias maps a virtual model name requested by clients (e.g. "fast") to a routing
+// policy. Aliases may also be sent by the remote strategy, which overrides local entries.
+type ModelAlias struct {
+	Type    string        `yaml:"type" json:"type"`
+	Targets []AliasTarget `yaml:"targets" json:"targets"`
+}
+
+// AliasTarget is a provider/model pair an alias can route to. An empty model uses the
+// provider's default model.
+type AliasTarget struct {
+	Provider string `yaml:"provider" json:"provider"`
+	Model    string `yaml:"model,omitempty" json:"model,omitempty"`
+}
+
+// RoutingHintsConfig lets callers pick a backend with provider-prefixed model names
+// ("anthropic/claude-sonnet-4") and the X-Gateway-Provider / X-Gateway-Strategy headers
+type RoutingHintsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// AllowedKeys lists the caller API keys (Authorization bearer tokens) allowed to
+	// override routing. Empty allows no caller unless AllowAll is set.
+	AllowedKeys []string `yaml:"allowed_keys,omitempty"`
+	// AllowAll lets every caller override routing, whatever AllowedKeys says.
+	AllowAll bool `yaml:"allow_all,omitempty"`
+	// ProviderAliases maps model name prefixes to provider names, e.g. local: local_vllm.
+	// Configured provider names are always accepted as prefixes.
+	ProviderAliases map[string]string `yaml:"provider_aliases,omitempty"`
+}
+
+// ShadowConfig mirrors a sample of live requests to a candidate provider/model for offline comparison
+type ShadowConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Provider      string        `yaml:"provider"`
+	Model         string        `yaml:"model,omitempty"`          // empty uses the provider's default model
+	SampleRate    float64       `yaml:"sample_rate"`              // fraction of requests mirrored, 0..1
+	Filter        string        `yaml:"filter,omitempty"`         // expr condition over Req and Provider, e.g. "len(Req.Messages) > 2"
+	MaxConcurrent int           `yaml:"max_concurrent,omitempty"` // default 4; samples beyond the cap are dropped
+	Timeout       time.Duration `yaml:"timeout,omitempty"`        // default 60s
+	OutputPath    string        `yaml:"output_path"`              // JSONL file the request pairs are appended to
+}
+
+// SessionAffinityConfig pins a conversation to the provider/model chosen for an earlier turn
+type SessionAffinityConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	TTL        time.Duration `yaml:"ttl,omitempty"`         // idle time before a pin expires, default 30m
+	MaxEntries int           `yaml:"max_entries,omitempty"` // default 10000
+	Header     string        `yaml:"header,omitempty"`      // default "X-Session-ID"
+	// Escalation lists intent vector conditions that override the pinned choice.
+	// "prev" holds the vector recorded when the choice was pinned, e.g. "complexity - prev.complexity > 0.5".
+	Escalation []string `yaml:"escalation,omitempty"`
+}
+
+// TokenizerConfig selects the BPE vocabularies used to count prompt tokens
+type TokenizerConfig struct {
+	VocabDir string                  `yaml:"vocab_dir,omitempty"` // holds <encoding>.tiktoken files; empty uses the character estimate
+	Families []TokenizerFamilyConfig `yaml:"families,omitempty"`  // checked before the built-in model families
+}
+
+// TokenizerFamilyConfig maps model names to a vocabulary
+type TokenizerFamilyConfig struct {
+	Match    string `yaml:"match"`    // model name glob, e.g. "qwen*"
+	Encoding string `yaml:"encoding"` // vocabulary file name without the .tiktoken suffix
+}
+
+// ContextGuardConfig reroutes requests whose prompt does not fit the chosen model's context window
+type ContextGuardConfig struct {
+	Enabled             bool    `yaml:"enabled"`
+	ReserveOutputTokens int     `yaml:"reserve_output_tokens,omitempty"` // assumed completion length when max_tokens is unset
+	SafetyMargin        float64 `yaml:"safety_margin,omitempty"`         // fraction of the window kept free for counting error, e.g. 0.05
+}
+
+// ModelCatalogEntry describes the pricing and capabilities of a provider/model pair.
+// Prices are in USD per million tokens. Model may be a glob (e.g. "gemini-*"), in which
+// case the entry is only used to price requests and never chosen as a routing target.
+type ModelCatalogEntry struct {
+	Provider         string  `yaml:"provider"`
+	Model            string  `yaml:"model"`
+	InputPrice       float64 `yaml:"input_price"`
+	OutputPrice      float64 `yaml:"output_price"`
+	CachedInputPrice float64 `yaml:"cached_input_price,omitempty"` // defaults to input_price when unset
+	ContextWindow    int     `yaml:"context_window,omitempty"`     // 0 means unknown / unlimited
+	Tools            bool    `yaml:"tools,omitempty"`
+	Vision           bool    `yaml:"vision,omitempty"`
 }
 
 // GenerativeRoutingConfig configures the smart routing based on generative models
@@ -25,12 +189,28 @@ type GenerativeRoutingConfig struct {
 	FallbackProvider string                   `yaml:"fallback_provider"`
 	Evaluators       []EvaluatorConfig        `yaml:"evaluators"`
 	Resolution       ResolutionStrategyConfig `yaml:"resolution_strategy"`
+
+	// SpeculativeStages starts every evaluator stage at once; by default a stage starts
+	// when the previous one has finished without deciding the route.
+	SpeculativeStages bool `yaml:"speculative_stages,omitempty"`
+
+	// Cache memoizes evaluator results per evaluator and consumed messages.
+	Cache EvaluationCacheConfig `yaml:"cache,omitempty"`
+}
+
+// EvaluationCacheConfig bounds the evaluator result cache
+type EvaluationCacheConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	MaxEntries int           `yaml:"max_entries,omitempty"` // default 10000
+	TTL        time.Duration `yaml:"ttl,omitempty"`         // default 10m
 }
 
 // EvaluatorConfig configures a single intent dimension evaluator
 type EvaluatorConfig struct {
 	Name           string         `yaml:"name"`
 	Type           string         `yaml:"type"`               // e.g. "llm_api", "builtin"
+	Stage          int            `yaml:"stage,omitempty"`    // evaluation stage, lowest first
+	NoCache        bool           `yaml:"no_cache,omitempty"` // bypass generative_routing.cache
 	Protocol       string         `yaml:"protocol,omitempty"` // "ollama" (default) or "openai"
 	Endpoint       string         `yaml:"endpoint,omitempty"`
 	Model          string         `yaml:"model,omitempty"`
@@ -39,6 +219,86 @@ type EvaluatorConfig struct {
 	LogitBias      map[string]int `yaml:"logit_bias,omitempty"`
 	PromptTemplate string         `yaml:"prompt_template,omitempty"`
 	Threshold      int            `yaml:"threshold,omitempty"`
+	Scope          string         `yaml:"scope,omitempty"`  // builtin_tokens: "last_message" (default), "rounds" or "total"
+	Output         string         `yaml:"output,omitempty"` // builtin_tokens: "binary" (default) or "normalized"
+
+	// embedding_knn: labeled JSONL examples ({"text": ..., "score": 0..1}), the file their
+	// embeddings are cached in (default examples_path + ".embeddings.json") and the number
+	// of nearest neighbours averaged (default 5).
+	ExamplesPath string `yaml:"examples_path,omitempty"`
+	CachePath    string `yaml:"cache_path,omitempty"`
+	K            int    `yaml:"k,omitempty"`
+
+	// classifier: model file written by `eval-cli train`.
+	ModelPath string `yaml:"model_path,omitempty"`
+
+	// Rule-based builtins: builtin_keywords scores its matching rules, builtin_code and
+	// builtin_attachments look for the listed signals (default all), builtin_language
+	// measures the share of letters in a Unicode script ("cjk" or e.g. "Han", "Cyrillic").
+	Rules   []KeywordRuleConfig `yaml:"rules,omitempty"`
+	Signals []string            `yaml:"signals,omitempty"`
+	Script  string              `yaml:"script,omitempty"`
+
+	// llm_structured_api: the fields of the JSON object the model must return, each
+	// becoming intent vector dimensions, and how the reply is constrained: "json_schema"
+	// (default), "json" or "none".
+	Schema         []SchemaFieldConfig `yaml:"schema,omitempty"`
+	ResponseFormat string              `yaml:"response_format,omitempty"`
+
+	// llm_logprob_api: the labels the model answers with (default "0" and "1") and the
+	// number of alternatives requested per token (default twice the labels, at most 20).
+	// With labels configured, the score is the expected label value and the probability
+	// of every label is exposed as the dimension "<name>_<label>".
+	Labels      []LogprobLabelConfig `yaml:"labels,omitempty"`
+	TopLogprobs int                  `yaml:"top_logprobs,omitempty"`
+
+	// Maps the raw scores to calibrated probabilities; written by `eval-cli calibrate`.
+	Calibration *CalibrationConfig `yaml:"calibration,omitempty"`
+}
+
+// CalibrationConfig maps the raw score s of an evaluator to a probability: "platt"
+// computes 1 / (1 + exp(-(a*s + b))) and "isotonic" interpolates linearly between points
+// sorted by score, clamping outside them.
+type CalibrationConfig struct {
+	Method string             `yaml:"method"`
+	A      float64            `yaml:"a,omitempty"`
+	B      float64            `yaml:"b,omitempty"`
+	Points []CalibrationPoint `yaml:"points,omitempty"`
+}
+
+// CalibrationPoint is a raw score and its calibrated probability.
+type CalibrationPoint struct {
+	Score       float64 `yaml:"score"`
+	Probability float64 `yaml:"probability"`
+}
+
+// LogprobLabelConfig is one answer of a logprob evaluator. Value defaults to the label
+// itself when numeric and to its position spread over [0, 1] otherwise. Aliases are other
+// spellings counted as the label, e.g. "med" for "medium".
+type LogprobLabelConfig struct {
+	Label   string   `yaml:"label"`
+	Value   *float64 `yaml:"value,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+// SchemaFieldConfig declares one field of a structured evaluator reply. Numbers must lie
+// within [min, max] (default [0, 1]), booleans become 0 or 1, and enums become one
+// dimension per value, "<name>_<value>", set to 1 for the returned value.
+type SchemaFieldConfig struct {
+	Name        string   `yaml:"name"`
+	Type        string   `yaml:"type"` // "number", "boolean" or "enum"
+	Description string   `yaml:"description,omitempty"`
+	Min         *float64 `yaml:"min,omitempty"`
+	Max         *float64 `yaml:"max,omitempty"`
+	Values      []string `yaml:"values,omitempty"` // enum
+}
+
+// KeywordRuleConfig scores a message containing any of its keywords (case-insensitive)
+// or matching any of its regex patterns.
+type KeywordRuleConfig struct {
+	Keywords []string `yaml:"keywords,omitempty"`
+	Patterns []string `yaml:"patterns,omitempty"`
+	Score    *float64 `yaml:"score,omitempty"` // default 1
 }
 
 // ResolutionStrategyConfig configures how to make routing decision based on eval vectors
@@ -46,6 +306,20 @@ type ResolutionStrategyConfig struct {
 	Type            string                 `yaml:"type"` // e.g. "dynamic_expression"
 	Rules           []ResolutionRuleConfig `yaml:"rules,omitempty"`
 	DefaultProvider string                 `yaml:"default_provider"`
+
+	// ExpectedOutputTokens is the completion length assumed by "cost_optimal" when the
+	// request carries no max_tokens.
+	ExpectedOutputTokens int `yaml:"expected_output_tokens,omitempty"`
+
+	// Tests are resolved at startup and by `eval-cli --validate`; a mismatch is fatal.
+	Tests []ResolutionTestConfig `yaml:"tests,omitempty"`
+}
+
+// ResolutionTestConfig is an intent vector with the provider it must resolve to.
+type ResolutionTestConfig struct {
+	Name   string             `yaml:"name,omitempty"`
+	Vector map[string]float64 `yaml:"vector"`
+	Expect string             `yaml:"expect"`
 }
 
 // ResolutionRuleConfig determines condition to hit specific target provider
@@ -121,3 +121,53 @@ providers:
 		t.Fatalf("expected GenerativeRouting to be nil if not present in config, got %+v", conf.GenerativeRouting)
 	}
 }
+
+func TestLoadLocalConfig_ModelCatalog(t *testing.T) {
+	content := `
+providers:
+  deepseek:
+    api_key: "sk-..."
+model_catalog:
+  - provider: "deepseek"
+    model: "deepseek-chat"
+    input_price: 0.27
+    output_price: 1.10
+    cached_input_price: 0.07
+    context_window: 65536
+    tools: true
+  - provider: "google"
+    model: "gemini-*"
+    input_price: 1.25
+    output_price: 10
+    vision: true
+generative_routing:
+  enabled: true
+  resolution_strategy:
+    type: "cost_optimal"
+    expected_output_tokens: 800
+`
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write mock config: %v", err)
+	}
+	t.Setenv("LOCALROUTER_CONFIG_PATH", configPath)
+
+	conf, err := LoadLocalConfig()
+	if err != nil {
+		t.Fatalf("LoadLocalConfig failed: %v", err)
+	}
+
+	if len(conf.ModelCatalog) != 2 {
+		t.Fatalf("expected 2 catalog entries, got %d", len(conf.ModelCatalog))
+	}
+	ds := conf.ModelCatalog[0]
+	if ds.Provider != "deepseek" || ds.CachedInputPrice != 0.07 || ds.ContextWindow != 65536 || !ds.Tools || ds.Vision {
+		t.Errorf("catalog entry parsed incorrectly: %+v", ds)
+	}
+	if !conf.ModelCatalog[1].Vision {
+		t.Errorf("expected vision capability on second entry: %+v", conf.ModelCatalog[1])
+	}
+	if conf.GenerativeRouting.Resolution.ExpectedOutputTokens != 800 {
+		t.Errorf("expected expected_output_tokens 800, got %d", conf.GenerativeRouting.Resolution.ExpectedOutputTokens)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
+	"sort"
 	"sync/atomic"
 	"time"
 )
@@ -19,13 +21,107 @@ type ModelSetter interface {
 
 // RemoteStrategy represents the data structure returned by the remote origin.
 type RemoteStrategy struct {
-	Strategy       string            `json:"strategy"`        // "local" or "remote"
-	LocalModel     string            `json:"local_model"`     // e.g., "qwen-35b-awq"
-	RemoteProvider string            `json:"remote_provider"` // e.g., "google", "openai"
-	RemoteModel    string            `json:"remote_model"`    // e.g., "gemini-3.0-flash-preview"
-	ProviderModels map[string]string `json:"provider_models"` // per-provider model overrides; empty values are ignored
-	FallbackOn404  *bool             `json:"fallback_on_404"` // if non-nil, overrides per-provider 404 fallback behaviour
-	UpdatedAt      string            `json:"updated_at"`
+	Strategy       string                `json:"strategy"`                // "local" or "remote"
+	LocalModel     string                `json:"local_model"`             // e.g., "qwen-35b-awq"
+	RemoteProvider string                `json:"remote_provider"`         // e.g., "google", "openai"
+	RemoteModel    string                `json:"remote_model"`            // e.g., "gemini-3.0-flash-preview"
+	ProviderModels map[string]string     `json:"provider_models"`         // per-provider model overrides; empty values are ignored
+	FallbackOn404  *bool                 `json:"fallback_on_404"`         // if non-nil, overrides per-provider 404 fallback behaviour
+	Splits         []TrafficSplit        `json:"splits,omitempty"`        // if non-empty, replaces Strategy with a weighted split
+	ModelAliases   map[string]ModelAlias `json:"model_aliases,omitempty"` // overrides local model_aliases by name
+	UpdatedAt      string                `json:"updated_at"`
+
+	// ModelStrategies overrides the fields above for requests whose model matches the
+	// key, either exactly or as a glob such as "gpt-4o-mini*".
+	ModelStrategies map[string]ModelStrategy `json:"model_strategies,omitempty"`
+
+	// Schedules switch the strategy during recurring time windows. They take precedence
+	// over the local schedules and are applied after ModelStrategies.
+	Schedules []Schedule `json:"schedules,omitempty"`
+}
+
+// ModelStrategy is the strategy block for one requested model. Empty fields keep the
+// top-level value of the RemoteStrategy.
+type ModelStrategy struct {
+	Strategy       string         `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	LocalModel     string         `json:"local_model,omitempty" yaml:"local_model,omitempty"`
+	RemoteProvider string         `json:"remote_provider,omitempty" yaml:"remote_provider,omitempty"`
+	RemoteModel    string         `json:"remote_model,omitempty" yaml:"remote_model,omitempty"`
+	Splits         []TrafficSplit `json:"splits,omitempty" yaml:"splits,omitempty"`
+}
+
+// ForModel returns the strategy that applies to requests for model, along with the
+// matching model_strategies key. An exact key wins over globs, and among globs the
+// longest pattern wins. Without a match rs itself is returned with an empty key.
+func (rs *RemoteStrategy) ForModel(model string) (*RemoteStrategy, string) {
+	if rs == nil || len(rs.ModelStrategies) == 0 {
+		return rs, ""
+	}
+
+	key := ""
+	block, ok := rs.ModelStrategies[model]
+	if ok {
+		key = model
+	} else {
+		patterns := make([]string, 0, len(rs.ModelStrategies))
+		for p := range rs.ModelStrategies {
+			patterns = append(patterns, p)
+		}
+		// Longest first, then lexical, so overlapping globs resolve deterministically.
+		sort.Slice(patterns, func(i, j int) bool {
+			if len(patterns[i]) != len(patterns[j]) {
+				return len(patterns[i]) > len(patterns[j])
+			}
+			return patterns[i] < patterns[j]
+		})
+		for _, p := range patterns {
+			if matched, err := path.Match(p, model); err == nil && matched {
+				key, block, ok = p, rs.ModelStrategies[p], true
+				break
+			}
+		}
+	}
+	if !ok {
+		return rs, ""
+	}
+	return rs.Overlay(block), key
+}
+
+// Overlay returns a copy of rs with the non-empty fields of block applied. A nil rs is
+// treated as an empty strategy.
+func (rs *RemoteStrategy) Overlay(block ModelStrategy) *RemoteStrategy {
+	var eff RemoteStrategy
+	if rs != nil {
+		eff = *rs
+	}
+	if block.Strategy != "" {
+		eff.Strategy = block.Strategy
+	}
+	if block.LocalModel != "" {
+		eff.LocalModel = block.LocalModel
+	}
+	if block.RemoteProvider != "" {
+		eff.RemoteProvider = block.RemoteProvider
+	}
+	if block.RemoteModel != "" {
+		eff.RemoteModel = block.RemoteModel
+	}
+	if len(block.Splits) > 0 {
+		eff.Splits = block.Splits
+	} else if block.Strategy != "" {
+		eff.Splits = nil // an explicit strategy is not replaced by the default splits
+	}
+	return &eff
+}
+
+// TrafficSplit is one weighted bucket of a traffic split. Conversations are hashed onto
+// the buckets in order, so ramping up a canary listed before a single baseline bucket
+// only ever moves conversations into the canary.
+type TrafficSplit struct {
+	Name     string  `json:"name,omitempty" yaml:"name,omitempty"` // label used in logs; defaults to provider/model
+	Provider string  `json:"provider" yaml:"provider"`
+	Model    string  `json:"model,omitempty" yaml:"model,omitempty"` // empty uses local_model or remote_model
+	Weight   float64 `json:"weight" yaml:"weight"`
 }
 
 // FallbackOn404Enabled reports whether the remote strategy enables 404 model fallback.
@@ -117,7 +213,16 @@ func (rm *RemoteManager) fetch() error {
 		"remote_provider", strategy.RemoteProvider,
 		"remote_model", strategy.RemoteModel,
 		"provider_models_count", len(strategy.ProviderModels),
+		"splits_count", len(strategy.Splits),
+		"model_aliases_count", len(strategy.ModelAliases),
+		"model_strategies_count", len(strategy.ModelStrategies),
+		"schedules_count", len(strategy.Schedules),
 	)
+	for i, s := range strategy.Schedules {
+		if _, err := s.Parse(time.UTC); err != nil {
+			logger.Warnf("[Config] Remote schedule %d (%s) is invalid and will be ignored: %v", i, s.Name, err)
+		}
+	}
 
 	// Push per-provider model overrides; skip empty values to preserve provider defaults.
 	rm.applyProviderModels(strategy.ProviderModels)
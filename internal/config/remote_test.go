@@ -176,3 +176,102 @@ func TestRemoteManager_Fetch_NonOKStatus(t *testing.T) {
 		t.Error("expected error for non-200 status, got nil")
 	}
 }
+
+func TestRemoteStrategy_Splits_Parsed(t *testing.T) {
+	raw := `{
+		"local_model": "qwen-35b-awq",
+		"splits": [
+			{"name": "canary", "provider": "google", "model": "gemini-next", "weight": 10},
+			{"provider": "local_vllm", "weight": 90}
+		]
+	}`
+	var rs RemoteStrategy
+	if err := json.Unmarshal([]byte(raw), &rs); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	if len(rs.Splits) != 2 {
+		t.Fatalf("expected 2 splits, got %d", len(rs.Splits))
+	}
+	if rs.Splits[0] != (TrafficSplit{Name: "canary", Provider: "google", Model: "gemini-next", Weight: 10}) {
+		t.Errorf("unexpected first split: %+v", rs.Splits[0])
+	}
+	if rs.Splits[1].Model != "" || rs.Splits[1].Weight != 90 {
+		t.Errorf("unexpected second split: %+v", rs.Splits[1])
+	}
+}
+
+func TestRemoteStrategy_ModelAliases_Parsed(t *testing.T) {
+	raw := `{"model_aliases": {"fast": {"type": "fallback", "targets": [{"provider": "local_vllm", "model": "qwen"}, {"provider": "google"}]}}}`
+	var rs RemoteStrategy
+	if err := json.Unmarshal([]byte(raw), &rs); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+	fast := rs.ModelAliases["fast"]
+	if fast.Type != AliasFallback || len(fast.Targets) != 2 || fast.Targets[0] != (AliasTarget{Provider: "local_vllm", Model: "qwen"}) {
+		t.Errorf("unexpected alias: %+v", fast)
+	}
+}
+
+// --- ForModel ---
+
+func TestRemoteStrategy_ForModel(t *testing.T) {
+	raw := `{
+		"strategy": "local",
+		"local_model": "qwen-35b-awq",
+		"remote_provider": "google",
+		"remote_model": "gemini-2.0-flash",
+		"model_strategies": {
+			"gpt-4o": {"strategy": "remote", "remote_provider": "openai", "remote_model": "gpt-4o"},
+			"gpt-4o*": {"local_model": "qwen-7b"},
+			"gpt-*": {"strategy": "remote"}
+		}
+	}`
+	var rs RemoteStrategy
+	if err := json.Unmarshal([]byte(raw), &rs); err != nil {
+		t.Fatalf("unexpected unmarshal error: %v", err)
+	}
+
+	eff, key := rs.ForModel("gpt-4o")
+	if key != "gpt-4o" || eff.Strategy != "remote" || eff.RemoteProvider != "openai" || eff.RemoteModel != "gpt-4o" || eff.LocalModel != "qwen-35b-awq" {
+		t.Errorf("unexpected exact match: %q %+v", key, eff)
+	}
+
+	// The longest matching glob wins; unset fields keep the defaults.
+	eff, key = rs.ForModel("gpt-4o-mini")
+	if key != "gpt-4o*" || eff.Strategy != "local" || eff.LocalModel != "qwen-7b" || eff.RemoteModel != "gemini-2.0-flash" {
+		t.Errorf("unexpected glob match: %q %+v", key, eff)
+	}
+
+	eff, key = rs.ForModel("claude")
+	if key != "" || eff != &rs {
+		t.Errorf("expected the top-level strategy without a match, got %q %+v", key, eff)
+	}
+	if rs.Strategy != "local" || rs.RemoteProvider != "google" {
+		t.Errorf("expected the shared strategy to be left untouched, got %+v", rs)
+	}
+}
+
+func TestRemoteStrategy_ForModel_Splits(t *testing.T) {
+	rs := &RemoteStrategy{
+		Splits: []TrafficSplit{{Provider: "google", Weight: 1}},
+		ModelStrategies: map[string]ModelStrategy{
+			"pinned": {Strategy: "local"},
+			"canary": {Splits: []TrafficSplit{{Provider: "openai", Weight: 1}}},
+			"plain":  {LocalModel: "qwen"},
+		},
+	}
+	if eff, _ := rs.ForModel("pinned"); eff.Splits != nil {
+		t.Errorf("expected an explicit strategy to drop the default splits, got %+v", eff.Splits)
+	}
+	if eff, _ := rs.ForModel("canary"); len(eff.Splits) != 1 || eff.Splits[0].Provider != "openai" {
+		t.Errorf("expected the model splits, got %+v", eff.Splits)
+	}
+	if eff, _ := rs.ForModel("plain"); len(eff.Splits) != 1 || eff.Splits[0].Provider != "google" {
+		t.Errorf("expected the default splits, got %+v", eff.Splits)
+	}
+
+	var nilStrategy *RemoteStrategy
+	if eff, key := nilStrategy.ForModel("x"); eff != nil || key != "" {
+		t.Errorf("expected nil for a nil strategy, got %+v %q", eff, key)
+	}
+}
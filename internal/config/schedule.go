@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TimeWindow is a recurring window of local time.
+type TimeWindow struct {
+	Days     []string `json:"days,omitempty" yaml:"days,omitempty"`         // "mon".."sun" or ranges such as "mon-fri"; empty means every day
+	Start    string   `json:"start,omitempty" yaml:"start,omitempty"`       // "HH:MM", inclusive; empty means midnight
+	End      string   `json:"end,omitempty" yaml:"end,omitempty"`           // "HH:MM", exclusive; empty means midnight, before Start wraps past midnight
+	Timezone string   `json:"timezone,omitempty" yaml:"timezone,omitempty"` // IANA name; empty uses the gateway timezone
+}
+
+// Schedule switches the strategy while its time window is active. Empty strategy fields
+// keep the value of the RemoteStrategy.
+type Schedule struct {
+	Name          string `json:"name,omitempty" yaml:"name,omitempty"`
+	TimeWindow    `yaml:",inline"`
+	ModelStrategy `yaml:",inline"`
+}
+
+// Window is a parsed TimeWindow.
+type Window struct {
+	days       [7]bool // indexed by time.Weekday; all false means every day
+	start, end int     // minutes since midnight
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// WeekdayName returns the three-letter lower-case name used by time windows.
+func WeekdayName(d time.Weekday) string {
+	return strings.ToLower(d.String()[:3])
+}
+
+// Parse validates w. Windows without a timezone use defaultLoc.
+func (w TimeWindow) Parse(defaultLoc *time.Location) (*Window, error) {
+	pw := &Window{loc: defaultLoc}
+	if w.Timezone != "" {
+		loc, err := LoadLocation(w.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		pw.loc = loc
+	}
+	for _, d := range w.Days {
+		from, to, isRange := strings.Cut(d, "-")
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q", d)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(to)]; !ok {
+				return nil, fmt.Errorf("unknown day %q", d)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			pw.days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	var err error
+	if pw.start, err = parseClock(w.Start); err != nil {
+		return nil, err
+	}
+	if pw.end, err = parseClock(w.End); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func parseClock(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls into the window. A window ending before it starts
+// wraps past midnight and belongs to the day it starts on.
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	switch {
+	case w.start == w.end: // whole day
+	case w.start < w.end:
+		if minute < w.start || minute >= w.end {
+			return false
+		}
+	default: // wraps past midnight
+		if minute < w.end {
+			day = (day + 6) % 7 // the window started yesterday
+		} else if minute < w.start {
+			return false
+		}
+	}
+	return w.days == [7]bool{} || w.days[day]
+}
+
+var locations sync.Map // IANA name -> *time.Location
+
+// LoadLocation is time.LoadLocation with a cache, since remote schedules are parsed
+// on every request.
+func LoadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTimeWindow_Contains(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+	office, _ := TimeWindow{Days: []string{"mon-fri"}, Start: "09:00", End: "18:00", Timezone: "Europe/Berlin"}.Parse(time.UTC)
+	nightly, _ := TimeWindow{Days: []string{"fri"}, Start: "22:00", End: "06:00"}.Parse(time.UTC)
+
+	cases := []struct {
+		w    *Window
+		at   string
+		want bool
+	}{
+		{office, "2026-10-14T08:00:00Z", true},   // Wed 10:00 in Berlin
+		{office, "2026-10-14T06:30:00Z", false},  // Wed 08:30 in Berlin
+		{office, "2026-10-14T16:00:00Z", false},  // Wed 18:00 in Berlin, end is exclusive
+		{office, "2026-10-17T10:00:00Z", false},  // Saturday
+		{nightly, "2026-10-16T23:00:00Z", true},  // Fri 23:00
+		{nightly, "2026-10-17T05:59:00Z", true},  // Sat 05:59, window opened Friday
+		{nightly, "2026-10-18T02:00:00Z", false}, // Sun 02:00, window opened Saturday
+		{nightly, "2026-10-16T12:00:00Z", false},
+	}
+	for _, c := range cases {
+		if got := c.w.Contains(at(c.at)); got != c.want {
+			t.Errorf("%s: expected %v, got %v", c.at, c.want, got)
+		}
+	}
+}
+
+func TestTimeWindow_ParseErrors(t *testing.T) {
+	for _, w := range []TimeWindow{
+		{Days: []string{"someday"}},
+		{Days: []string{"mon-xyz"}},
+		{Start: "9am"},
+		{End: "24:30"},
+		{Timezone: "Mars/Olympus"},
+	} {
+		if _, err := w.Parse(time.UTC); err == nil {
+			t.Errorf("expected an error for %+v", w)
+		}
+	}
+}
+
+func TestTimeWindow_DayRangeWraps(t *testing.T) {
+	w, err := TimeWindow{Days: []string{"fri-mon"}}.Parse(time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for day, want := range map[int]bool{16: true, 18: true, 19: true, 20: false, 15: false} {
+		if got := w.Contains(time.Date(2026, 10, day, 12, 0, 0, 0, time.UTC)); got != want {
+			t.Errorf("2026-10-%d: expected %v, got %v", day, want, got)
+		}
+	}
+}
+
+func TestSchedule_Parsed(t *testing.T) {
+	var rs RemoteStrategy
+	body := `{"strategy":"local","schedules":[{"name":"training","days":["mon-fri"],"start":"09:00","end":"17:00","timezone":"Asia/Shanghai","strategy":"remote","remote_model":"gemini"}]}`
+	if err := json.Unmarshal([]byte(body), &rs); err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.Schedules) != 1 {
+		t.Fatalf("expected one schedule, got %d", len(rs.Schedules))
+	}
+	s := rs.Schedules[0]
+	if s.Name != "training" || s.Start != "09:00" || s.Timezone != "Asia/Shanghai" || s.Strategy != "remote" || s.RemoteModel != "gemini" {
+		t.Errorf("unexpected schedule %+v", s)
+	}
+
+	var cfg Config
+	yml := "schedules:\n  - name: off-peak\n    start: \"22:00\"\n    end: \"06:00\"\n    strategy: remote\n    remote_provider: deepseek\n"
+	if err := yaml.Unmarshal([]byte(yml), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Schedules) != 1 || cfg.Schedules[0].Start != "22:00" || cfg.Schedules[0].RemoteProvider != "deepseek" {
+		t.Errorf("unexpected local schedules %+v", cfg.Schedules)
+	}
+}
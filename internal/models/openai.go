@@ -13,6 +13,8 @@ type ChatCompletionRequest struct {
 	Stream      bool      `json:"stream,omitempty"`
 	Temperature float64   `json:"temperature,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
+	User        string    `json:"user,omitempty"`
+	Logprobs    bool      `json:"logprobs,omitempty"`
 }
 
 // ChatCompletionResponse is the unified response structure for non-streaming
@@ -22,15 +24,44 @@ type ChatCompletionResponse struct {
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
 	Choices []struct {
-		Index        int     `json:"index"`
-		Message      Message `json:"message"`
-		FinishReason string  `json:"finish_reason"`
+		Index        int       `json:"index"`
+		Message      Message   `json:"message"`
+		FinishReason string    `json:"finish_reason"`
+		Logprobs     *Logprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage Usage `json:"usage"`
+}
+
+// Logprobs carries the log probabilities of the generated tokens, when requested
+type Logprobs struct {
+	Content []TokenLogprob `json:"content"`
+}
+
+// TokenLogprob is the log probability of a single generated token
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+}
+
+// Usage reports the token accounting of a completion as returned by the upstream
+type Usage struct {
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *PromptTokensDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens, e.g. how many were served from the upstream prompt cache
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// CachedTokens returns the number of prompt tokens served from the upstream cache, or 0 if unreported
+func (u Usage) CachedTokens() int {
+	if u.PromptTokensDetails == nil {
+		return 0
+	}
+	return u.PromptTokensDetails.CachedTokens
 }
 
 // ChatCompletionStreamResponse is the unified structure for streaming fragments
@@ -45,6 +76,22 @@ type ChatCompletionStreamResponse struct {
 			Role    string `json:"role,omitempty"`
 			Content string `json:"content,omitempty"`
 		} `json:"delta"`
-		FinishReason *string `json:"finish_reason"`
+		FinishReason *string   `json:"finish_reason"`
+		Logprobs     *Logprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
+	// Usage is reported by some upstreams on the final chunk
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// ModelList is the response body of GET /v1/models
+type ModelList struct {
+	Object string      `json:"object"`
+	Data   []ModelInfo `json:"data"`
+}
+
+// ModelInfo describes a model clients can request
+type ModelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	OwnedBy string `json:"owned_by"`
 }
@@ -93,10 +93,13 @@ type anthropicResponse struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
-	Usage struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+	Usage anthropicUsage `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens          int `json:"input_tokens"`
+	OutputTokens         int `json:"output_tokens"`
+	CacheReadInputTokens int `json:"cache_read_input_tokens,omitempty"`
 }
 
 type anthropicStreamEvent struct {
@@ -218,15 +221,28 @@ func (p *Provider) decodeResponse(resp *http.Response) (*models.ChatCompletionRe
 		content = aresp.Content[0].Text
 	}
 
+	// Anthropic reports cache reads separately from input_tokens; fold them back into the
+	// OpenAI-style prompt count and surface them as cached tokens.
+	promptTokens := aresp.Usage.InputTokens + aresp.Usage.CacheReadInputTokens
+	usage := models.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: aresp.Usage.OutputTokens,
+		TotalTokens:      promptTokens + aresp.Usage.OutputTokens,
+	}
+	if aresp.Usage.CacheReadInputTokens > 0 {
+		usage.PromptTokensDetails = &models.PromptTokensDetails{CachedTokens: aresp.Usage.CacheReadInputTokens}
+	}
+
 	return &models.ChatCompletionResponse{
 		ID:      aresp.ID,
 		Object:  "chat.completion",
 		Created: time.Now().Unix(),
 		Model:   aresp.Model,
 		Choices: []struct {
-			Index        int            `json:"index"`
-			Message      models.Message `json:"message"`
-			FinishReason string         `json:"finish_reason"`
+			Index        int              `json:"index"`
+			Message      models.Message   `json:"message"`
+			FinishReason string           `json:"finish_reason"`
+			Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 		}{{
 			Index: 0,
 			Message: models.Message{
@@ -235,15 +251,7 @@ func (p *Provider) decodeResponse(resp *http.Response) (*models.ChatCompletionRe
 			},
 			FinishReason: "stop",
 		}},
-		Usage: struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		}{
-			PromptTokens:     aresp.Usage.InputTokens,
-			CompletionTokens: aresp.Usage.OutputTokens,
-			TotalTokens:      aresp.Usage.InputTokens + aresp.Usage.OutputTokens,
-		},
+		Usage: usage,
 	}, nil
 }
 
@@ -329,7 +337,8 @@ func (p *Provider) pipeStream(ctx context.Context, resp *http.Response, model st
 					Role    string `json:"role,omitempty"`
 					Content string `json:"content,omitempty"`
 				} `json:"delta"`
-				FinishReason *string `json:"finish_reason"`
+				FinishReason *string          `json:"finish_reason"`
+				Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 			}{{Index: 0, Delta: delta}}
 
 			select {
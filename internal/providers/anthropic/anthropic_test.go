@@ -72,10 +72,7 @@ func newAnthropicOKServer(t *testing.T) *httptest.Server {
 				Type string `json:"type"`
 				Text string `json:"text"`
 			}{{Type: "text", Text: "hello"}},
-			Usage: struct {
-				InputTokens  int `json:"input_tokens"`
-				OutputTokens int `json:"output_tokens"`
-			}{InputTokens: 5, OutputTokens: 3},
+			Usage: anthropicUsage{InputTokens: 5, OutputTokens: 3},
 		})
 	}))
 }
@@ -116,6 +113,30 @@ func TestChatCompletion_ExplicitModelPassedThrough(t *testing.T) {
 	}
 }
 
+func TestChatCompletion_MapsCacheReadTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(anthropicResponse{
+			ID:    "resp-1",
+			Model: DefaultModel,
+			Usage: anthropicUsage{InputTokens: 5, OutputTokens: 3, CacheReadInputTokens: 100},
+		})
+	}))
+	defer srv.Close()
+
+	p := &Provider{baseURL: srv.URL, client: &http.Client{}}
+	resp, err := p.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Messages: []models.Message{{Role: "user", Content: "hi"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Usage.PromptTokens != 105 || resp.Usage.TotalTokens != 108 {
+		t.Errorf("expected cache reads folded into prompt tokens, got %+v", resp.Usage)
+	}
+	if resp.Usage.CachedTokens() != 100 {
+		t.Errorf("expected 100 cached tokens, got %d", resp.Usage.CachedTokens())
+	}
+}
+
 func TestChatCompletion_404FallbackEnabled(t *testing.T) {
 	callCount := 0
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -214,9 +214,10 @@ func (p *Provider) decodeResponse(resp *http.Response, model string) (*models.Ch
 		Created: time.Now().Unix(),
 		Model:   model,
 		Choices: []struct {
-			Index        int            `json:"index"`
-			Message      models.Message `json:"message"`
-			FinishReason string         `json:"finish_reason"`
+			Index        int              `json:"index"`
+			Message      models.Message   `json:"message"`
+			FinishReason string           `json:"finish_reason"`
+			Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 		}{{
 			Index:        0,
 			Message:      models.Message{Role: "assistant", Content: content},
@@ -340,7 +341,8 @@ func (p *Provider) pipeStream(ctx context.Context, resp *http.Response, model st
 					Role    string `json:"role,omitempty"`
 					Content string `json:"content,omitempty"`
 				} `json:"delta"`
-				FinishReason *string `json:"finish_reason"`
+				FinishReason *string          `json:"finish_reason"`
+				Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 			}{{
 				Index: 0,
 				Delta: struct {
@@ -18,3 +18,22 @@ type Provider interface {
 	// The implementation should close the channel when finished or return an error if initialization fails.
 	ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error
 }
+
+// Composite is implemented by providers that forward a request to one of several
+// underlying providers, like a fallback model alias or a cascade. They are created per
+// request.
+type Composite interface {
+	// ServedBy returns the name of the underlying provider that answered the request,
+	// empty until one did.
+	ServedBy() string
+}
+
+// ServedBy returns the name of the provider that actually answered a request sent to p.
+func ServedBy(p Provider) string {
+	if c, ok := p.(Composite); ok {
+		if name := c.ServedBy(); name != "" {
+			return name
+		}
+	}
+	return p.Name()
+}
@@ -0,0 +1,175 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/cache"
+	"agentic-llm-gateway/pkg/logger"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+const (
+	defaultAffinityTTL        = 30 * time.Minute
+	defaultAffinityMaxEntries = 10000
+	defaultAffinityHeader     = "X-Session-ID"
+)
+
+// affinityEntry is the routing choice pinned to a conversation.
+type affinityEntry struct {
+	provider string
+	model    string
+	vector   map[string]float64 // intent vector of the turn that pinned the choice
+}
+
+// sessionAffinity keeps later turns of a conversation on the provider/model chosen for
+// an earlier turn, so dialogues do not flip between models and keep prompt caches warm.
+// A nil *sessionAffinity is disabled.
+type sessionAffinity struct {
+	header      string
+	store       *cache.LRU[string, affinityEntry]
+	escalations []*vm.Program
+	rules       []string
+}
+
+// newSessionAffinity creates the session store. Pins expire by now, and escalation
+// rules may read the intent vector dimensions dims and prev.
+func newSessionAffinity(now func() time.Time, dims []string) (*sessionAffinity, error) {
+	if config.GlobalConfig == nil || !config.GlobalConfig.SessionAffinity.Enabled {
+		return nil, nil
+	}
+	cfg := config.GlobalConfig.SessionAffinity
+
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultAffinityTTL
+	}
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultAffinityMaxEntries
+	}
+	escalations, err := compileEscalations(cfg.Escalation, dims)
+	if err != nil {
+		return nil, err
+	}
+	a := &sessionAffinity{
+		header:      sessionHeader(),
+		store:       cache.New[string, affinityEntry](maxEntries, ttl),
+		escalations: escalations,
+		rules:       cfg.Escalation,
+	}
+	a.store.SetClock(now)
+	return a, nil
+}
+
+// compileEscalations compiles session escalation rules over the dimensions dims of the
+// current turn and prev, the vector of the pinned turn. Every invalid rule is reported,
+// since skipping one would silently keep conversations pinned.
+func compileEscalations(rules []string, dims []string) ([]*vm.Program, error) {
+	env := map[string]interface{}{"prev": map[string]float64{}}
+	for _, d := range dims {
+		env[d] = 0.0
+	}
+	var programs []*vm.Program
+	var errs []error
+	for i, rule := range rules {
+		program, err := expr.Compile(rule, expr.Env(env), expr.AsBool())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("escalation rule %d (%s): %w", i, rule, err))
+			continue
+		}
+		programs = append(programs, program)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return programs, nil
+}
+
+// key derives the affinity key of the conversation. The requested model is part of the
+// key so one conversation may use several virtual models independently.
+func (a *sessionAffinity) key(st *routeState) string {
+	if a == nil {
+		return ""
+	}
+	id := conversationID(st, a.header)
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(st.req.Model + "\x00" + id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// escalates reports the first escalation rule matching the current intent vector.
+// vector is only computed when escalation rules exist, so pinned turns skip evaluation otherwise.
+func (a *sessionAffinity) escalates(vector func() map[string]float64, prev map[string]float64) (string, bool) {
+	if len(a.escalations) == 0 {
+		return "", false
+	}
+	if prev == nil {
+		prev = map[string]float64{}
+	}
+	env := map[string]interface{}{"prev": prev}
+	for k, v := range vector() {
+		env[k] = v
+	}
+	for i, program := range a.escalations {
+		matched, err := expr.Run(program, env)
+		if err != nil {
+			logger.Warnf("[Router] Session escalation rule %q failed: %v", a.rules[i], err)
+			continue
+		}
+		if b, ok := matched.(bool); ok && b {
+			return a.rules[i], true
+		}
+	}
+	return "", false
+}
+
+// selectWithAffinity reuses the choice pinned to the conversation unless an escalation
+// rule overrides it, and pins fresh decisions for later turns. Every pinned turn renews
+// the pin, so only idle conversations expire.
+func (e *defaultEngine) selectWithAffinity(st *routeState) (providers.Provider, string, error) {
+	key := e.affinity.key(st)
+	if key == "" {
+		return e.selectProvider(st)
+	}
+
+	if pinned, ok := e.affinity.store.Get(key); ok {
+		if p, exists := e.providerMap[pinned.provider]; exists {
+			rule, escalated := e.affinity.escalates(func() map[string]float64 { return e.intentVector(st) }, pinned.vector)
+			if st.trace != nil {
+				st.trace.Affinity = &AffinityTrace{Key: key, Pinned: !escalated, Escalation: rule}
+			}
+			if !escalated {
+				logger.Debugf("[Router] Session %s pinned to %s/%s", key, pinned.provider, pinned.model)
+				st.trace.decided("affinity")
+				if st.trace == nil {
+					e.affinity.store.Set(key, pinned) // the TTL runs from the latest turn
+				}
+				return p, pinned.model, nil
+			}
+			logger.Infof("[Router] Session %s escalated by rule %q, re-routing away from %s/%s", key, rule, pinned.provider, pinned.model)
+		}
+	}
+
+	p, model, err := e.selectProvider(st)
+	if err != nil {
+		return nil, "", err
+	}
+	if st.trace != nil {
+		if st.trace.Affinity == nil {
+			st.trace.Affinity = &AffinityTrace{Key: key}
+		}
+		return p, model, nil // dry runs never pin
+	}
+	e.affinity.store.Set(key, affinityEntry{provider: p.Name(), model: model, vector: st.vector})
+	return p, model, nil
+}
@@ -0,0 +1,220 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func affinityTestEngine(t *testing.T, cfg *config.Config) StrategyEngine {
+	t.Helper()
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = nil })
+
+	return newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+	})
+}
+
+func conversation(turns ...string) *models.ChatCompletionRequest {
+	req := &models.ChatCompletionRequest{Model: "auto", Messages: []models.Message{{Role: "system", Content: "be brief"}}}
+	for i, turn := range turns {
+		if i > 0 {
+			req.Messages = append(req.Messages, models.Message{Role: "assistant", Content: "ok"})
+		}
+		req.Messages = append(req.Messages, models.Message{Role: "user", Content: turn})
+	}
+	return req
+}
+
+func TestSessionAffinity_ReusesPinnedChoice(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{SessionAffinity: config.SessionAffinityConfig{Enabled: true}})
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	p, model, err := engine.SelectProvider(context.Background(), conversation("hi"), local)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen" {
+		t.Fatalf("expected local_vllm/qwen, got %v/%s (err %v)", p, model, err)
+	}
+
+	// The strategy changed, but the second turn of the same conversation stays pinned.
+	p, model, err = engine.SelectProvider(context.Background(), conversation("hi", "and then?"), remote)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected pinned local_vllm/qwen, got %v/%s (err %v)", p, model, err)
+	}
+
+	// A different conversation is routed afresh.
+	p, _, err = engine.SelectProvider(context.Background(), conversation("something else"), remote)
+	if err != nil || p.Name() != "google" {
+		t.Errorf("expected google for a new conversation, got %v (err %v)", p, err)
+	}
+}
+
+func TestSessionAffinity_Disabled(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{})
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	engine.SelectProvider(context.Background(), conversation("hi"), local)
+	p, _, _ := engine.SelectProvider(context.Background(), conversation("hi", "and then?"), remote)
+	if p.Name() != "google" {
+		t.Errorf("expected no affinity when disabled, got %s", p.Name())
+	}
+}
+
+func TestSessionAffinity_Key(t *testing.T) {
+	a := &sessionAffinity{header: "X-Session-ID"}
+	withHeader := func(v string) *RequestMeta {
+		h := http.Header{}
+		if v != "" {
+			h.Set("X-Session-ID", v)
+		}
+		return &RequestMeta{Headers: h}
+	}
+	key := func(meta *RequestMeta, req *models.ChatCompletionRequest) string {
+		return a.key(&routeState{req: req, meta: meta})
+	}
+
+	// The header wins over the user field and the message hash.
+	r1, r2 := conversation("a"), conversation("b")
+	r1.User, r2.User = "alice", "bob"
+	if key(withHeader("s1"), r1) != key(withHeader("s1"), r2) {
+		t.Error("expected the session header to define the key")
+	}
+
+	// The user field wins over the message hash.
+	if key(withHeader(""), r1) == key(withHeader(""), r2) {
+		t.Error("expected different users to get different keys")
+	}
+
+	// Without either, the opening messages identify the conversation.
+	if key(withHeader(""), conversation("a")) != key(withHeader(""), conversation("a", "b", "c")) {
+		t.Error("expected later turns to share the key of the first turn")
+	}
+	if key(withHeader(""), conversation("a")) == key(withHeader(""), conversation("b")) {
+		t.Error("expected different openings to get different keys")
+	}
+
+	// Virtual models are pinned independently.
+	other := conversation("a")
+	other.Model = "other"
+	if key(withHeader(""), conversation("a")) == key(withHeader(""), other) {
+		t.Error("expected the requested model to be part of the key")
+	}
+
+	// Requests without a user message cannot be keyed.
+	if key(withHeader(""), &models.ChatCompletionRequest{}) != "" {
+		t.Error("expected an empty key for a request without user messages")
+	}
+}
+
+func TestSessionAffinity_HeaderFromContext(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{SessionAffinity: config.SessionAffinityConfig{Enabled: true, Header: "X-Conversation"}})
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	ctx := WithRequestMeta(context.Background(), &RequestMeta{Headers: http.Header{"X-Conversation": {"c-1"}}})
+	engine.SelectProvider(ctx, conversation("hi"), local)
+	p, _, _ := engine.SelectProvider(ctx, conversation("unrelated opening"), remote)
+	if p.Name() != "local_vllm" {
+		t.Errorf("expected the header to pin the conversation, got %s", p.Name())
+	}
+}
+
+func TestSessionAffinity_Escalation(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{
+		SessionAffinity: config.SessionAffinityConfig{
+			Enabled:    true,
+			Escalation: []string{"length_check > prev.length_check"},
+		},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators:      []config.EvaluatorConfig{{Name: "length_check", Type: "builtin", Threshold: 20}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:            "dynamic_expression",
+				DefaultProvider: "local_vllm",
+				Rules:           []config.ResolutionRuleConfig{{Condition: "length_check >= 1", TargetProvider: "google"}},
+			},
+		},
+	})
+
+	p, _, _ := engine.SelectProvider(context.Background(), conversation("hi"), nil)
+	if p.Name() != "local_vllm" {
+		t.Fatalf("expected local_vllm for a simple opening, got %s", p.Name())
+	}
+
+	// Complexity jumps: the escalation rule overrides the pinned choice.
+	p, _, _ = engine.SelectProvider(context.Background(), conversation("hi", strings.Repeat("hard ", 10)), nil)
+	if p.Name() != "google" {
+		t.Fatalf("expected escalation to google, got %s", p.Name())
+	}
+
+	// The escalated choice is pinned for the rest of the conversation.
+	p, _, _ = engine.SelectProvider(context.Background(), conversation("hi", strings.Repeat("hard ", 10), "ok"), nil)
+	if p.Name() != "google" {
+		t.Errorf("expected the escalated choice to stay pinned, got %s", p.Name())
+	}
+}
+
+func TestSessionAffinity_Expiry(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{SessionAffinity: config.SessionAffinityConfig{Enabled: true, TTL: time.Minute}}).(*defaultEngine)
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	engine.SelectProvider(context.Background(), conversation("hi"), local)
+	now = now.Add(time.Minute + time.Second)
+	p, _, _ := engine.SelectProvider(context.Background(), conversation("hi", "and then?"), remote)
+	if p.Name() != "google" {
+		t.Errorf("expected an expired pin to be ignored, got %s", p.Name())
+	}
+}
+
+func TestSessionAffinity_PinnedTurnsRenewTTL(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{SessionAffinity: config.SessionAffinityConfig{Enabled: true, TTL: time.Minute}}).(*defaultEngine)
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	engine.SelectProvider(context.Background(), conversation("hi"), local)
+	// Each turn comes within the TTL of the previous one, but the last comes after the
+	// TTL of the first.
+	for _, turns := range [][]string{{"hi", "and then?"}, {"hi", "and then?", "go on"}} {
+		now = now.Add(40 * time.Second)
+		p, _, _ := engine.SelectProvider(context.Background(), conversation(turns...), remote)
+		if p.Name() != "local_vllm" {
+			t.Fatalf("%d turns: expected the pin to be renewed, got %s", len(turns), p.Name())
+		}
+	}
+
+	// An idle conversation expires
+	now = now.Add(61 * time.Second)
+	p, _, _ := engine.SelectProvider(context.Background(), conversation("hi", "and then?", "go on", "more"), remote)
+	if p.Name() != "google" {
+		t.Errorf("expected the idle pin to expire, got %s", p.Name())
+	}
+}
+
+func TestSessionAffinity_InvalidEscalationRejected(t *testing.T) {
+	config.GlobalConfig = &config.Config{SessionAffinity: config.SessionAffinityConfig{
+		Enabled:    true,
+		Escalation: []string{"prev.complexity <", "complexity > prev.complexity"},
+	}}
+	defer func() { config.GlobalConfig = nil }()
+
+	// complexity is unknown without generative routing
+	_, err := NewEngine(map[string]providers.Provider{})
+	if err == nil || !strings.Contains(err.Error(), "escalation rule 0") || !strings.Contains(err.Error(), "escalation rule 1") {
+		t.Errorf("expected both escalation rules to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/strategy"
+)
+
+// ModelAliases returns the virtual model names known to the gateway: the local
+// model_aliases overridden by those of the remote strategy.
+func ModelAliases(remoteCfg *config.RemoteStrategy) map[string]config.ModelAlias {
+	aliases := make(map[string]config.ModelAlias)
+	if config.GlobalConfig != nil {
+		for name, a := range config.GlobalConfig.ModelAliases {
+			aliases[name] = a
+		}
+	}
+	if remoteCfg != nil {
+		for name, a := range remoteCfg.ModelAliases {
+			aliases[name] = a
+		}
+	}
+	return aliases
+}
+
+// ModelAliasNames returns the sorted names of ModelAliases.
+func ModelAliasNames(remoteCfg *config.RemoteStrategy) []string {
+	aliases := ModelAliases(remoteCfg)
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func lookupAlias(name string, remoteCfg *config.RemoteStrategy) (config.ModelAlias, bool) {
+	if remoteCfg != nil {
+		if a, ok := remoteCfg.ModelAliases[name]; ok {
+			return a, true
+		}
+	}
+	if config.GlobalConfig != nil {
+		a, ok := config.GlobalConfig.ModelAliases[name]
+		return a, ok
+	}
+	return config.ModelAlias{}, false
+}
+
+// selectAlias routes requests for fixed and fallback aliases. Generative aliases are
+// recorded in st and resolved by selectProvider, so they keep session affinity.
+// handled is false when the request does not name a fixed or fallback alias.
+func (e *defaultEngine) selectAlias(st *routeState) (p providers.Provider, model string, handled bool, err error) {
+	name := st.req.Model
+	alias, ok := lookupAlias(name, st.remoteCfg)
+	if !ok {
+		return nil, "", false, nil
+	}
+
+	var targets []chainTarget
+	for _, t := range alias.Targets {
+		if tp, exists := e.providerMap[t.Provider]; exists {
+			targets = append(targets, chainTarget{provider: tp, model: t.Model})
+		} else {
+			logger.Warnf("[Router] Model alias %s references unconfigured provider %s, skipping target", name, t.Provider)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, "", true, fmt.Errorf("model alias %s has no configured target", name)
+	}
+
+	switch alias.Type {
+	case config.AliasFixed, "":
+		st.trace.decided("alias")
+		return targets[0].provider, targets[0].model, true, nil
+	case config.AliasFallback:
+		st.trace.decided("alias")
+		if len(targets) == 1 {
+			return targets[0].provider, targets[0].model, true, nil
+		}
+		return &chainProvider{name: name, targets: targets}, targets[0].model, true, nil
+	case config.AliasGenerative:
+		st.alias = targets
+		return nil, "", false, nil
+	default:
+		return nil, "", true, fmt.Errorf("model alias %s has unknown type %q", name, alias.Type)
+	}
+}
+
+// selectAliasGenerative runs generative routing restricted to the providers of the alias
+// targets, falling back to the first target when the resolver picks another provider.
+func (e *defaultEngine) selectAliasGenerative(st *routeState) (providers.Provider, string) {
+	allowed := func(provider string) bool {
+		return slices.ContainsFunc(st.alias, func(t chainTarget) bool { return t.provider.Name() == provider })
+	}
+
+	if e.generativeEnabled() {
+		genCfg := config.GlobalConfig.GenerativeRouting
+		resolver, err := strategy.NewResolver(genCfg.Resolution, e.catalog.Restrict(allowed))
+		if err != nil {
+			logger.Errorf("[Router] Invalid resolution strategy: %v", err)
+		}
+		vector := e.stagedIntentVector(st, resolver)
+		provider, model := "", ""
+		if tr, ok := resolver.(strategy.TargetResolver); ok {
+			provider, model = tr.ResolveTarget(vector)
+		} else if resolver != nil {
+			provider = resolver.Resolve(vector)
+		}
+		for _, t := range st.alias {
+			if t.provider.Name() != provider {
+				continue
+			}
+			if model == "" {
+				model = t.model
+			}
+			st.trace.decided("alias")
+			return t.provider, model
+		}
+		if provider != "" {
+			logger.Debugf("[Router] Generative routing picked %s outside model alias %s, using its first target", provider, st.req.Model)
+		}
+	}
+
+	st.trace.decided("alias")
+	return st.alias[0].provider, st.alias[0].model
+}
+
+// chainTarget is one provider/model pair of a fallback alias.
+type chainTarget struct {
+	provider providers.Provider
+	model    string
+}
+
+// chainProvider serves a fallback alias by trying its targets in order until one
+// succeeds. Streams only fall back while the upstream stream is being opened.
+type chainProvider struct {
+	name    string
+	targets []chainTarget
+	served  string // provider that answered
+}
+
+func (c *chainProvider) Name() string { return "alias:" + c.name }
+
+func (c *chainProvider) ServedBy() string { return c.served }
+
+func (c *chainProvider) members() []chainTarget { return c.targets }
+
+func (c *chainProvider) restrict(targets []chainTarget) providers.Provider {
+	if len(targets) == 1 {
+		return targets[0].provider
+	}
+	return &chainProvider{name: c.name, targets: targets}
+}
+
+func (c *chainProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	var errs []error
+	for _, t := range c.targets {
+		attempt := *req
+		attempt.Model = t.model
+		resp, err := t.provider.ChatCompletion(ctx, &attempt)
+		if err == nil {
+			req.Model = attempt.Model // report the model that actually answered
+			c.served = providers.ServedBy(t.provider)
+			return resp, nil
+		}
+		logger.Warnf("[Router] Model alias %s target %s/%s failed, trying next: %v", c.name, t.provider.Name(), t.model, err)
+		errs = append(errs, fmt.Errorf("%s: %w", t.provider.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (c *chainProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	var errs []error
+	for _, t := range c.targets {
+		attempt := *req
+		attempt.Model = t.model
+		err := t.provider.ChatCompletionStream(ctx, &attempt, streamChan)
+		if err == nil {
+			req.Model = attempt.Model
+			c.served = providers.ServedBy(t.provider)
+			return nil
+		}
+		logger.Warnf("[Router] Model alias %s target %s/%s failed to stream, trying next: %v", c.name, t.provider.Name(), t.model, err)
+		errs = append(errs, fmt.Errorf("%s: %w", t.provider.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+// failingProvider fails every call and counts them.
+type failingProvider struct {
+	MockProvider
+	calls int
+}
+
+func (f *failingProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	f.calls++
+	return nil, errors.New("upstream down")
+}
+func (f *failingProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	f.calls++
+	return errors.New("upstream down")
+}
+
+// echoProvider answers with the model it was asked for.
+type echoProvider struct{ MockProvider }
+
+func (p *echoProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	return &models.ChatCompletionResponse{Model: req.Model}, nil
+}
+
+func aliasTestEngine(t *testing.T, cfg *config.Config, pMap map[string]providers.Provider) StrategyEngine {
+	t.Helper()
+	config.GlobalConfig = cfg
+	t.Cleanup(func() { config.GlobalConfig = nil })
+	return newTestEngine(t, pMap)
+}
+
+func TestAlias_Fixed(t *testing.T) {
+	engine := aliasTestEngine(t, &config.Config{
+		ModelAliases: map[string]config.ModelAlias{
+			"fast": {Type: config.AliasFixed, Targets: []config.AliasTarget{{Provider: "local_vllm", Model: "qwen-7b"}}},
+		},
+	}, map[string]providers.Provider{"local_vllm": &MockProvider{name: "local_vllm"}, "google": &MockProvider{name: "google"}})
+	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini"}
+
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "fast"}, rcfg)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen-7b" {
+		t.Errorf("expected local_vllm/qwen-7b, got %v/%s (err %v)", p, model, err)
+	}
+
+	// Non-alias models route as before.
+	p, _, _ = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}, rcfg)
+	if p.Name() != "google" {
+		t.Errorf("expected google for a plain model, got %s", p.Name())
+	}
+}
+
+func TestAlias_RemoteOverridesLocal(t *testing.T) {
+	engine := aliasTestEngine(t, &config.Config{
+		ModelAliases: map[string]config.ModelAlias{
+			"fast":  {Targets: []config.AliasTarget{{Provider: "local_vllm", Model: "qwen-7b"}}},
+			"smart": {Targets: []config.AliasTarget{{Provider: "google", Model: "gemini-pro"}}},
+		},
+	}, map[string]providers.Provider{"local_vllm": &MockProvider{name: "local_vllm"}, "google": &MockProvider{name: "google"}})
+	rcfg := &config.RemoteStrategy{
+		Strategy: "local",
+		ModelAliases: map[string]config.ModelAlias{
+			"fast":  {Targets: []config.AliasTarget{{Provider: "google", Model: "gemini-flash"}}},
+			"coder": {Targets: []config.AliasTarget{{Provider: "google", Model: "gemini-code"}}},
+		},
+	}
+
+	p, model, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "fast"}, rcfg)
+	if p.Name() != "google" || model != "gemini-flash" {
+		t.Errorf("expected the remote alias to win, got %s/%s", p.Name(), model)
+	}
+	if names := ModelAliasNames(rcfg); !reflect.DeepEqual(names, []string{"coder", "fast", "smart"}) {
+		t.Errorf("unexpected alias names: %v", names)
+	}
+}
+
+func TestAlias_FallbackChain(t *testing.T) {
+	down := &failingProvider{MockProvider: MockProvider{name: "deepseek"}}
+	engine := aliasTestEngine(t, &config.Config{
+		ModelAliases: map[string]config.ModelAlias{
+			"smart": {Type: config.AliasFallback, Targets: []config.AliasTarget{
+				{Provider: "deepseek", Model: "deepseek-chat"},
+				{Provider: "missing", Model: "skipped"},
+				{Provider: "google", Model: "gemini-pro"},
+			}},
+		},
+	}, map[string]providers.Provider{"deepseek": down, "google": &echoProvider{MockProvider{name: "google"}}})
+
+	req := &models.ChatCompletionRequest{Model: "smart"}
+	p, model, err := engine.SelectProvider(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "alias:smart" || model != "deepseek-chat" {
+		t.Errorf("expected the alias chain starting at deepseek-chat, got %s/%s", p.Name(), model)
+	}
+
+	req.Model = model
+	resp, err := p.ChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected the chain to fall back, got %v", err)
+	}
+	if resp.Model != "gemini-pro" || req.Model != "gemini-pro" || down.calls != 1 {
+		t.Errorf("expected gemini-pro to answer after one failure, got resp %s req %s calls %d", resp.Model, req.Model, down.calls)
+	}
+	if got := providers.ServedBy(p); got != "google" {
+		t.Errorf("expected google to be reported as the serving provider, got %s", got)
+	}
+
+	err = p.ChatCompletionStream(context.Background(), &models.ChatCompletionRequest{}, make(chan *models.ChatCompletionStreamResponse))
+	if err != nil || down.calls != 2 {
+		t.Errorf("expected the stream to fall back to google, got %v after %d calls", err, down.calls)
+	}
+}
+
+func TestAlias_FallbackChainAllFail(t *testing.T) {
+	chain := &chainProvider{name: "smart", targets: []chainTarget{
+		{provider: &failingProvider{MockProvider: MockProvider{name: "a"}}},
+		{provider: &failingProvider{MockProvider: MockProvider{name: "b"}}},
+	}}
+	_, err := chain.ChatCompletion(context.Background(), &models.ChatCompletionRequest{})
+	if err == nil || !strings.Contains(err.Error(), "a: upstream down") || !strings.Contains(err.Error(), "b: upstream down") {
+		t.Errorf("expected both failures to be reported, got %v", err)
+	}
+}
+
+func TestAlias_Generative(t *testing.T) {
+	engine := aliasTestEngine(t, &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators:      []config.EvaluatorConfig{{Name: "length_check", Type: "builtin", Threshold: 20}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:            "dynamic_expression",
+				DefaultProvider: "anthropic",
+				Rules: []config.ResolutionRuleConfig{
+					{Condition: "length_check >= 1", TargetProvider: "google"},
+				},
+			},
+		},
+		ModelAliases: map[string]config.ModelAlias{
+			"coder": {Type: config.AliasGenerative, Targets: []config.AliasTarget{
+				{Provider: "local_vllm", Model: "qwen-coder"},
+				{Provider: "google", Model: "gemini-pro"},
+			}},
+		},
+	}, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+		"anthropic":  &MockProvider{name: "anthropic"},
+	})
+
+	long := &models.ChatCompletionRequest{Model: "coder", Messages: []models.Message{{Role: "user", Content: strings.Repeat("hard ", 10)}}}
+	p, model, _ := engine.SelectProvider(context.Background(), long, nil)
+	if p.Name() != "google" || model != "gemini-pro" {
+		t.Errorf("expected google/gemini-pro, got %s/%s", p.Name(), model)
+	}
+
+	// The resolver defaults to anthropic, which the alias does not allow.
+	short := &models.ChatCompletionRequest{Model: "coder", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+	p, model, _ = engine.SelectProvider(context.Background(), short, nil)
+	if p.Name() != "local_vllm" || model != "qwen-coder" {
+		t.Errorf("expected the first alias target, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestAlias_Invalid(t *testing.T) {
+	engine := aliasTestEngine(t, &config.Config{
+		ModelAliases: map[string]config.ModelAlias{
+			"broken": {Type: "random", Targets: []config.AliasTarget{{Provider: "google"}}},
+			"empty":  {Targets: []config.AliasTarget{{Provider: "missing"}}},
+		},
+	}, map[string]providers.Provider{"google": &MockProvider{name: "google"}})
+
+	for _, name := range []string{"broken", "empty"} {
+		if _, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: name}, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
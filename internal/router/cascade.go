@@ -0,0 +1,418 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/tokenizer"
+)
+
+const defaultCascadeBufferTokens = 64
+
+// defaultVerifierPatterns catch local answers that refuse or hedge.
+var defaultVerifierPatterns = []string{
+	`(?i)\bI(?:'m| am) (?:not sure|unsure|uncertain)\b`,
+	`(?i)\bI (?:don't|do not) know\b`,
+	`(?i)\bI (?:can't|cannot|am unable to|'m unable to) (?:help|answer|assist)\b`,
+	`(?i)\bas an AI(?: language model)?\b`,
+}
+
+// cascade holds the verifiers shared by every cascaded request.
+type cascade struct {
+	bufferTokens int
+	verifiers    []verifier
+	counter      *tokenizer.Counter
+}
+
+// newEngineCascade builds the verifiers of the "cascade" strategy. Every invalid verifier
+// is reported, since skipping one would accept answers it was meant to escalate.
+func newEngineCascade(counter *tokenizer.Counter) (*cascade, error) {
+	c := &cascade{bufferTokens: defaultCascadeBufferTokens, counter: counter}
+	var cfgs []config.VerifierConfig
+	if config.GlobalConfig != nil {
+		if n := config.GlobalConfig.Cascade.BufferTokens; n > 0 {
+			c.bufferTokens = n
+		}
+		cfgs = config.GlobalConfig.Cascade.Verifiers
+	}
+	if len(cfgs) == 0 {
+		cfgs = []config.VerifierConfig{{Type: "regex", Patterns: defaultVerifierPatterns}}
+	}
+	var errs []error
+	for i, vCfg := range cfgs {
+		v, err := newVerifier(vCfg, counter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("verifiers[%d] (%s): %w", i, vCfg.Type, err))
+			continue
+		}
+		c.verifiers = append(c.verifiers, v)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// cascadeProvider returns the provider serving the "cascade" strategy of remoteCfg.
+func (e *defaultEngine) cascadeProvider(remoteCfg *config.RemoteStrategy) (providers.Provider, string, error) {
+	local, ok := e.providerMap["local_vllm"]
+	if !ok {
+		return nil, "", fmt.Errorf("local provider 'local_vllm' not configured")
+	}
+	remoteName := remoteCfg.RemoteProvider
+	if remoteName == "" {
+		remoteName = "google"
+	}
+	remote, ok := e.providerMap[remoteName]
+	if !ok {
+		return nil, "", fmt.Errorf("remote provider '%s' not configured", remoteName)
+	}
+	return &cascadeProvider{
+		cascade:     e.cascade,
+		local:       local,
+		localModel:  remoteCfg.LocalModel,
+		remote:      remote,
+		remoteModel: remoteCfg.RemoteModel,
+	}, remoteCfg.LocalModel, nil
+}
+
+// cascadeAnswer is the (possibly partial) local answer handed to the verifiers.
+type cascadeAnswer struct {
+	content  string
+	logprobs []float64
+	complete bool // false while streaming, before the local answer ended
+}
+
+// verifier judges a local answer of the cascade.
+type verifier interface {
+	// verify reports whether the answer is acceptable, and why not otherwise.
+	verify(ctx context.Context, req *models.ChatCompletionRequest, answer *cascadeAnswer) (bool, string)
+	// needsLogprobs reports whether the local answer must carry token logprobs.
+	needsLogprobs() bool
+}
+
+func newVerifier(vCfg config.VerifierConfig, counter *tokenizer.Counter) (verifier, error) {
+	switch vCfg.Type {
+	case "regex":
+		if len(vCfg.Patterns) == 0 {
+			return nil, fmt.Errorf("no patterns")
+		}
+		v := &regexVerifier{}
+		for _, p := range vCfg.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, err
+			}
+			v.patterns = append(v.patterns, re)
+		}
+		return v, nil
+	case "logprob":
+		if vCfg.MinConfidence <= 0 || vCfg.MinConfidence >= 1 {
+			return nil, fmt.Errorf("min_confidence must be between 0 and 1")
+		}
+		return &logprobVerifier{minConfidence: vCfg.MinConfidence}, nil
+	case "judge":
+		if vCfg.Judge == nil {
+			return nil, fmt.Errorf("no judge evaluator configured")
+		}
+		ev, err := evaluator.New(*vCfg.Judge, evaluator.Deps{Counter: counter})
+		if err != nil {
+			return nil, err
+		}
+		return &judgeVerifier{judge: ev, minScore: vCfg.MinScore}, nil
+	}
+	return nil, fmt.Errorf("unknown verifier type %q", vCfg.Type)
+}
+
+// checkVerifier validates vCfg like newVerifier without creating the judge evaluator.
+func checkVerifier(vCfg config.VerifierConfig) error {
+	if vCfg.Type != "judge" {
+		_, err := newVerifier(vCfg, nil)
+		return err
+	}
+	switch {
+	case vCfg.Judge == nil:
+		return fmt.Errorf("no judge evaluator configured")
+	case !evaluator.Registered(vCfg.Judge.Type):
+		return fmt.Errorf("judge: unknown evaluator type %q", vCfg.Judge.Type)
+	case len(vCfg.Judge.Schema) > 0:
+		return fmt.Errorf("judge: a judge must produce a single score, not a schema")
+	}
+	return nil
+}
+
+// regexVerifier rejects answers matching a refusal or uncertainty pattern.
+type regexVerifier struct {
+	patterns []*regexp.Regexp
+}
+
+func (v *regexVerifier) verify(_ context.Context, _ *models.ChatCompletionRequest, answer *cascadeAnswer) (bool, string) {
+	for _, re := range v.patterns {
+		if re.MatchString(answer.content) {
+			return false, fmt.Sprintf("matched %q", re.String())
+		}
+	}
+	return true, ""
+}
+
+func (v *regexVerifier) needsLogprobs() bool { return false }
+
+// logprobVerifier rejects answers whose geometric mean token probability is too low.
+// Answers without logprobs cannot be judged and are accepted.
+type logprobVerifier struct {
+	minConfidence float64
+}
+
+func (v *logprobVerifier) verify(_ context.Context, _ *models.ChatCompletionRequest, answer *cascadeAnswer) (bool, string) {
+	if len(answer.logprobs) == 0 {
+		logger.Debugf("[Router] Cascade logprob verifier skipped: local answer carries no logprobs")
+		return true, ""
+	}
+	var sum float64
+	for _, lp := range answer.logprobs {
+		sum += lp
+	}
+	confidence := math.Exp(sum / float64(len(answer.logprobs)))
+	if confidence < v.minConfidence {
+		return false, fmt.Sprintf("confidence %.3f below %.3f", confidence, v.minConfidence)
+	}
+	return true, ""
+}
+
+func (v *logprobVerifier) needsLogprobs() bool { return true }
+
+// judgeVerifier scores the conversation ending with the local answer with an evaluator,
+// typically an LLM judge. A failing judge accepts the answer, like a failing evaluator
+// degrades generative routing.
+type judgeVerifier struct {
+	judge    evaluator.Evaluator
+	minScore float64
+}
+
+func (v *judgeVerifier) verify(ctx context.Context, req *models.ChatCompletionRequest, answer *cascadeAnswer) (bool, string) {
+	msgs := append(append([]models.Message{}, req.Messages...), models.Message{Role: "assistant", Content: answer.content})
+	res, err := v.judge.Evaluate(ctx, msgs)
+	if err != nil {
+		logger.Warnf("[Router] Cascade judge %s failed, accepting the local answer: %v", v.judge.Name(), err)
+		return true, ""
+	}
+	if res.Score < v.minScore {
+		return false, fmt.Sprintf("judge %s scored %.3f below %.3f", v.judge.Name(), res.Score, v.minScore)
+	}
+	return true, ""
+}
+
+func (v *judgeVerifier) needsLogprobs() bool { return false }
+
+// cascadeProvider serves a request from the local provider and re-sends it to the remote
+// provider when a verifier rejects the local answer or the local provider fails. Only the
+// accepted answer reaches the client.
+type cascadeProvider struct {
+	*cascade
+	local       providers.Provider
+	localModel  string
+	remote      providers.Provider
+	remoteModel string
+	served      string // provider that answered
+}
+
+func (c *cascadeProvider) Name() string { return "cascade" }
+
+func (c *cascadeProvider) ServedBy() string { return c.served }
+
+func (c *cascadeProvider) members() []chainTarget {
+	return []chainTarget{{provider: c.local, model: c.localModel}, {provider: c.remote, model: c.remoteModel}}
+}
+
+// restrict skips the local attempt, or the escalation, when the other target is too small.
+func (c *cascadeProvider) restrict(targets []chainTarget) providers.Provider {
+	if len(targets) == 1 {
+		return targets[0].provider
+	}
+	return c
+}
+
+// accept runs every verifier against answer. An empty complete answer is always rejected.
+func (c *cascadeProvider) accept(ctx context.Context, req *models.ChatCompletionRequest, answer *cascadeAnswer) (bool, string) {
+	if answer.complete && strings.TrimSpace(answer.content) == "" {
+		return false, "empty answer"
+	}
+	for _, v := range c.verifiers {
+		if ok, reason := v.verify(ctx, req, answer); !ok {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// localAttempt prepares the request for the local provider.
+func (c *cascadeProvider) localAttempt(req *models.ChatCompletionRequest) models.ChatCompletionRequest {
+	attempt := *req
+	if c.localModel != "" {
+		attempt.Model = c.localModel
+	}
+	for _, v := range c.verifiers {
+		if v.needsLogprobs() {
+			attempt.Logprobs = true
+		}
+	}
+	return attempt
+}
+
+func (c *cascadeProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	attempt := c.localAttempt(req)
+	resp, err := c.local.ChatCompletion(ctx, &attempt)
+	if err == nil {
+		answer := &cascadeAnswer{complete: true}
+		if len(resp.Choices) > 0 {
+			answer.content = resp.Choices[0].Message.Content
+			if lp := resp.Choices[0].Logprobs; lp != nil {
+				for _, t := range lp.Content {
+					answer.logprobs = append(answer.logprobs, t.Logprob)
+				}
+			}
+		}
+		ok, reason := c.accept(ctx, req, answer)
+		if ok {
+			if !req.Logprobs {
+				for i := range resp.Choices {
+					resp.Choices[i].Logprobs = nil
+				}
+			}
+			req.Model = attempt.Model
+			c.served = providers.ServedBy(c.local)
+			return resp, nil
+		}
+		logger.Infof("[Router] Cascade rejected the local answer of %s/%s, escalating to %s: %s", c.local.Name(), attempt.Model, c.remote.Name(), reason)
+	} else {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		logger.Warnf("[Router] Cascade local provider %s failed, escalating to %s: %v", c.local.Name(), c.remote.Name(), err)
+	}
+
+	escalated := *req
+	escalated.Model = c.remoteModel
+	resp, rerr := c.remote.ChatCompletion(ctx, &escalated)
+	if rerr != nil {
+		return nil, errors.Join(err, fmt.Errorf("%s: %w", c.remote.Name(), rerr))
+	}
+	req.Model = escalated.Model
+	c.served = providers.ServedBy(c.remote)
+	return resp, nil
+}
+
+// ChatCompletionStream buffers the local stream up to the decision point, the configured
+// number of tokens or the end of the answer, and verifies the buffered prefix. An accepted
+// answer is replayed and streamed on; a rejected one is dropped and the remote provider
+// streams instead.
+func (c *cascadeProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	attempt := c.localAttempt(req)
+	localCtx, cancel := context.WithCancel(ctx)
+	localChan := make(chan *models.ChatCompletionStreamResponse)
+
+	if err := c.local.ChatCompletionStream(localCtx, &attempt, localChan); err != nil {
+		cancel()
+		if ctx.Err() != nil {
+			return err
+		}
+		logger.Warnf("[Router] Cascade local provider %s failed to stream, escalating to %s: %v", c.local.Name(), c.remote.Name(), err)
+		return c.escalateStream(ctx, req, streamChan)
+	}
+
+	var buffered []*models.ChatCompletionStreamResponse
+	var content strings.Builder
+	answer := &cascadeAnswer{}
+buffering:
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			return ctx.Err()
+		case chunk, ok := <-localChan:
+			if !ok {
+				answer.complete = true
+				break buffering
+			}
+			buffered = append(buffered, chunk)
+			if len(chunk.Choices) > 0 {
+				content.WriteString(chunk.Choices[0].Delta.Content)
+				if lp := chunk.Choices[0].Logprobs; lp != nil {
+					for _, t := range lp.Content {
+						answer.logprobs = append(answer.logprobs, t.Logprob)
+					}
+				}
+			}
+			if c.counter.CountText(attempt.Model, content.String()) >= c.bufferTokens {
+				break buffering
+			}
+		}
+	}
+	answer.content = content.String()
+
+	if ok, reason := c.accept(ctx, req, answer); !ok {
+		cancel()
+		go drain(localChan)
+		logger.Infof("[Router] Cascade rejected the local stream of %s/%s, escalating to %s: %s", c.local.Name(), attempt.Model, c.remote.Name(), reason)
+		return c.escalateStream(ctx, req, streamChan)
+	}
+
+	req.Model = attempt.Model
+	c.served = providers.ServedBy(c.local)
+	go func() {
+		defer cancel()
+		defer close(streamChan)
+		forward := func(chunk *models.ChatCompletionStreamResponse) bool {
+			if !req.Logprobs {
+				for i := range chunk.Choices {
+					chunk.Choices[i].Logprobs = nil
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case streamChan <- chunk:
+				return true
+			}
+		}
+		for _, chunk := range buffered {
+			if !forward(chunk) {
+				go drain(localChan)
+				return
+			}
+		}
+		for chunk := range localChan {
+			if !forward(chunk) {
+				go drain(localChan)
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *cascadeProvider) escalateStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	escalated := *req
+	escalated.Model = c.remoteModel
+	if err := c.remote.ChatCompletionStream(ctx, &escalated, streamChan); err != nil {
+		return fmt.Errorf("%s: %w", c.remote.Name(), err)
+	}
+	req.Model = escalated.Model
+	c.served = providers.ServedBy(c.remote)
+	return nil
+}
+
+// drain discards the rest of an abandoned stream so its producer can exit.
+func drain(ch <-chan *models.ChatCompletionStreamResponse) {
+	for range ch {
+	}
+}
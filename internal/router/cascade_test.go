@@ -0,0 +1,263 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/evaluator"
+)
+
+// answerProvider answers every request with fixed content, one word per stream chunk.
+type answerProvider struct {
+	MockProvider
+	content  string
+	logprob  float64
+	err      error
+	requests []models.ChatCompletionRequest
+}
+
+func (p *answerProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	p.requests = append(p.requests, *req)
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp := &models.ChatCompletionResponse{Model: req.Model}
+	resp.Choices = make([]struct {
+		Index        int              `json:"index"`
+		Message      models.Message   `json:"message"`
+		FinishReason string           `json:"finish_reason"`
+		Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
+	}, 1)
+	resp.Choices[0].Message = models.Message{Role: "assistant", Content: p.content}
+	if req.Logprobs {
+		resp.Choices[0].Logprobs = &models.Logprobs{Content: []models.TokenLogprob{{Token: p.content, Logprob: p.logprob}}}
+	}
+	return resp, nil
+}
+
+func (p *answerProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	p.requests = append(p.requests, *req)
+	if p.err != nil {
+		return p.err
+	}
+	go func() {
+		defer close(streamChan)
+		for _, word := range strings.SplitAfter(p.content, " ") {
+			chunk := &models.ChatCompletionStreamResponse{Model: req.Model}
+			chunk.Choices = make([]struct {
+				Index int `json:"index"`
+				Delta struct {
+					Role    string `json:"role,omitempty"`
+					Content string `json:"content,omitempty"`
+				} `json:"delta"`
+				FinishReason *string          `json:"finish_reason"`
+				Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
+			}, 1)
+			chunk.Choices[0].Delta.Content = word
+			select {
+			case <-ctx.Done():
+				return
+			case streamChan <- chunk:
+			}
+		}
+	}()
+	return nil
+}
+
+func cascadeTestProvider(t *testing.T, cfg config.CascadeConfig, local, remote *answerProvider) providers.Provider {
+	t.Helper()
+	engine := aliasTestEngine(t, &config.Config{Cascade: cfg}, map[string]providers.Provider{"local_vllm": local, "google": remote})
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"},
+		&config.RemoteStrategy{Strategy: "cascade", LocalModel: "qwen", RemoteProvider: "google", RemoteModel: "gemini"})
+	if err != nil || p.Name() != "cascade" || model != "qwen" {
+		t.Fatalf("expected the cascade starting at qwen, got %v/%s (err %v)", p, model, err)
+	}
+	return p
+}
+
+func collect(t *testing.T, ch <-chan *models.ChatCompletionStreamResponse) string {
+	t.Helper()
+	var sb strings.Builder
+	for chunk := range ch {
+		sb.WriteString(chunk.Choices[0].Delta.Content)
+	}
+	return sb.String()
+}
+
+func TestCascade_AcceptsLocalAnswer(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: "Paris is the capital of France."}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{}, local, remote)
+
+	req := &models.ChatCompletionRequest{Model: "qwen"}
+	resp, err := p.ChatCompletion(context.Background(), req)
+	if err != nil || resp.Choices[0].Message.Content != local.content {
+		t.Fatalf("expected the local answer, got %+v (err %v)", resp, err)
+	}
+	if req.Model != "qwen" || len(remote.requests) != 0 {
+		t.Errorf("expected no escalation, got model %s and %d remote calls", req.Model, len(remote.requests))
+	}
+	if got := providers.ServedBy(p); got != "local_vllm" {
+		t.Errorf("expected local_vllm to be reported as the serving provider, got %s", got)
+	}
+}
+
+func TestCascade_EscalatesRejectedAnswer(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: "I'm not sure, maybe Lyon?"}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{}, local, remote)
+
+	req := &models.ChatCompletionRequest{Model: "qwen"}
+	resp, err := p.ChatCompletion(context.Background(), req)
+	if err != nil || resp.Choices[0].Message.Content != "Paris." {
+		t.Fatalf("expected the remote answer, got %+v (err %v)", resp, err)
+	}
+	if req.Model != "gemini" || providers.ServedBy(p) != "google" {
+		t.Errorf("expected the remote model and provider to be reported, got %s/%s", providers.ServedBy(p), req.Model)
+	}
+}
+
+func TestCascade_EscalatesLocalFailure(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, err: errors.New("gpu busy")}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{}, local, remote)
+
+	if _, err := p.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "qwen"}); err != nil || len(remote.requests) != 1 {
+		t.Errorf("expected one escalation, got %d (err %v)", len(remote.requests), err)
+	}
+
+	remote.err = errors.New("quota")
+	if _, err := p.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "qwen"}); err == nil || !strings.Contains(err.Error(), "gpu busy") || !strings.Contains(err.Error(), "quota") {
+		t.Errorf("expected both errors, got %v", err)
+	}
+}
+
+func TestCascade_LogprobVerifier(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: "Lyon.", logprob: -2}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{Verifiers: []config.VerifierConfig{{Type: "logprob", MinConfidence: 0.5}}}, local, remote)
+
+	resp, _ := p.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "qwen"})
+	if !local.requests[0].Logprobs || remote.requests[0].Logprobs {
+		t.Errorf("expected logprobs to be requested from the local provider only")
+	}
+	if resp.Choices[0].Message.Content != "Paris." {
+		t.Errorf("expected escalation at confidence %.2f, got %q", 0.135, resp.Choices[0].Message.Content)
+	}
+
+	local.logprob = -0.1
+	resp, _ = p.ChatCompletion(context.Background(), &models.ChatCompletionRequest{Model: "qwen"})
+	if resp.Choices[0].Message.Content != "Lyon." || resp.Choices[0].Logprobs != nil {
+		t.Errorf("expected the confident local answer without logprobs, got %+v", resp.Choices[0])
+	}
+}
+
+// fixedScoreEvaluator always returns the same score.
+type fixedScoreEvaluator struct{ score float64 }
+
+func (f fixedScoreEvaluator) Name() string       { return "judge" }
+func (f fixedScoreEvaluator) HistoryRounds() int { return 0 }
+func (f fixedScoreEvaluator) Evaluate(ctx context.Context, msgs []models.Message) (*evaluator.EvaluationResult, error) {
+	if msgs[len(msgs)-1].Role != "assistant" {
+		return nil, errors.New("expected the answer last")
+	}
+	return &evaluator.EvaluationResult{Score: f.score}, nil
+}
+
+func TestCascade_JudgeVerifier(t *testing.T) {
+	v := &judgeVerifier{judge: fixedScoreEvaluator{score: 0.3}, minScore: 0.5}
+	req := &models.ChatCompletionRequest{Messages: []models.Message{{Role: "user", Content: "capital of France?"}}}
+	if ok, _ := v.verify(context.Background(), req, &cascadeAnswer{content: "Lyon."}); ok {
+		t.Error("expected a low judge score to reject")
+	}
+	v.judge = fixedScoreEvaluator{score: 0.8}
+	if ok, _ := v.verify(context.Background(), req, &cascadeAnswer{content: "Paris."}); !ok {
+		t.Error("expected a high judge score to accept")
+	}
+}
+
+func TestCascade_InvalidVerifiers(t *testing.T) {
+	for _, vCfg := range []config.VerifierConfig{
+		{Type: "regex"},
+		{Type: "regex", Patterns: []string{"("}},
+		{Type: "logprob", MinConfidence: 2},
+		{Type: "judge"},
+		{Type: "vibes"},
+	} {
+		if _, err := newVerifier(vCfg, nil); err == nil {
+			t.Errorf("expected an error for %+v", vCfg)
+		}
+		if err := checkVerifier(vCfg); err == nil {
+			t.Errorf("expected checkVerifier to reject %+v", vCfg)
+		}
+	}
+
+	// a bad pattern stops startup instead of disabling the verifier
+	cfg := &config.Config{Cascade: config.CascadeConfig{Verifiers: []config.VerifierConfig{
+		{Type: "regex", Patterns: []string{`(?i)\bI don't know\b`}},
+		{Type: "regex", Patterns: []string{"(unclosed"}},
+	}}}
+	if err := ValidateConfig(cfg, nil); err == nil || !strings.Contains(err.Error(), "cascade.verifiers[1]") {
+		t.Errorf("expected the pattern to be reported, got %v", err)
+	}
+	config.GlobalConfig = cfg
+	defer func() { config.GlobalConfig = nil }()
+	if _, err := NewEngine(map[string]providers.Provider{}); err == nil {
+		t.Error("expected NewEngine to reject the verifier")
+	}
+}
+
+func TestCascade_StreamAccepted(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: "Paris is the capital of France and its largest city."}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{BufferTokens: 3}, local, remote)
+
+	ch := make(chan *models.ChatCompletionStreamResponse)
+	req := &models.ChatCompletionRequest{Model: "qwen", Stream: true}
+	if err := p.ChatCompletionStream(context.Background(), req, ch); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(t, ch); got != local.content {
+		t.Errorf("expected the full local answer, got %q", got)
+	}
+	if len(remote.requests) != 0 || req.Model != "qwen" {
+		t.Errorf("expected no escalation")
+	}
+}
+
+func TestCascade_StreamRejectedAtDecisionPoint(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: "I don't know the answer to that, sorry about it."}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris is the capital."}
+	p := cascadeTestProvider(t, config.CascadeConfig{BufferTokens: 8}, local, remote)
+
+	ch := make(chan *models.ChatCompletionStreamResponse)
+	req := &models.ChatCompletionRequest{Model: "qwen", Stream: true}
+	if err := p.ChatCompletionStream(context.Background(), req, ch); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(t, ch); got != remote.content {
+		t.Errorf("expected only the remote answer, got %q", got)
+	}
+	if req.Model != "gemini" || providers.ServedBy(p) != "google" {
+		t.Errorf("expected the remote model and provider to be reported, got %s/%s", providers.ServedBy(p), req.Model)
+	}
+}
+
+func TestCascade_StreamEscalatesEmptyAnswer(t *testing.T) {
+	local := &answerProvider{MockProvider: MockProvider{name: "local_vllm"}, content: ""}
+	remote := &answerProvider{MockProvider: MockProvider{name: "google"}, content: "Paris."}
+	p := cascadeTestProvider(t, config.CascadeConfig{}, local, remote)
+
+	ch := make(chan *models.ChatCompletionStreamResponse)
+	if err := p.ChatCompletionStream(context.Background(), &models.ChatCompletionRequest{Model: "qwen"}, ch); err != nil {
+		t.Fatal(err)
+	}
+	if got := collect(t, ch); got != "Paris." {
+		t.Errorf("expected the remote answer, got %q", got)
+	}
+}
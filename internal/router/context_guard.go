@@ -0,0 +1,123 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/strategy"
+)
+
+// ErrContextOverflow is returned when a prompt does not fit the context window of any
+// configured model. The server maps it to a client error instead of a gateway failure.
+var ErrContextOverflow = errors.New("prompt exceeds the context window of every configured model")
+
+// guardContextWindow verifies that the prompt fits the chosen model's context window, as
+// recorded in the model catalog, and reroutes to a model that can take it otherwise.
+// Targets missing from the catalog, or without a known window, are passed through. The
+// targets of a cascade or fallback alias are checked one by one: those that are too small
+// are dropped, and the request is only rerouted when none fits. A replacement must also
+// support the tools and vision the request needs, as cost_optimal requires them.
+func (e *defaultEngine) guardContextWindow(st *routeState, p providers.Provider, model string) (providers.Provider, string, error) {
+	if config.GlobalConfig == nil || !config.GlobalConfig.ContextGuard.Enabled {
+		return p, model, nil
+	}
+	guard := config.GlobalConfig.ContextGuard
+	req := st.req
+
+	demand := func(model string) catalog.Requirements {
+		need := catalog.Requirements{
+			PromptTokens: e.counter.CountMessages(model, req.Messages),
+			OutputTokens: guard.ReserveOutputTokens,
+		}
+		if req.MaxTokens > 0 {
+			need.OutputTokens = req.MaxTokens
+		}
+		// Inflate the demand rather than shrinking every window, so one margin applies to all candidates.
+		if guard.SafetyMargin > 0 && guard.SafetyMargin < 1 {
+			need.PromptTokens = int(float64(need.PromptTokens) / (1 - guard.SafetyMargin))
+		}
+		return need
+	}
+	// fits also returns the context window of a target that is too small
+	fits := func(provider, model string) (bool, int) {
+		entry, ok := e.catalog.Lookup(provider, model)
+		if !ok || entry.ContextWindow <= 0 {
+			return true, 0
+		}
+		return catalog.Satisfies(entry, demand(model)), entry.ContextWindow
+	}
+
+	window := 0
+	if c, ok := p.(compositeProvider); ok {
+		members := c.members()
+		var fitting []chainTarget
+		for _, m := range members {
+			ok, w := fits(m.provider.Name(), m.model)
+			if ok {
+				fitting = append(fitting, m)
+			} else if window == 0 || w < window {
+				window = w
+			}
+		}
+		switch {
+		case len(fitting) == len(members):
+			return p, model, nil
+		case len(fitting) > 0:
+			logger.Warn("[Router] Prompt exceeds the context window of some targets, skipping them",
+				"provider", p.Name(),
+				"context_window", window,
+				"targets", len(members),
+				"remaining_targets", len(fitting),
+			)
+			return c.restrict(fitting), fitting[0].model, nil
+		}
+	} else {
+		ok, w := fits(p.Name(), model)
+		if ok {
+			return p, model, nil
+		}
+		window = w
+	}
+	need := demand(model)
+	vector := e.intentVector(st)
+	need.Tools = vector[strategy.DimNeedsTools] >= 0.5
+	need.Vision = vector[strategy.DimNeedsVision] >= 0.5 || evaluator.HasImages(req.Messages)
+
+	// Prefer another model of the same provider, then the cheapest model anywhere.
+	sameProvider := func(name string) bool { return name == p.Name() }
+	replacement, found := e.catalog.Cheapest(need, sameProvider)
+	if !found {
+		replacement, found = e.catalog.Cheapest(need, nil)
+	}
+	if !found {
+		return nil, "", fmt.Errorf("%w: %d prompt + %d output tokens", ErrContextOverflow, need.PromptTokens, need.OutputTokens)
+	}
+
+	target, ok := e.providerMap[replacement.Provider]
+	if !ok {
+		return nil, "", fmt.Errorf("context overflow replacement provider '%s' not configured", replacement.Provider)
+	}
+	logger.Warn("[Router] Prompt exceeds context window, rerouting",
+		"provider", p.Name(),
+		"model", model,
+		"context_window", window,
+		"prompt_tokens", need.PromptTokens,
+		"output_tokens", need.OutputTokens,
+		"new_provider", replacement.Provider,
+		"new_model", replacement.Model,
+	)
+	return target, replacement.Model, nil
+}
+
+// compositeProvider is a provider serving a request from one of several targets, like
+// a cascade or a fallback alias. The context guard checks the targets instead.
+type compositeProvider interface {
+	members() []chainTarget
+	// restrict returns the provider serving only targets, a non-empty subset of members.
+	restrict(targets []chainTarget) providers.Provider
+}
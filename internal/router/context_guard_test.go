@@ -0,0 +1,229 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func guardTestEngine(t *testing.T, guard config.ContextGuardConfig) StrategyEngine {
+	t.Helper()
+	config.GlobalConfig = &config.Config{
+		ContextGuard: guard,
+		ModelCatalog: []config.ModelCatalogEntry{
+			{Provider: "local_vllm", Model: "qwen-small", ContextWindow: 100},
+			{Provider: "local_vllm", Model: "qwen-large", InputPrice: 0.01, ContextWindow: 1000},
+			{Provider: "google", Model: "gemini-long", InputPrice: 1, ContextWindow: 5000},
+		},
+	}
+	t.Cleanup(func() { config.GlobalConfig = nil })
+
+	return newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+	})
+}
+
+func promptOfTokens(n int) *models.ChatCompletionRequest {
+	// The character estimate counts four ASCII characters per token.
+	return &models.ChatCompletionRequest{Messages: []models.Message{{Role: "user", Content: strings.Repeat("abcd", n)}}}
+}
+
+func TestContextGuard_FitsUnchanged(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true, ReserveOutputTokens: 10})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	p, model, err := engine.SelectProvider(context.Background(), promptOfTokens(20), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "local_vllm" || model != "qwen-small" {
+		t.Errorf("expected local_vllm/qwen-small, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestContextGuard_PrefersSameProvider(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true, ReserveOutputTokens: 10})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	p, model, err := engine.SelectProvider(context.Background(), promptOfTokens(200), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "local_vllm" || model != "qwen-large" {
+		t.Errorf("expected local_vllm/qwen-large, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestContextGuard_ReroutesToOtherProvider(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	req := promptOfTokens(2000)
+	req.MaxTokens = 500
+	p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "google" || model != "gemini-long" {
+		t.Errorf("expected google/gemini-long, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestContextGuard_SafetyMargin(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true, SafetyMargin: 0.5})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	// ~70 tokens fit a 100 token window, but not once half of it is kept free.
+	_, model, err := engine.SelectProvider(context.Background(), promptOfTokens(60), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model != "qwen-large" {
+		t.Errorf("expected qwen-large with safety margin, got %s", model)
+	}
+}
+
+func TestContextGuard_NothingFits(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	_, _, err := engine.SelectProvider(context.Background(), promptOfTokens(10000), rcfg)
+	if !errors.Is(err, ErrContextOverflow) {
+		t.Errorf("expected ErrContextOverflow, got %v", err)
+	}
+}
+
+func TestContextGuard_Disabled(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	_, model, err := engine.SelectProvider(context.Background(), promptOfTokens(10000), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model != "qwen-small" {
+		t.Errorf("expected guard to be inactive, got %s", model)
+	}
+}
+
+func TestContextGuard_UnknownModelPassesThrough(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "not-in-catalog"}
+
+	_, model, err := engine.SelectProvider(context.Background(), promptOfTokens(10000), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model != "not-in-catalog" {
+		t.Errorf("expected uncatalogued model to pass through, got %s", model)
+	}
+}
+
+func TestContextGuard_FallbackAlias(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true})
+	config.GlobalConfig.ModelAliases = map[string]config.ModelAlias{
+		"smart": {Type: config.AliasFallback, Targets: []config.AliasTarget{
+			{Provider: "local_vllm", Model: "qwen-small"},
+			{Provider: "google", Model: "gemini-long"},
+		}},
+		"local": {Type: config.AliasFallback, Targets: []config.AliasTarget{
+			{Provider: "local_vllm", Model: "qwen-small"},
+			{Provider: "local_vllm", Model: "qwen-large"},
+		}},
+	}
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	// The target too small for the prompt is dropped from the chain.
+	req := promptOfTokens(200)
+	req.Model = "smart"
+	p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "google" || model != "gemini-long" {
+		t.Errorf("expected google/gemini-long, got %s/%s", p.Name(), model)
+	}
+
+	// A chain of which no target fits is rerouted like a single model.
+	req = promptOfTokens(2000)
+	req.Model = "local"
+	p, model, err = engine.SelectProvider(context.Background(), req, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "google" || model != "gemini-long" {
+		t.Errorf("expected google/gemini-long, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestContextGuard_Cascade(t *testing.T) {
+	engine := guardTestEngine(t, config.ContextGuardConfig{Enabled: true})
+	rcfg := &config.RemoteStrategy{Strategy: "cascade", LocalModel: "qwen-small", RemoteProvider: "google", RemoteModel: "gemini-long"}
+
+	p, _, err := engine.SelectProvider(context.Background(), promptOfTokens(20), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "cascade" {
+		t.Errorf("expected the cascade for a short prompt, got %s", p.Name())
+	}
+
+	// The local attempt is skipped when the prompt overflows its window.
+	p, model, err := engine.SelectProvider(context.Background(), promptOfTokens(200), rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "google" || model != "gemini-long" {
+		t.Errorf("expected google/gemini-long, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestContextGuard_ReplacementNeedsCapabilities(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		ContextGuard: config.ContextGuardConfig{Enabled: true, ReserveOutputTokens: 10},
+		ModelCatalog: []config.ModelCatalogEntry{
+			{Provider: "local_vllm", Model: "qwen-small", ContextWindow: 100},
+			{Provider: "local_vllm", Model: "qwen-large", InputPrice: 0.01, ContextWindow: 1000},
+			{Provider: "deepseek", Model: "deepseek-chat", InputPrice: 0.27, ContextWindow: 1000, Tools: true},
+			{Provider: "google", Model: "gemini-long", InputPrice: 1, ContextWindow: 5000, Tools: true, Vision: true},
+		},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators: []config.EvaluatorConfig{{Name: "needs_tools", Type: "builtin_keywords", Rules: []config.KeywordRuleConfig{
+				{Keywords: []string{"search the web"}},
+			}}},
+			Resolution: config.ResolutionStrategyConfig{Type: "dynamic_expression", DefaultProvider: "local_vllm"},
+		},
+	}
+	defer func() { config.GlobalConfig = nil }()
+
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"deepseek":   &MockProvider{name: "deepseek"},
+		"google":     &MockProvider{name: "google"},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen-small"}
+
+	for prefix, want := range map[string]string{
+		"":                "local_vllm/qwen-large",
+		"search the web ": "deepseek/deepseek-chat",
+		"what is ![chart](https://x.test/c.png) ": "google/gemini-long",
+	} {
+		req := promptOfTokens(200)
+		req.Messages[0].Content = prefix + req.Messages[0].Content
+		p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", prefix, err)
+		}
+		if got := p.Name() + "/" + model; got != want {
+			t.Errorf("%q: expected %s, got %s", prefix, want, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+// sessionHeader returns the request header that names a conversation.
+func sessionHeader() string {
+	if config.GlobalConfig != nil && config.GlobalConfig.SessionAffinity.Header != "" {
+		return config.GlobalConfig.SessionAffinity.Header
+	}
+	return defaultAffinityHeader
+}
+
+// conversationID identifies the conversation a request belongs to: the session header,
+// else the OpenAI "user" field, else a hash of the system prompt and first user message,
+// which stay constant across turns. It returns "" when none of these is available.
+func conversationID(st *routeState, header string) string {
+	if v := st.meta.Headers.Get(header); v != "" {
+		return "session:" + v
+	}
+	if st.req.User != "" {
+		return "user:" + st.req.User
+	}
+
+	h := sha256.New()
+	for _, m := range st.req.Messages {
+		if m.Role != "system" && m.Role != "user" {
+			continue
+		}
+		h.Write([]byte(m.Role + "\x00" + m.Content + "\x00"))
+		if m.Role == "user" {
+			return "messages:" + hex.EncodeToString(h.Sum(nil)[:16])
+		}
+	}
+	return ""
+}
@@ -4,105 +4,495 @@ import (
 	"agentic-llm-gateway/pkg/logger"
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"agentic-llm-gateway/internal/config"
 	"agentic-llm-gateway/internal/models"
 	"agentic-llm-gateway/internal/providers"
 
+	"agentic-llm-gateway/pkg/catalog"
 	"agentic-llm-gateway/pkg/evaluator"
 	"agentic-llm-gateway/pkg/strategy"
+	"agentic-llm-gateway/pkg/tokenizer"
 
 	"github.com/expr-lang/expr"
 )
 
 // StrategyEngine directs a ChatCompletionRequest to the correct Provider based on the RemoteStrategy.
+// ctx bounds evaluator calls and may carry RequestMeta for header-based decisions.
 type StrategyEngine interface {
-	SelectProvider(req *models.ChatCompletionRequest, remoteCfg *config.RemoteStrategy) (providers.Provider, string, error)
+	SelectProvider(ctx context.Context, req *models.ChatCompletionRequest, remoteCfg *config.RemoteStrategy) (providers.Provider, string, error)
 }
 
+const (
+	defaultEvaluationCacheMaxEntries = 10000
+	defaultEvaluationCacheTTL        = 10 * time.Minute
+)
+
 type defaultEngine struct {
 	providerMap map[string]providers.Provider
 	evaluators  []evaluator.Evaluator
+	stages      map[string]int // evaluation stage by evaluator name
+	evalCache   *evaluator.Cache
+	catalog     *catalog.Catalog
+	counter     *tokenizer.Counter
+	affinity    *sessionAffinity
+	cascade     *cascade
+	health      *healthTracker
+	expression  expressionCache
+	routes      []route
+	schedules   []schedule
+	loc         *time.Location
+	now         func() time.Time
 }
 
-// NewEngine initializes a routing expression engine.
-func NewEngine(pMap map[string]providers.Provider) StrategyEngine {
+// NewEngine initializes a routing expression engine. It fails on routing configuration
+// that would otherwise be dropped at request time.
+func NewEngine(pMap map[string]providers.Provider) (StrategyEngine, error) {
+	counter := newEngineCounter()
 	var evals []evaluator.Evaluator
+	stages := make(map[string]int)
+	evalCache := newEngineEvaluationCache()
+	cat := newEngineCatalog(pMap)
 	if config.GlobalConfig != nil && config.GlobalConfig.GenerativeRouting != nil && config.GlobalConfig.GenerativeRouting.Enabled {
-		for _, eCfg := range config.GlobalConfig.GenerativeRouting.Evaluators {
-			if eCfg.Type == "llm_api" {
-				if ev, err := evaluator.NewLLMAPIEvaluator(eCfg); err == nil {
-					evals = append(evals, ev)
-				} else {
-					logger.Errorf("[Router] Failed to init LLM API Evaluator %s: %v", eCfg.Name, err)
-				}
-			} else if eCfg.Type == "llm_logprob_api" {
-				if ev, err := evaluator.NewLLMLogprobEvaluator(eCfg); err == nil {
-					evals = append(evals, ev)
-				} else {
-					logger.Errorf("[Router] Failed to init LLM Logprob API Evaluator %s: %v", eCfg.Name, err)
-				}
-			} else if eCfg.Type == "builtin" {
-				evals = append(evals, evaluator.NewBuiltinLengthEvaluator(eCfg))
+		genCfg := config.GlobalConfig.GenerativeRouting
+		if _, err := strategy.NewResolver(genCfg.Resolution, cat); err != nil {
+			return nil, fmt.Errorf("generative_routing.resolution_strategy: %w", err)
+		}
+		for _, eCfg := range genCfg.Evaluators {
+			ev, err := evaluator.New(eCfg, evaluator.Deps{Counter: counter})
+			if err != nil {
+				logger.Errorf("[Router] Failed to init evaluator %s: %v", eCfg.Name, err)
+				continue
+			}
+			if evalCache != nil && !eCfg.NoCache {
+				ev = evalCache.Wrap(ev)
 			}
+			evals = append(evals, ev)
+			stages[eCfg.Name] = eCfg.Stage
 		}
 	}
-	return &defaultEngine{
-		providerMap: pMap,
+	loc, err := newEngineLocation()
+	if err != nil {
+		return nil, err
+	}
+	routes, err := newEngineRoutes(loc)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := newEngineSchedules(loc)
+	if err != nil {
+		return nil, err
+	}
+	cascade, err := newEngineCascade(counter)
+	if err != nil {
+		return nil, fmt.Errorf("cascade: %w", err)
+	}
+	health := newHealthTracker()
+	e := &defaultEngine{
+		providerMap: trackHealth(pMap, health),
+		health:      health,
 		evaluators:  evals,
+		stages:      stages,
+		evalCache:   evalCache,
+		catalog:     cat,
+		counter:     counter,
+		cascade:     cascade,
+		routes:      routes,
+		schedules:   schedules,
+		loc:         loc,
+		now:         time.Now,
+	}
+	// Pins expire by the engine clock
+	if e.affinity, err = newSessionAffinity(e.clock, vectorDimensions(config.GlobalConfig)); err != nil {
+		return nil, fmt.Errorf("session_affinity: %w", err)
 	}
+	return e, nil
+}
+
+func newEngineRoutes(loc *time.Location) ([]route, error) {
+	if config.GlobalConfig == nil {
+		return nil, nil
+	}
+	return compileRoutes(config.GlobalConfig.Routes, loc, routeDimensions(config.GlobalConfig))
+}
+
+func newEngineCounter() *tokenizer.Counter {
+	if config.GlobalConfig == nil {
+		return nil
+	}
+	return tokenizer.NewCounter(config.GlobalConfig.Tokenizer)
+}
+
+// newEngineCatalog keeps only the catalog entries of configured providers, so that
+// catalog-aware strategies never pick a target the gateway cannot reach.
+func newEngineCatalog(pMap map[string]providers.Provider) *catalog.Catalog {
+	if config.GlobalConfig == nil || len(config.GlobalConfig.ModelCatalog) == 0 {
+		return nil
+	}
+	return catalog.New(config.GlobalConfig.ModelCatalog).Restrict(func(provider string) bool {
+		if _, ok := pMap[provider]; ok {
+			return true
+		}
+		logger.Warnf("[Router] Model catalog references unconfigured provider %s, ignoring entry", provider)
+		return false
+	})
 }
 
 // Env is the environment passed into the expression engine
 type Env struct {
 	Req *models.ChatCompletionRequest
 	Cfg *config.RemoteStrategy
+
+	// Vector is the intent vector. Evaluators only run for expressions that read it.
+	Vector map[string]float64
+	// Headers holds the first value of every request header, by canonical name.
+	Headers map[string]string
+	// KeyLabel is the key_labels label of the caller's API key, empty when unlabelled.
+	KeyLabel string
+	// Health is the recent latency and error rate of every configured provider.
+	Health map[string]ProviderHealth
+
+	// EstimatedTokens is the estimated prompt size of Req.
+	EstimatedTokens int
+	// ExpectedOutputTokens is Req.MaxTokens, or a default when the request is unbounded.
+	ExpectedOutputTokens int
+
+	// Now is the request time in the gateway timezone; Hour (0-23) and Weekday ("mon"
+	// to "sun") are derived from it.
+	Now      time.Time
+	Hour     int
+	Weekday  string
+	Timezone string
+
+	Tokens    func() int                `expr:"tokens"`            // EstimatedTokens
+	HasCode   func() bool               `expr:"has_code"`          // any message contains code, as builtin_code detects it
+	HasImages func() bool               `expr:"has_images"`        // any message embeds or links an image, as builtin_attachments detects it
+	LastUser  func() string             `expr:"last_user"`         // content of the last user message
+	Matches   func(pattern string) bool `expr:"matches_last_user"` // the last user message matches the regex; expr reserves "matches" for its operator
+
+	catalog *catalog.Catalog
 }
 
-func (e *defaultEngine) SelectProvider(req *models.ChatCompletionRequest, remoteCfg *config.RemoteStrategy) (providers.Provider, string, error) {
-	// Generative Smart Routing
-	if config.GlobalConfig != nil && config.GlobalConfig.GenerativeRouting != nil && config.GlobalConfig.GenerativeRouting.Enabled && len(e.evaluators) > 0 {
-		ctx := context.Background() // A real implementation would pass request context
-		genCfg := config.GlobalConfig.GenerativeRouting
-		vectors := evaluator.EvaluateAll(ctx, req.Messages, genCfg.GlobalTimeoutMs, e.evaluators)
+// EstimatedCost returns the estimated USD cost of serving the request with provider/model,
+// or -1 when the pair is not in the model catalog.
+func (env Env) EstimatedCost(provider, model string) float64 {
+	entry, ok := env.catalog.Lookup(provider, model)
+	if !ok {
+		return -1
+	}
+	return catalog.EstimateCost(entry, env.EstimatedTokens, 0, env.ExpectedOutputTokens)
+}
 
-		// Stage 5 Resolver usage
-		resolver := strategy.NewResolver(genCfg.Resolution)
-		targetProvider := ""
-		if resolver != nil {
-			targetProvider = resolver.Resolve(vectors)
+// expectedOutputTokens returns the completion length assumed for req.
+func expectedOutputTokens(req *models.ChatCompletionRequest) int {
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+	return catalog.DefaultOutputTokens
+}
+
+// routeState carries the per-request values shared by the routing stages.
+type routeState struct {
+	ctx          context.Context
+	req          *models.ChatCompletionRequest
+	remoteCfg    *config.RemoteStrategy
+	meta         *RequestMeta
+	trace        *Trace
+	promptTokens int
+	alias        []chainTarget // targets of a generative model alias
+
+	vector    map[string]float64
+	evaluated bool
+	skipped   []evaluator.Evaluator // left out of vector by an early staged decision
+}
+
+func (e *defaultEngine) SelectProvider(ctx context.Context, req *models.ChatCompletionRequest, remoteCfg *config.RemoteStrategy) (providers.Provider, string, error) {
+	remoteCfg, modelStrategy := remoteCfg.ForModel(req.Model)
+	if modelStrategy != "" {
+		logger.Debugf("[Router] Model %s uses remote model strategy %q", req.Model, modelStrategy)
+	}
+	remoteCfg, schedule := e.scheduledStrategy(remoteCfg)
+	if schedule != "" {
+		logger.Debugf("[Router] Schedule %q is active", schedule)
+	}
+
+	st := &routeState{
+		ctx:          ctx,
+		req:          req,
+		remoteCfg:    remoteCfg,
+		meta:         RequestMetaFrom(ctx),
+		trace:        traceFrom(ctx),
+		promptTokens: e.counter.CountMessages(req.Model, req.Messages),
+	}
+	if st.trace != nil {
+		st.trace.RemoteStrategy = remoteCfg
+		st.trace.ModelStrategy = modelStrategy
+		st.trace.Schedule = schedule
+		st.trace.PromptTokens = st.promptTokens
+	}
+
+	p, model, handled, err := e.selectHint(st)
+	if err == nil && !handled {
+		p, model, handled, err = e.selectAlias(st)
+	}
+	if err == nil && !handled {
+		p, model, err = e.selectWithAffinity(st)
+	}
+	if err == nil {
+		chosen, chosenModel := p, model
+		p, model, err = e.guardContextWindow(st, chosen, chosenModel)
+		if err == nil && st.trace != nil && (p != chosen || model != chosenModel) {
+			st.trace.ContextGuard = &ContextGuardTrace{Provider: chosen.Name(), Model: chosenModel}
 		}
+	}
 
-		if targetProvider == "" {
-			targetProvider = genCfg.FallbackProvider
-		}
-
-		if targetProvider != "" {
-			if p, ok := e.providerMap[targetProvider]; ok {
-				targetModel := req.Model
-				if remoteCfg != nil {
-					if targetProvider == "local_vllm" && remoteCfg.LocalModel != "" {
-						targetModel = remoteCfg.LocalModel
-					} else if targetProvider != "local_vllm" && remoteCfg.RemoteModel != "" {
-						targetModel = remoteCfg.RemoteModel
-					}
-				}
-				return p, targetModel, nil
-			}
-			logger.Warnf("[Router] Generative Routing fallback provider %s not found, continuing to normal routing...", targetProvider)
+	if st.trace != nil {
+		if err != nil {
+			st.trace.Error = err.Error()
+		} else {
+			st.trace.Provider, st.trace.Model = p.Name(), model
 		}
 	}
+	if err != nil {
+		return nil, "", err
+	}
+	return p, model, nil
+}
 
-	// Fallback if no strategy defined
-	if remoteCfg == nil || remoteCfg.Strategy == "" {
-		logger.Warnf("[Router] No remote strategy defined, defaulting to google")
-		if p, ok := e.providerMap["google"]; ok {
-			return p, req.Model, nil
+// generativeEnabled reports whether evaluators should run for routing decisions.
+func (e *defaultEngine) generativeEnabled() bool {
+	return config.GlobalConfig != nil && config.GlobalConfig.GenerativeRouting != nil && config.GlobalConfig.GenerativeRouting.Enabled && len(e.evaluators) > 0
+}
+
+// intentVector runs the evaluators at most once per request and returns the intent
+// vector enriched with router-provided dimensions. It returns nil when generative
+// routing is disabled.
+func (e *defaultEngine) intentVector(st *routeState) map[string]float64 {
+	return e.stagedIntentVector(st, nil)
+}
+
+// stagedIntentVector computes the intent vector like intentVector. When resolver can
+// decide on partial vectors, the evaluator stages stop as soon as it does and the vector
+// lacks the skipped dimensions; they are evaluated if a full vector is needed later.
+func (e *defaultEngine) stagedIntentVector(st *routeState, resolver strategy.Resolver) map[string]float64 {
+	if st.evaluated {
+		if resolver == nil && len(st.skipped) > 0 {
+			e.completeIntentVector(st)
 		}
-		if p, ok := e.providerMap["local_vllm"]; ok {
-			return p, req.Model, nil
+		return st.vector
+	}
+	st.evaluated = true
+	if !e.generativeEnabled() {
+		return nil
+	}
+
+	genCfg := config.GlobalConfig.GenerativeRouting
+	base := e.timeDimensions()
+	base[strategy.DimEstimatedTokens] = float64(st.promptTokens)
+	if st.req.MaxTokens > 0 {
+		base[strategy.DimExpectedOutputTokens] = float64(st.req.MaxTokens)
+	}
+	if evaluator.HasImages(st.req.Messages) {
+		base[strategy.DimNeedsVision] = 1
+	}
+	opts := evaluator.StageOptions{Base: base, Speculative: genCfg.SpeculativeStages}
+	if pr, ok := resolver.(strategy.PartialResolver); ok {
+		opts.Decided = func(vector map[string]float64) bool {
+			_, decided := pr.ResolvePartial(vector)
+			return decided
 		}
-		return nil, "", fmt.Errorf("no strategy and no sensible default providers found")
+	}
+
+	stages := e.evaluatorStages()
+	vector, outcomes := evaluator.EvaluateStaged(st.ctx, st.req.Messages, genCfg.GlobalTimeoutMs, stages, opts)
+	st.trace.recordEvaluators(outcomes)
+	if st.trace != nil && e.evalCache != nil {
+		stats := e.evalCache.Stats()
+		st.trace.EvaluatorCache = &EvaluatorCacheTrace{Hits: stats.Hits, Misses: stats.Misses, Evictions: stats.Evictions, Size: stats.Size}
+	}
+	for i, ev := range slices.Concat(stages...) {
+		if outcomes[i].Skipped {
+			st.skipped = append(st.skipped, ev)
+		}
+	}
+	st.vector = vector
+	if st.trace != nil {
+		st.trace.IntentVector = vector
+	}
+	return vector
+}
+
+// completeIntentVector runs the evaluators skipped by an early staged decision.
+func (e *defaultEngine) completeIntentVector(st *routeState) {
+	genCfg := config.GlobalConfig.GenerativeRouting
+	more, outcomes := evaluator.EvaluateAllWithOutcomes(st.ctx, st.req.Messages, genCfg.GlobalTimeoutMs, st.skipped)
+	st.skipped = nil
+	st.trace.recordEvaluators(outcomes)
+	maps.Copy(st.vector, more)
+}
+
+// newEngineEvaluationCache returns the evaluator result cache, or nil when disabled.
+func newEngineEvaluationCache() *evaluator.Cache {
+	if config.GlobalConfig == nil || config.GlobalConfig.GenerativeRouting == nil || !config.GlobalConfig.GenerativeRouting.Cache.Enabled {
+		return nil
+	}
+	cfg := config.GlobalConfig.GenerativeRouting.Cache
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultEvaluationCacheMaxEntries
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = defaultEvaluationCacheTTL
+	}
+	return evaluator.NewCache(maxEntries, ttl)
+}
+
+// evaluatorStages groups the evaluators by stage, lowest stage first.
+func (e *defaultEngine) evaluatorStages() [][]evaluator.Evaluator {
+	byStage := make(map[int][]evaluator.Evaluator)
+	for _, ev := range e.evaluators {
+		stage := e.stages[ev.Name()]
+		byStage[stage] = append(byStage[stage], ev)
+	}
+	stages := make([][]evaluator.Evaluator, 0, len(byStage))
+	for _, stage := range slices.Sorted(maps.Keys(byStage)) {
+		stages = append(stages, byStage[stage])
+	}
+	return stages
+}
+
+func (e *defaultEngine) selectProvider(st *routeState) (providers.Provider, string, error) {
+	if st.alias != nil {
+		p, model := e.selectAliasGenerative(st)
+		return p, model, nil
+	}
+
+	if p, model, ok, err := e.selectRoute(st); ok || err != nil {
+		return p, model, err
+	}
+
+	// The built-in routes always run after the route table, in this order.
+	for _, name := range builtinRoutes {
+		if p, model, ok, err := e.selectBuiltin(st, name); ok || err != nil {
+			return p, model, err
+		}
+	}
+	return nil, "", fmt.Errorf("no route selected a provider")
+}
+
+// Built-in route names, usable as route targets.
+const (
+	BuiltinGenerative = "generative"
+	BuiltinExpression = "expression"
+	BuiltinSplit      = "split"
+	BuiltinStrategy   = "strategy"
+)
+
+var builtinRoutes = []string{BuiltinGenerative, BuiltinExpression, BuiltinSplit, BuiltinStrategy}
+
+// selectBuiltin runs one built-in route. ok is false when the route does not apply.
+func (e *defaultEngine) selectBuiltin(st *routeState, name string) (p providers.Provider, model string, ok bool, err error) {
+	switch name {
+	case BuiltinGenerative:
+		p, model, ok = e.selectGenerative(st)
+	case BuiltinExpression:
+		p, model, ok = e.selectExpression(st)
+	case BuiltinSplit:
+		if p, model, ok = e.selectSplit(st); ok {
+			st.trace.decided("split")
+		}
+	case BuiltinStrategy:
+		p, model, err = e.selectStrategy(st)
+		ok = err == nil
+	default:
+		err = fmt.Errorf("unknown built-in route %q", name)
+	}
+	return p, model, ok, err
+}
+
+// hasStrategy reports whether the remote strategy defines a strategy or traffic split.
+func hasStrategy(remoteCfg *config.RemoteStrategy) bool {
+	return remoteCfg != nil && (remoteCfg.Strategy != "" || len(remoteCfg.Splits) > 0)
+}
+
+// selectGenerative resolves the intent vector to a provider (generative smart routing).
+func (e *defaultEngine) selectGenerative(st *routeState) (providers.Provider, string, bool) {
+	req, remoteCfg := st.req, st.remoteCfg
+
+	if !e.generativeEnabled() {
+		return nil, "", false
+	}
+	genCfg := config.GlobalConfig.GenerativeRouting
+
+	// Stage 5 Resolver usage
+	resolver, err := strategy.NewResolver(genCfg.Resolution, e.catalog)
+	if err != nil {
+		logger.Errorf("[Router] Invalid resolution strategy: %v", err)
+		return nil, "", false
+	}
+	vectors := e.stagedIntentVector(st, resolver)
+	if vectors == nil {
+		return nil, "", false
+	}
+	targetProvider := ""
+	resolvedModel := ""
+	if tr, ok := resolver.(strategy.TargetResolver); ok {
+		targetProvider, resolvedModel = tr.ResolveTarget(vectors)
+	} else if resolver != nil {
+		targetProvider = resolver.Resolve(vectors)
+	}
+
+	var resolution *ResolutionTrace
+	if st.trace != nil {
+		resolution = &ResolutionTrace{Provider: targetProvider, Model: resolvedModel}
+		if resolver != nil {
+			resolution.Resolver = resolver.Name()
+		}
+		if ex, ok := resolver.(strategy.Explainer); ok {
+			_, resolution.Rule = ex.Explain(vectors)
+		}
+		st.trace.Resolution = resolution
+	}
+
+	if targetProvider == "" {
+		targetProvider = genCfg.FallbackProvider
+		if resolution != nil {
+			resolution.Provider, resolution.Rule = targetProvider, "fallback_provider"
+		}
+	}
+	if targetProvider == "" {
+		return nil, "", false
+	}
+
+	p, ok := e.providerMap[targetProvider]
+	if !ok {
+		logger.Warnf("[Router] Generative Routing fallback provider %s not found, continuing to normal routing...", targetProvider)
+		return nil, "", false
+	}
+	targetModel := req.Model
+	if resolvedModel != "" {
+		targetModel = resolvedModel
+	} else if remoteCfg != nil {
+		if targetProvider == "local_vllm" && remoteCfg.LocalModel != "" {
+			targetModel = remoteCfg.LocalModel
+		} else if targetProvider != "local_vllm" && remoteCfg.RemoteModel != "" {
+			targetModel = remoteCfg.RemoteModel
+		}
+	}
+	st.trace.decided("generative")
+	return p, targetModel, true
+}
+
+// selectExpression evaluates the local remote_strategy.expression override, which only
+// applies when the remote strategy defines a strategy.
+func (e *defaultEngine) selectExpression(st *routeState) (providers.Provider, string, bool) {
+	req, remoteCfg := st.req, st.remoteCfg
+	if !hasStrategy(remoteCfg) || config.GlobalConfig == nil || config.GlobalConfig.RemoteStrategy.Expression == "" {
+		return nil, "", false
 	}
 
 	// Example usage of expr: evaluate custom routing condition
@@ -111,37 +501,92 @@ func (e *defaultEngine) SelectProvider(req *models.ChatCompletionRequest, remote
 	// res, _ := expr.Run(program, Env{Req: req, Cfg: remoteCfg})
 	// logger.Printf("[Router] Expr Result: %v", res)
 
-	if config.GlobalConfig != nil && config.GlobalConfig.RemoteStrategy.Expression != "" {
-		program, err := expr.Compile(config.GlobalConfig.RemoteStrategy.Expression, expr.Env(Env{}))
-		if err == nil {
-			res, err := expr.Run(program, Env{Req: req, Cfg: remoteCfg})
-			if err == nil {
-				if providerName, ok := res.(string); ok {
-					if p, exists := e.providerMap[providerName]; exists {
-						targetModel := req.Model
-
-						// If the Expr evaluated provider matches what this provider is mapped to in Remote strategy
-						// Check if it's the generic remote provider or local vllm
-						if providerName == "local_vllm" && remoteCfg.LocalModel != "" {
-							targetModel = remoteCfg.LocalModel
-						} else if remoteCfg.RemoteModel != "" {
-							// If it's a cloud provider, default to the remote model.
-							targetModel = remoteCfg.RemoteModel
-						}
-
-						return p, targetModel, nil
-					}
-					logger.Warnf("[Router] Expr matched unknown provider: %v", providerName)
-				}
-			} else {
-				logger.Errorf("[Router] Expr Run Error: %v", err)
-			}
-		} else {
-			logger.Errorf("[Router] Expr Compile Error: %v", err)
+	var exprTrace *ExpressionTrace
+	if st.trace != nil {
+		exprTrace = &ExpressionTrace{Expression: config.GlobalConfig.RemoteStrategy.Expression}
+		st.trace.Expression = exprTrace
+	}
+	program, usesVector, err := e.expression.get(config.GlobalConfig.RemoteStrategy.Expression)
+	if err != nil {
+		if exprTrace != nil {
+			exprTrace.Error = err.Error()
+		}
+		return nil, "", false
+	}
+
+	var vector map[string]float64
+	if usesVector {
+		vector = e.intentVector(st)
+	}
+	res, err := expr.Run(program, e.newEnv(st, vector))
+	if exprTrace != nil {
+		exprTrace.Result = res
+		if err != nil {
+			exprTrace.Error = err.Error()
+		}
+	}
+	if err != nil {
+		logger.Errorf("[Router] Expr Run Error: %v", err)
+		return nil, "", false
+	}
+
+	providerName, targetModel, ok := expressionTarget(res)
+	if !ok {
+		return nil, "", false
+	}
+	p, exists := e.providerMap[providerName]
+	if !exists {
+		logger.Warnf("[Router] Expr matched unknown provider: %v", providerName)
+		return nil, "", false
+	}
+
+	if targetModel == "" {
+		targetModel = req.Model
+		// If the Expr evaluated provider matches what this provider is mapped to in Remote strategy
+		// Check if it's the generic remote provider or local vllm
+		if providerName == "local_vllm" && remoteCfg.LocalModel != "" {
+			targetModel = remoteCfg.LocalModel
+		} else if remoteCfg.RemoteModel != "" {
+			// If it's a cloud provider, default to the remote model.
+			targetModel = remoteCfg.RemoteModel
 		}
 	}
 
+	if exprTrace != nil {
+		exprTrace.Fired = true
+	}
+	st.trace.decided("expression")
+	return p, targetModel, true
+}
+
+// selectStrategy applies the plain local/remote strategy, defaulting to google when the
+// remote strategy defines none.
+func (e *defaultEngine) selectStrategy(st *routeState) (providers.Provider, string, error) {
+	req, remoteCfg := st.req, st.remoteCfg
+
+	// Fallback if no strategy defined
+	if !hasStrategy(remoteCfg) {
+		logger.Warnf("[Router] No remote strategy defined, defaulting to google")
+		st.trace.decided("default")
+		if p, ok := e.providerMap["google"]; ok {
+			return p, req.Model, nil
+		}
+		if p, ok := e.providerMap["local_vllm"]; ok {
+			return p, req.Model, nil
+		}
+		return nil, "", fmt.Errorf("no strategy and no sensible default providers found")
+	}
+	if remoteCfg.Strategy == "" {
+		return nil, "", fmt.Errorf("no usable traffic split and no strategy defined")
+	}
+
 	// Since local_router.md says "based on remote JSON return ... local or remote", we evaluate strictly:
+	st.trace.decided("strategy")
+	return e.selectByStrategy(remoteCfg)
+}
+
+// selectByStrategy applies the plain local/remote strategy of remoteCfg.
+func (e *defaultEngine) selectByStrategy(remoteCfg *config.RemoteStrategy) (providers.Provider, string, error) {
 	if remoteCfg.Strategy == "remote" {
 		targetProvider := remoteCfg.RemoteProvider
 		if targetProvider == "" {
@@ -155,6 +600,10 @@ func (e *defaultEngine) SelectProvider(req *models.ChatCompletionRequest, remote
 		return p, remoteCfg.RemoteModel, nil
 	}
 
+	if remoteCfg.Strategy == "cascade" {
+		return e.cascadeProvider(remoteCfg)
+	}
+
 	if remoteCfg.Strategy == "local" {
 		p, ok := e.providerMap["local_vllm"]
 		if !ok {
@@ -0,0 +1,149 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func costTestCatalog() []config.ModelCatalogEntry {
+	return []config.ModelCatalogEntry{
+		{Provider: "local_vllm", Model: "qwen-32b", ContextWindow: 100},
+		{Provider: "deepseek", Model: "deepseek-chat", InputPrice: 0.27, OutputPrice: 1.10, ContextWindow: 65536},
+		{Provider: "anthropic", Model: "claude-sonnet-4", InputPrice: 3, OutputPrice: 15, ContextWindow: 200000},
+	}
+}
+
+func TestGenerativeRouting_CostOptimal(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		ModelCatalog: costTestCatalog(),
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators:      []config.EvaluatorConfig{{Name: "length_check", Type: "builtin", Threshold: 10}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:                 "cost_optimal",
+				DefaultProvider:      "anthropic",
+				ExpectedOutputTokens: 20,
+			},
+		},
+	}
+	defer func() { config.GlobalConfig = nil }()
+
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"deepseek":   &MockProvider{name: "deepseek"},
+		"anthropic":  &MockProvider{name: "anthropic"},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "anthropic", RemoteModel: "claude-sonnet-4"}
+
+	// A short prompt fits the free local model.
+	short := &models.ChatCompletionRequest{Model: "x", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+	p, model, err := engine.SelectProvider(context.Background(), short, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "local_vllm" || model != "qwen-32b" {
+		t.Errorf("expected local_vllm/qwen-32b, got %s/%s", p.Name(), model)
+	}
+
+	// A longer prompt overflows the local context window and goes to the next cheapest model.
+	long := &models.ChatCompletionRequest{Model: "x", Messages: []models.Message{{Role: "user", Content: strings.Repeat("word ", 200)}}}
+	p, model, err = engine.SelectProvider(context.Background(), long, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "deepseek" || model != "deepseek-chat" {
+		t.Errorf("expected deepseek/deepseek-chat, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestGenerativeRouting_CostOptimalCapabilities(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		ModelCatalog: []config.ModelCatalogEntry{
+			{Provider: "local_vllm", Model: "qwen-32b", ContextWindow: 32768},
+			{Provider: "deepseek", Model: "deepseek-chat", InputPrice: 0.27, OutputPrice: 1.10, ContextWindow: 65536, Tools: true},
+			{Provider: "anthropic", Model: "claude-sonnet-4", InputPrice: 3, OutputPrice: 15, ContextWindow: 200000, Tools: true, Vision: true},
+		},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			// needs_tools must come from an evaluator; needs_vision is derived by the router.
+			Evaluators: []config.EvaluatorConfig{{Name: "needs_tools", Type: "builtin_keywords", Rules: []config.KeywordRuleConfig{
+				{Keywords: []string{"search the web"}},
+			}}},
+			Resolution: config.ResolutionStrategyConfig{Type: "cost_optimal", DefaultProvider: "anthropic"},
+		},
+	}
+	defer func() { config.GlobalConfig = nil }()
+
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"deepseek":   &MockProvider{name: "deepseek"},
+		"anthropic":  &MockProvider{name: "anthropic"},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "anthropic"}
+
+	for content, want := range map[string]string{
+		"hi":                         "local_vllm",
+		"search the web for flights": "deepseek",
+		"describe ![chart](https://x.test/c.png)": "anthropic",
+	} {
+		req := &models.ChatCompletionRequest{Model: "x", Messages: []models.Message{{Role: "user", Content: content}}}
+		p, _, err := engine.SelectProvider(context.Background(), req, rcfg)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", content, err)
+		}
+		if p.Name() != want {
+			t.Errorf("%q: expected %s, got %s", content, want, p.Name())
+		}
+	}
+}
+
+func TestNewEngine_CatalogDropsUnconfiguredProviders(t *testing.T) {
+	config.GlobalConfig = &config.Config{ModelCatalog: costTestCatalog()}
+	defer func() { config.GlobalConfig = nil }()
+
+	engine := newTestEngine(t, map[string]providers.Provider{"deepseek": &MockProvider{name: "deepseek"}}).(*defaultEngine)
+	if len(engine.catalog.Entries()) != 1 {
+		t.Errorf("expected only the deepseek entry to remain, got %+v", engine.catalog.Entries())
+	}
+}
+
+func TestSelectProvider_ExprEstimatedCost(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		ModelCatalog: costTestCatalog(),
+		RemoteStrategy: config.RemoteStrategyConfig{
+			Expression: `EstimatedTokens > 0 && EstimatedCost('anthropic', 'claude-sonnet-4') > 0.01 ? 'deepseek' : 'anthropic'`,
+		},
+	}
+	defer func() { config.GlobalConfig = nil }()
+
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"deepseek":  &MockProvider{name: "deepseek"},
+		"anthropic": &MockProvider{name: "anthropic"},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "anthropic"}
+
+	// 512 expected output tokens at $15/M alone is below $0.01 ...
+	p, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Messages: []models.Message{{Role: "user", Content: "hi"}}}, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "anthropic" {
+		t.Errorf("expected anthropic for a cheap request, got %s", p.Name())
+	}
+
+	// ... while a large completion budget pushes the estimate over it.
+	p, _, err = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{MaxTokens: 4096, Messages: []models.Message{{Role: "user", Content: "hi"}}}, rcfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name() != "deepseek" {
+		t.Errorf("expected deepseek for an expensive request, got %s", p.Name())
+	}
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"testing"
 
 	"agentic-llm-gateway/internal/config"
@@ -9,10 +10,10 @@ import (
 )
 
 func TestSelectProvider_NilRemoteConfig_Google(t *testing.T) {
-	engine := NewEngine(map[string]providers.Provider{
+	engine := newTestEngine(t, map[string]providers.Provider{
 		"google": &MockProvider{name: "google"},
 	})
-	p, model, err := engine.SelectProvider(&models.ChatCompletionRequest{Model: "default-model"}, nil)
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "default-model"}, nil)
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -22,10 +23,10 @@ func TestSelectProvider_NilRemoteConfig_Google(t *testing.T) {
 }
 
 func TestSelectProvider_NilRemoteConfig_LocalVllm(t *testing.T) {
-	engine := NewEngine(map[string]providers.Provider{
+	engine := newTestEngine(t, map[string]providers.Provider{
 		"local_vllm": &MockProvider{name: "local_vllm"},
 	})
-	p, model, err := engine.SelectProvider(&models.ChatCompletionRequest{Model: "default-model"}, nil)
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "default-model"}, nil)
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -40,11 +41,11 @@ func TestSelectProvider_ExprCompileError(t *testing.T) {
 	}
 	defer func() { config.GlobalConfig = nil }()
 
-	engine := NewEngine(map[string]providers.Provider{
+	engine := newTestEngine(t, map[string]providers.Provider{
 		"google": &MockProvider{name: "google"},
 	})
 	// Will log error and fall through to default remote routing
-	p, _, err := engine.SelectProvider(&models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
+	p, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -59,8 +60,8 @@ func TestSelectProvider_ExprRunError(t *testing.T) {
 	}
 	defer func() { config.GlobalConfig = nil }()
 
-	engine := NewEngine(map[string]providers.Provider{"google": &MockProvider{name: "google"}})
-	p, _, err := engine.SelectProvider(&models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
+	engine := newTestEngine(t, map[string]providers.Provider{"google": &MockProvider{name: "google"}})
+	p, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -75,8 +76,8 @@ func TestSelectProvider_ExprWrongType(t *testing.T) {
 	}
 	defer func() { config.GlobalConfig = nil }()
 
-	engine := NewEngine(map[string]providers.Provider{"google": &MockProvider{name: "google"}})
-	p, _, err := engine.SelectProvider(&models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
+	engine := newTestEngine(t, map[string]providers.Provider{"google": &MockProvider{name: "google"}})
+	p, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -91,8 +92,8 @@ func TestSelectProvider_ExprUnknownProvider(t *testing.T) {
 	}
 	defer func() { config.GlobalConfig = nil }()
 
-	engine := NewEngine(map[string]providers.Provider{"google": &MockProvider{name: "google"}})
-	p, _, err := engine.SelectProvider(&models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
+	engine := newTestEngine(t, map[string]providers.Provider{"google": &MockProvider{name: "google"}})
+	p, _, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{}, &config.RemoteStrategy{Strategy: "remote"})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"testing"
 
 	"agentic-llm-gateway/internal/config"
@@ -31,12 +32,12 @@ func TestGenerativeRouting_FallbackProvider(t *testing.T) {
 	}
 	// NewEngine with no evaluators configured in GlobalConfig.Evaluators → evals slice empty.
 	// Generative routing block is skipped; falls through to normal routing.
-	engine := NewEngine(pMap)
+	engine := newTestEngine(t, pMap)
 
 	req := &models.ChatCompletionRequest{Model: "test-model", Messages: []models.Message{{Role: "user", Content: "hi"}}}
 	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "openai", RemoteModel: "gpt-5"}
 
-	p, model, err := engine.SelectProvider(req, rcfg)
+	p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -62,12 +63,12 @@ func TestGenerativeRouting_LocalVllmModel(t *testing.T) {
 	pMap := map[string]providers.Provider{
 		"local_vllm": &MockProvider{name: "local_vllm"},
 	}
-	engine := NewEngine(pMap)
+	engine := newTestEngine(t, pMap)
 
 	req := &models.ChatCompletionRequest{Model: "test-model", Messages: []models.Message{{Role: "user", Content: "hi"}}}
 	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "llama-3-8b"}
 
-	p, model, err := engine.SelectProvider(req, rcfg)
+	p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -84,8 +85,8 @@ func TestSelectProvider_UnknownStrategy(t *testing.T) {
 	pMap := map[string]providers.Provider{
 		"google": &MockProvider{name: "google"},
 	}
-	engine := NewEngine(pMap)
-	_, _, err := engine.SelectProvider(
+	engine := newTestEngine(t, pMap)
+	_, _, err := engine.SelectProvider(context.Background(),
 		&models.ChatCompletionRequest{Model: "x"},
 		&config.RemoteStrategy{Strategy: "unknown"},
 	)
@@ -96,8 +97,8 @@ func TestSelectProvider_UnknownStrategy(t *testing.T) {
 
 // TestSelectProvider_MissingRemoteProvider verifies error for unconfigured provider.
 func TestSelectProvider_MissingRemoteProvider(t *testing.T) {
-	engine := NewEngine(map[string]providers.Provider{})
-	_, _, err := engine.SelectProvider(
+	engine := newTestEngine(t, map[string]providers.Provider{})
+	_, _, err := engine.SelectProvider(context.Background(),
 		&models.ChatCompletionRequest{Model: "x"},
 		&config.RemoteStrategy{Strategy: "remote", RemoteProvider: "openai"},
 	)
@@ -108,8 +109,8 @@ func TestSelectProvider_MissingRemoteProvider(t *testing.T) {
 
 // TestSelectProvider_MissingLocalVllm verifies error when local_vllm not configured.
 func TestSelectProvider_MissingLocalVllm(t *testing.T) {
-	engine := NewEngine(map[string]providers.Provider{})
-	_, _, err := engine.SelectProvider(
+	engine := newTestEngine(t, map[string]providers.Provider{})
+	_, _, err := engine.SelectProvider(context.Background(),
 		&models.ChatCompletionRequest{Model: "x"},
 		&config.RemoteStrategy{Strategy: "local"},
 	)
@@ -120,8 +121,8 @@ func TestSelectProvider_MissingLocalVllm(t *testing.T) {
 
 // TestSelectProvider_NoFallbackProviders ensures a clear error when no defaults exist.
 func TestSelectProvider_NoFallbackProviders(t *testing.T) {
-	engine := NewEngine(map[string]providers.Provider{})
-	_, _, err := engine.SelectProvider(
+	engine := newTestEngine(t, map[string]providers.Provider{})
+	_, _, err := engine.SelectProvider(context.Background(),
 		&models.ChatCompletionRequest{Model: "x"},
 		nil,
 	)
@@ -143,12 +144,12 @@ func TestSelectProvider_ExpressionRouting_RemoteModelOverride(t *testing.T) {
 	pMap := map[string]providers.Provider{
 		"google": &MockProvider{name: "google"},
 	}
-	engine := NewEngine(pMap)
+	engine := newTestEngine(t, pMap)
 
 	req := &models.ChatCompletionRequest{Model: "original-model", Messages: []models.Message{{Role: "user", Content: "hi"}}}
 	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini-flash"}
 
-	p, model, err := engine.SelectProvider(req, rcfg)
+	p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
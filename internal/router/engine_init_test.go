@@ -1,6 +1,7 @@
 package router
 
 import (
+	"strings"
 	"testing"
 
 	"agentic-llm-gateway/internal/config"
@@ -15,20 +16,40 @@ func TestNewEngine_LoadsEvaluators(t *testing.T) {
 				{Name: "len", Type: "builtin"},
 				{Name: "api", Type: "llm_api", Protocol: "ollama", Endpoint: "http://localhost"},
 				{Name: "log", Type: "llm_logprob_api", Protocol: "openai", Endpoint: "http://localhost"},
+				{Name: "tok", Type: "builtin_tokens", Scope: "total", Threshold: 1000},
+				{Name: "badtok", Type: "builtin_tokens", Scope: "nonsense"},
 				{Name: "bad", Type: "unknown"},
 			},
 		},
 	}
 	defer func() { config.GlobalConfig = nil }()
 
-	engine := NewEngine(map[string]providers.Provider{})
+	engine := newTestEngine(t, map[string]providers.Provider{})
 	defEng, ok := engine.(*defaultEngine)
 	if !ok {
 		t.Fatal("expected engine to be *defaultEngine")
 	}
 
 	// Valid configs should be loaded, invalid skipped
-	if len(defEng.evaluators) != 3 {
-		t.Errorf("expected 3 valid evaluators loaded, got %d", len(defEng.evaluators))
+	if len(defEng.evaluators) != 4 {
+		t.Errorf("expected 4 valid evaluators loaded, got %d", len(defEng.evaluators))
+	}
+}
+
+func TestNewEngine_RejectsInvalidResolution(t *testing.T) {
+	config.GlobalConfig = &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:    true,
+			Evaluators: []config.EvaluatorConfig{{Name: "len", Type: "builtin"}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:  "dynamic_expression",
+				Rules: []config.ResolutionRuleConfig{{Condition: "len >", TargetProvider: "google"}},
+			},
+		},
+	}
+	defer func() { config.GlobalConfig = nil }()
+
+	if _, err := NewEngine(map[string]providers.Provider{}); err == nil || !strings.Contains(err.Error(), "rule 0") {
+		t.Errorf("expected the invalid rule to be rejected, got %v", err)
 	}
 }
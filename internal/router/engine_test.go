@@ -22,17 +22,27 @@ func (m *MockProvider) ChatCompletionStream(ctx context.Context, req *models.Cha
 	return nil
 }
 
+// newTestEngine creates an engine from the current GlobalConfig and fails t on error.
+func newTestEngine(t *testing.T, pMap map[string]providers.Provider) StrategyEngine {
+	t.Helper()
+	engine, err := NewEngine(pMap)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	return engine
+}
+
 func TestStrategyEngine_SelectProvider_Fallback(t *testing.T) {
 	pMap := map[string]providers.Provider{
 		"local_vllm": &MockProvider{name: "local_vllm"},
 		"google":     &MockProvider{name: "google"},
 		"openai":     &MockProvider{name: "openai"},
 	}
-	engine := NewEngine(pMap)
+	engine := newTestEngine(t, pMap)
 
 	// Test 1: No remote config
 	req := &models.ChatCompletionRequest{Model: "test-model"}
-	p, model, err := engine.SelectProvider(req, nil)
+	p, model, err := engine.SelectProvider(context.Background(), req, nil)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -45,7 +55,7 @@ func TestStrategyEngine_SelectProvider_Fallback(t *testing.T) {
 
 	// Test 2: Remote config defined strictly "remote" without explicit provider
 	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini-test"}
-	p, model, err = engine.SelectProvider(req, rcfg)
+	p, model, err = engine.SelectProvider(context.Background(), req, rcfg)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -58,7 +68,7 @@ func TestStrategyEngine_SelectProvider_Fallback(t *testing.T) {
 
 	// Test 2b: Remote config defined "remote" with explicit provider
 	rcfgOpenAI := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "openai", RemoteModel: "gpt-4"}
-	p, model, err = engine.SelectProvider(req, rcfgOpenAI)
+	p, model, err = engine.SelectProvider(context.Background(), req, rcfgOpenAI)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -71,7 +81,7 @@ func TestStrategyEngine_SelectProvider_Fallback(t *testing.T) {
 
 	// Test 3: Remote config defined strictly "local"
 	rcfg = &config.RemoteStrategy{Strategy: "local", LocalModel: "llama-3"}
-	p, model, err = engine.SelectProvider(req, rcfg)
+	p, model, err = engine.SelectProvider(context.Background(), req, rcfg)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -96,7 +106,7 @@ func TestStrategyEngine_SelectProvider_Expression(t *testing.T) {
 		"anthropic": &MockProvider{name: "anthropic"},
 		"google":    &MockProvider{name: "google"},
 	}
-	engine := NewEngine(pMap)
+	engine := newTestEngine(t, pMap)
 
 	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini-test-remote"}
 
@@ -109,7 +119,7 @@ func TestStrategyEngine_SelectProvider_Expression(t *testing.T) {
 			{Role: "user", Content: "3"},
 		},
 	}
-	p, model, err := engine.SelectProvider(req1, rcfg)
+	p, model, err := engine.SelectProvider(context.Background(), req1, rcfg)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -127,7 +137,7 @@ func TestStrategyEngine_SelectProvider_Expression(t *testing.T) {
 			{Role: "user", Content: "1"},
 		},
 	}
-	p, model, err = engine.SelectProvider(req2, rcfg)
+	p, model, err = engine.SelectProvider(context.Background(), req2, rcfg)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -138,3 +148,26 @@ func TestStrategyEngine_SelectProvider_Expression(t *testing.T) {
 		t.Errorf("expected model to remain 'other-model', got %v", model)
 	}
 }
+
+func TestStrategyEngine_SelectProvider_ModelStrategies(t *testing.T) {
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"openai":     &MockProvider{name: "openai"},
+	})
+	rcfg := &config.RemoteStrategy{
+		Strategy:   "local",
+		LocalModel: "qwen",
+		ModelStrategies: map[string]config.ModelStrategy{
+			"gpt-4o": {Strategy: "remote", RemoteProvider: "openai", RemoteModel: "gpt-4o"},
+		},
+	}
+
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}, rcfg)
+	if err != nil || p.Name() != "openai" || model != "gpt-4o" {
+		t.Errorf("expected openai/gpt-4o, got %v/%s (err %v)", p, model, err)
+	}
+	p, model, err = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o-mini"}, rcfg)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected the default local strategy, got %v/%s (err %v)", p, model, err)
+	}
+}
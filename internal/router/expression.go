@@ -0,0 +1,156 @@
+package router
+
+import (
+	"net/http"
+	"regexp"
+	"sync"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/cache"
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/logger"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// expressionCache holds the compiled remote_strategy.expression. The program is only
+// recompiled when the configured source changes.
+type expressionCache struct {
+	mu         sync.Mutex
+	source     string
+	program    *vm.Program
+	usesVector bool // the intent vector is only computed for expressions reading Vector
+	err        error
+}
+
+// get returns the program compiled from source, compiling it on first use or change.
+func (c *expressionCache) get(source string) (*vm.Program, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.program != nil || c.err != nil {
+		if c.source == source {
+			return c.program, c.usesVector, c.err
+		}
+	}
+	c.source, c.program, c.usesVector, c.err = source, nil, false, nil
+
+	program, err := compileExpression(source)
+	if err != nil {
+		logger.Errorf("[Router] Expr Compile Error: %v", err)
+		c.err = err
+		return nil, false, err
+	}
+	c.program = program
+	c.usesVector = ast.Find(program.Node(), func(n ast.Node) bool {
+		id, ok := n.(*ast.IdentifierNode)
+		return ok && id.Value == "Vector"
+	}) != nil
+	return c.program, c.usesVector, nil
+}
+
+// compileExpression compiles a remote_strategy.expression against Env.
+func compileExpression(source string) (*vm.Program, error) {
+	return expr.Compile(source, expr.Env(Env{}))
+}
+
+// newEnv builds the expression environment of a request. vector is nil unless the
+// expression reads it.
+func (e *defaultEngine) newEnv(st *routeState, vector map[string]float64) Env {
+	now := e.clock()
+	env := Env{
+		Req:                  st.req,
+		Cfg:                  st.remoteCfg,
+		Vector:               vector,
+		Headers:              firstHeaderValues(st.meta.Headers),
+		KeyLabel:             keyLabel(st.meta),
+		Health:               e.health.snapshot(e.providerMap),
+		EstimatedTokens:      st.promptTokens,
+		ExpectedOutputTokens: expectedOutputTokens(st.req),
+		Now:                  now,
+		Hour:                 now.Hour(),
+		Weekday:              config.WeekdayName(now.Weekday()),
+		Timezone:             e.loc.String(),
+		catalog:              e.catalog,
+	}
+	if env.Vector == nil {
+		env.Vector = map[string]float64{}
+	}
+	msgs := st.req.Messages
+	env.Tokens = func() int { return st.promptTokens }
+	env.HasCode = func() bool { return evaluator.HasCode(msgs) }
+	env.HasImages = func() bool { return evaluator.HasImages(msgs) }
+	env.LastUser = func() string { return lastUser(msgs) }
+	env.Matches = func(pattern string) bool {
+		re, err := cachedRegexp(pattern)
+		if err != nil {
+			logger.Warnf("[Router] Invalid regex %q in expression: %v", pattern, err)
+			return false
+		}
+		return re.MatchString(lastUser(msgs))
+	}
+	return env
+}
+
+// expressionTarget interprets the result of the expression: a provider name, or an
+// object with "provider" and optional "model" keys.
+func expressionTarget(res any) (provider, model string, ok bool) {
+	switch v := res.(type) {
+	case string:
+		return v, "", v != ""
+	case map[string]any:
+		provider, _ = v["provider"].(string)
+		model, _ = v["model"].(string)
+		return provider, model, provider != ""
+	}
+	return "", "", false
+}
+
+func firstHeaderValues(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if len(values) > 0 {
+			out[http.CanonicalHeaderKey(name)] = values[0]
+		}
+	}
+	return out
+}
+
+// keyLabel returns the key_labels label of the caller's API key.
+func keyLabel(meta *RequestMeta) string {
+	key := callerKey(meta)
+	if key == "" || config.GlobalConfig == nil {
+		return ""
+	}
+	return config.GlobalConfig.KeyLabels[key]
+}
+
+func lastUser(msgs []models.Message) string {
+	for i := len(msgs) - 1; i >= 0; i-- {
+		if msgs[i].Role == "user" {
+			return msgs[i].Content
+		}
+	}
+	return ""
+}
+
+// maxCachedRegexps bounds the compiled patterns kept for matches_last_user. Patterns
+// may be built from request content, so the cache must not grow with traffic.
+const maxCachedRegexps = 256
+
+var regexpCache = cache.New[string, *regexp.Regexp](maxCachedRegexps, 0)
+
+func cachedRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Get(pattern); ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache.Set(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,181 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/evaluator"
+)
+
+// countingEvaluator returns a fixed complexity score and counts its runs.
+type countingEvaluator struct{ runs int }
+
+func (c *countingEvaluator) Name() string       { return "complexity" }
+func (c *countingEvaluator) HistoryRounds() int { return 0 }
+func (c *countingEvaluator) Evaluate(ctx context.Context, msgs []models.Message) (*evaluator.EvaluationResult, error) {
+	c.runs++
+	return &evaluator.EvaluationResult{Dimension: "complexity", Score: 0.9}, nil
+}
+
+func expressionTestEngine(t *testing.T, expression string) (*defaultEngine, *countingEvaluator) {
+	t.Helper()
+	e := aliasTestEngine(t, &config.Config{
+		RemoteStrategy:    config.RemoteStrategyConfig{Expression: expression},
+		GenerativeRouting: &config.GenerativeRoutingConfig{Enabled: true},
+		KeyLabels:         map[string]string{"sk-search": "search-team"},
+	}, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+		"openai":     &failingProvider{MockProvider: MockProvider{name: "openai"}},
+	}).(*defaultEngine)
+	ev := &countingEvaluator{}
+	e.evaluators = []evaluator.Evaluator{ev}
+	return e, ev
+}
+
+// selectWithExpression runs the expression stage alone; with generative routing enabled
+// the generative stage would otherwise decide first.
+func selectWithExpression(t *testing.T, e *defaultEngine, req *models.ChatCompletionRequest, h http.Header) (string, string) {
+	t.Helper()
+	ctx := WithRequestMeta(context.Background(), &RequestMeta{Path: "/v1/chat/completions", Headers: h})
+	st := &routeState{
+		ctx:          ctx,
+		req:          req,
+		remoteCfg:    &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"},
+		meta:         RequestMetaFrom(ctx),
+		promptTokens: e.counter.CountMessages(req.Model, req.Messages),
+	}
+	p, model, ok := e.selectExpression(st)
+	if !ok {
+		t.Fatal("expected the expression to select a provider")
+	}
+	return p.Name(), model
+}
+
+func TestExpression_CompiledOnce(t *testing.T) {
+	e, _ := expressionTestEngine(t, `"google"`)
+	first, _, _ := e.expression.get(`"google"`)
+	again, _, _ := e.expression.get(`"google"`)
+	if first == nil || first != again {
+		t.Error("expected the compiled program to be reused")
+	}
+	changed, _, _ := e.expression.get(`"openai"`)
+	if changed == first {
+		t.Error("expected a changed expression to be recompiled")
+	}
+
+	if _, _, err := e.expression.get(`Nope(`); err == nil {
+		t.Error("expected a compile error")
+	}
+}
+
+func TestExpression_VectorOnlyWhenUsed(t *testing.T) {
+	e, ev := expressionTestEngine(t, `len(Req.Messages) > 2 ? "google" : "local_vllm"`)
+	if p, _ := selectWithExpression(t, e, conversation("hi"), nil); p != "local_vllm" {
+		t.Errorf("expected local_vllm, got %s", p)
+	}
+	if ev.runs != 0 {
+		t.Errorf("expected no evaluator runs, got %d", ev.runs)
+	}
+
+	e, ev = expressionTestEngine(t, `Vector["complexity"] > 0.5 ? "google" : "local_vllm"`)
+	if p, _ := selectWithExpression(t, e, conversation("hi"), nil); p != "google" || ev.runs != 1 {
+		t.Errorf("expected google after one evaluator run, got %s after %d", p, ev.runs)
+	}
+}
+
+func TestExpression_ObjectResult(t *testing.T) {
+	e, _ := expressionTestEngine(t, `{provider: "google", model: "gemini-flash"}`)
+	if p, model := selectWithExpression(t, e, conversation("hi"), nil); p != "google" || model != "gemini-flash" {
+		t.Errorf("expected google/gemini-flash, got %s/%s", p, model)
+	}
+
+	e, _ = expressionTestEngine(t, `{provider: "local_vllm"}`)
+	if p, model := selectWithExpression(t, e, conversation("hi"), nil); p != "local_vllm" || model != "qwen" {
+		t.Errorf("expected the strategy's local model, got %s/%s", p, model)
+	}
+}
+
+func TestExpression_RequestContext(t *testing.T) {
+	e, _ := expressionTestEngine(t, `KeyLabel == "search-team" && Headers["X-Tier"] == "gold" ? "google" : "local_vllm"`)
+	h := http.Header{}
+	h.Set("Authorization", "Bearer sk-search")
+	if p, _ := selectWithExpression(t, e, conversation("hi"), h); p != "local_vllm" {
+		t.Errorf("expected local_vllm without the tier header, got %s", p)
+	}
+	h.Set("X-Tier", "gold")
+	if p, _ := selectWithExpression(t, e, conversation("hi"), h); p != "google" {
+		t.Errorf("expected google for the labelled key, got %s", p)
+	}
+}
+
+func TestExpression_Helpers(t *testing.T) {
+	cases := []struct {
+		expression string
+		req        *models.ChatCompletionRequest
+		want       string
+	}{
+		{`has_code() ? "google" : "local_vllm"`, conversation("fix this:\n```go\nfunc main() {}\n```"), "google"},
+		{`has_code() ? "google" : "local_vllm"`, conversation("what is our return policy: 30 days?"), "local_vllm"},
+		{`has_images() ? "google" : "local_vllm"`, conversation("describe ![chart](https://x.test/chart.png)"), "google"},
+		{`matches_last_user("(?i)translate") ? "google" : "local_vllm"`, conversation("Translate this", "ok", "thanks"), "local_vllm"},
+		{`matches_last_user("(?i)translate") ? "google" : "local_vllm"`, conversation("hi", "hello", "please TRANSLATE"), "google"},
+		{`last_user() == "thanks" ? "google" : "local_vllm"`, conversation("hi", "hello", "thanks"), "google"},
+		{`tokens() == EstimatedTokens && tokens() > 0 ? "google" : "local_vllm"`, conversation("hi"), "google"},
+	}
+	for _, c := range cases {
+		e, _ := expressionTestEngine(t, c.expression)
+		if p, _ := selectWithExpression(t, e, c.req, nil); p != c.want {
+			t.Errorf("%s: expected %s, got %s", c.expression, c.want, p)
+		}
+	}
+}
+
+func TestExpression_RegexpCacheBounded(t *testing.T) {
+	for i := 0; i < 2*maxCachedRegexps; i++ {
+		if _, err := cachedRegexp(fmt.Sprintf("(?i)word%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := regexpCache.Len(); n > maxCachedRegexps {
+		t.Errorf("expected at most %d cached patterns, got %d", maxCachedRegexps, n)
+	}
+}
+
+func TestExpression_ProviderHealth(t *testing.T) {
+	e, _ := expressionTestEngine(t, `Health["openai"].Healthy ? "openai" : "google"`)
+	if p, _ := selectWithExpression(t, e, conversation("hi"), nil); p != "openai" {
+		t.Errorf("expected untried openai to count as healthy, got %s", p)
+	}
+
+	openai := e.providerMap["openai"]
+	for i := 0; i < 3; i++ {
+		if _, err := openai.ChatCompletion(context.Background(), conversation("hi")); err == nil {
+			t.Fatal("expected the failing provider to fail")
+		}
+	}
+	if p, _ := selectWithExpression(t, e, conversation("hi"), nil); p != "google" {
+		t.Errorf("expected failing openai to be avoided, got %s", p)
+	}
+}
+
+func TestHealthTracker_MovingAverages(t *testing.T) {
+	h := newHealthTracker()
+	h.record("p", 100e6, nil)
+	h.record("p", 200e6, nil)
+	h.record("p", 0, errors.New("boom"))
+
+	s := h.snapshot(map[string]providers.Provider{"p": nil, "q": nil})
+	if s["p"].Requests != 3 || s["p"].LatencyMs != 120 || s["p"].ErrorRate < 0.19 || s["p"].ErrorRate > 0.21 || !s["p"].Healthy {
+		t.Errorf("unexpected health %+v", s["p"])
+	}
+	if !s["q"].Healthy || s["q"].Requests != 0 {
+		t.Errorf("expected an untried provider to be healthy, got %+v", s["q"])
+	}
+}
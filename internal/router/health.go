@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+// healthAlpha is the weight of the newest call in the moving averages.
+const healthAlpha = 0.2
+
+// ProviderHealth is the recent track record of a provider, as seen by routing expressions.
+type ProviderHealth struct {
+	Healthy   bool    // false once the error rate reaches 50%
+	LatencyMs float64 // moving average of successful calls; time to first byte for streams
+	ErrorRate float64 // moving average between 0 and 1
+	Requests  int
+}
+
+// healthTracker records the outcome of every upstream call made through the engine's
+// providers.
+type healthTracker struct {
+	mu    sync.Mutex
+	stats map[string]ProviderHealth
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{stats: make(map[string]ProviderHealth)}
+}
+
+func (h *healthTracker) record(provider string, latency time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, seen := h.stats[provider]
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	if !seen {
+		s.ErrorRate = failed
+	} else {
+		s.ErrorRate += healthAlpha * (failed - s.ErrorRate)
+	}
+	if err == nil {
+		ms := float64(latency.Microseconds()) / 1000
+		if s.LatencyMs == 0 {
+			s.LatencyMs = ms
+		} else {
+			s.LatencyMs += healthAlpha * (ms - s.LatencyMs)
+		}
+	}
+	s.Requests++
+	s.Healthy = s.ErrorRate < 0.5
+	h.stats[provider] = s
+}
+
+// snapshot returns the health of every provider in pMap. Providers without calls yet
+// are reported healthy.
+func (h *healthTracker) snapshot(pMap map[string]providers.Provider) map[string]ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make(map[string]ProviderHealth, len(pMap))
+	for name := range pMap {
+		s, ok := h.stats[name]
+		if !ok {
+			s.Healthy = true
+		}
+		out[name] = s
+	}
+	return out
+}
+
+// trackHealth wraps every provider of pMap so that its calls are recorded in h.
+func trackHealth(pMap map[string]providers.Provider, h *healthTracker) map[string]providers.Provider {
+	tracked := make(map[string]providers.Provider, len(pMap))
+	for name, p := range pMap {
+		tracked[name] = &trackedProvider{Provider: p, key: name, health: h}
+	}
+	return tracked
+}
+
+// trackedProvider records the latency and errors of the provider it wraps.
+type trackedProvider struct {
+	providers.Provider
+	key    string
+	health *healthTracker
+}
+
+func (t *trackedProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	started := time.Now()
+	resp, err := t.Provider.ChatCompletion(ctx, req)
+	if ctx.Err() == nil {
+		t.health.record(t.key, time.Since(started), err)
+	}
+	return resp, err
+}
+
+func (t *trackedProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	started := time.Now()
+	err := t.Provider.ChatCompletionStream(ctx, req, streamChan)
+	if ctx.Err() == nil {
+		t.health.record(t.key, time.Since(started), err)
+	}
+	return err
+}
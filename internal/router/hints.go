@@ -0,0 +1,156 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// Request headers carrying client-side routing hints.
+const (
+	HeaderProvider = "X-Gateway-Provider"
+	HeaderStrategy = "X-Gateway-Strategy"
+)
+
+var (
+	// ErrOverrideNotAllowed is returned when a caller sends routing hints without being
+	// allowed to override routing.
+	ErrOverrideNotAllowed = errors.New("caller is not allowed to override routing")
+	// ErrInvalidHint is returned for routing hint headers that cannot be honoured.
+	ErrInvalidHint = errors.New("invalid routing hint")
+)
+
+var defaultProviderAliases = map[string]string{"local": "local_vllm"}
+
+// routingHint is the backend a caller asked for.
+type routingHint struct {
+	provider string // empty when only a strategy is requested
+	model    string // model with the provider prefix stripped; empty keeps the strategy model
+	strategy string // "local" or "remote"; only used without a provider
+}
+
+func hintsConfig() (config.RoutingHintsConfig, bool) {
+	if config.GlobalConfig == nil || !config.GlobalConfig.RoutingHints.Enabled {
+		return config.RoutingHintsConfig{}, false
+	}
+	return config.GlobalConfig.RoutingHints, true
+}
+
+// hintProvider resolves a provider name or alias to a configured provider name.
+func (e *defaultEngine) hintProvider(cfg config.RoutingHintsConfig, name string) (string, bool) {
+	if alias, ok := cfg.ProviderAliases[name]; ok {
+		name = alias
+	} else if alias, ok := defaultProviderAliases[name]; ok {
+		name = alias
+	}
+	_, ok := e.providerMap[name]
+	return name, ok
+}
+
+// parseHint reads the routing hints of the request. A model prefix only counts as a hint
+// when it names a configured provider or alias, so model names such as
+// "meta-llama/Llama-3-8B" pass through unchanged.
+func (e *defaultEngine) parseHint(st *routeState) (routingHint, bool, error) {
+	cfg, enabled := hintsConfig()
+	if !enabled {
+		return routingHint{}, false, nil
+	}
+
+	var hint routingHint
+	if prefix, model, found := strings.Cut(st.req.Model, "/"); found {
+		if provider, ok := e.hintProvider(cfg, prefix); ok {
+			hint.provider, hint.model = provider, model
+		}
+	}
+	// The header may repeat the provider of a model prefix, but not contradict it: the
+	// prefixed model belongs to the prefix provider.
+	if v := st.meta.Headers.Get(HeaderProvider); v != "" {
+		provider, ok := e.hintProvider(cfg, v)
+		if !ok {
+			return routingHint{}, false, fmt.Errorf("%w: %s names unconfigured provider %q", ErrInvalidHint, HeaderProvider, v)
+		}
+		if hint.provider != "" && hint.provider != provider {
+			return routingHint{}, false, fmt.Errorf("%w: %s %q conflicts with model %q", ErrInvalidHint, HeaderProvider, v, st.req.Model)
+		}
+		hint.provider = provider
+	}
+	if v := st.meta.Headers.Get(HeaderStrategy); v != "" {
+		if v != "local" && v != "remote" {
+			return routingHint{}, false, fmt.Errorf("%w: %s must be local or remote, got %q", ErrInvalidHint, HeaderStrategy, v)
+		}
+		hint.strategy = v
+	}
+
+	if hint.provider == "" && hint.strategy == "" {
+		return routingHint{}, false, nil
+	}
+	if !overrideAllowed(cfg, st.meta) {
+		return routingHint{}, false, ErrOverrideNotAllowed
+	}
+	return hint, true, nil
+}
+
+// overrideAllowed applies the caller key policy. Callers are denied unless allow_all is
+// set or their key is listed.
+func overrideAllowed(cfg config.RoutingHintsConfig, meta *RequestMeta) bool {
+	if cfg.AllowAll {
+		return true
+	}
+	key := callerKey(meta)
+	return key != "" && slices.Contains(cfg.AllowedKeys, key)
+}
+
+// callerKey returns the caller's API key, the bearer token of the Authorization header.
+func callerKey(meta *RequestMeta) string {
+	key, _ := strings.CutPrefix(meta.Headers.Get("Authorization"), "Bearer ")
+	return key
+}
+
+// selectHint routes the request as the caller asked, bypassing session affinity,
+// generative routing, the expression override and traffic splits. hinted is false
+// when the request carries no hints.
+func (e *defaultEngine) selectHint(st *routeState) (p providers.Provider, model string, hinted bool, err error) {
+	hint, hinted, err := e.parseHint(st)
+	if err != nil || !hinted {
+		return nil, "", false, err
+	}
+
+	if hint.provider != "" {
+		model = hint.model
+		if model == "" {
+			model = strategyModel(hint.provider, st.remoteCfg, st.req.Model)
+		}
+		logger.Infof("[Router] Routing hint selected %s/%s", hint.provider, model)
+		st.trace.decided("hint")
+		return e.providerMap[hint.provider], model, true, nil
+	}
+
+	cfg := config.RemoteStrategy{}
+	if st.remoteCfg != nil {
+		cfg = *st.remoteCfg
+	}
+	cfg.Strategy = hint.strategy
+	logger.Infof("[Router] Routing hint selected strategy %s", hint.strategy)
+	st.trace.decided("hint")
+	p, model, err = e.selectByStrategy(&cfg)
+	return p, model, true, err
+}
+
+// strategyModel returns the model the remote strategy assigns to provider, or fallback.
+func strategyModel(provider string, remoteCfg *config.RemoteStrategy, fallback string) string {
+	if remoteCfg == nil {
+		return fallback
+	}
+	if provider == "local_vllm" && remoteCfg.LocalModel != "" {
+		return remoteCfg.LocalModel
+	}
+	if provider != "local_vllm" && provider == remoteCfg.RemoteProvider && remoteCfg.RemoteModel != "" {
+		return remoteCfg.RemoteModel
+	}
+	return fallback
+}
@@ -0,0 +1,144 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func hintsTestEngine(t *testing.T, hints config.RoutingHintsConfig) StrategyEngine {
+	t.Helper()
+	config.GlobalConfig = &config.Config{
+		RoutingHints: hints,
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:          true,
+			FallbackProvider: "google",
+			Evaluators:       []config.EvaluatorConfig{{Name: "length_check", Type: "builtin", Threshold: 10}},
+		},
+	}
+	t.Cleanup(func() { config.GlobalConfig = nil })
+
+	return newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+		"anthropic":  &MockProvider{name: "anthropic"},
+	})
+}
+
+func hintCtx(headers map[string]string) context.Context {
+	h := http.Header{}
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	return WithRequestMeta(context.Background(), &RequestMeta{Headers: h})
+}
+
+var hintStrategy = &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini", LocalModel: "qwen"}
+
+func TestHints_ModelPrefix(t *testing.T) {
+	engine := hintsTestEngine(t, config.RoutingHintsConfig{Enabled: true, AllowAll: true})
+
+	cases := []struct{ model, provider, want string }{
+		{"anthropic/claude-sonnet-4", "anthropic", "claude-sonnet-4"},
+		{"local/qwen-7b", "local_vllm", "qwen-7b"},
+		// Unknown prefixes are part of the model name and go through generative routing.
+		{"meta-llama/Llama-3-8B", "google", "gemini"},
+	}
+	for _, c := range cases {
+		p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: c.model}, hintStrategy)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.model, err)
+		}
+		if p.Name() != c.provider || model != c.want {
+			t.Errorf("%s: expected %s/%s, got %s/%s", c.model, c.provider, c.want, p.Name(), model)
+		}
+	}
+}
+
+func TestHints_Disabled(t *testing.T) {
+	engine := hintsTestEngine(t, config.RoutingHintsConfig{})
+
+	p, _, err := engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "anthropic"}), &models.ChatCompletionRequest{Model: "anthropic/claude"}, hintStrategy)
+	if err != nil || p.Name() != "google" {
+		t.Errorf("expected hints to be ignored when disabled, got %v (err %v)", p, err)
+	}
+}
+
+func TestHints_Headers(t *testing.T) {
+	engine := hintsTestEngine(t, config.RoutingHintsConfig{Enabled: true, AllowAll: true, ProviderAliases: map[string]string{"claude": "anthropic"}})
+	req := func() *models.ChatCompletionRequest { return &models.ChatCompletionRequest{Model: "gpt-4o"} }
+
+	p, model, err := engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "claude"}), req(), hintStrategy)
+	if err != nil || p.Name() != "anthropic" || model != "gpt-4o" {
+		t.Errorf("expected anthropic with the requested model, got %v/%s (err %v)", p, model, err)
+	}
+
+	p, model, err = engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "local_vllm"}), req(), hintStrategy)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected local_vllm with the strategy's local model, got %v/%s (err %v)", p, model, err)
+	}
+
+	p, model, err = engine.SelectProvider(hintCtx(map[string]string{HeaderStrategy: "local"}), req(), hintStrategy)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected the local strategy, got %v/%s (err %v)", p, model, err)
+	}
+	if hintStrategy.Strategy != "remote" {
+		t.Error("expected the shared remote strategy to be left untouched")
+	}
+
+	_, _, err = engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "missing"}), req(), hintStrategy)
+	if !errors.Is(err, ErrInvalidHint) {
+		t.Errorf("expected ErrInvalidHint for an unknown provider, got %v", err)
+	}
+	_, _, err = engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "google"}), &models.ChatCompletionRequest{Model: "anthropic/claude"}, hintStrategy)
+	if !errors.Is(err, ErrInvalidHint) {
+		t.Errorf("expected ErrInvalidHint for a header contradicting the model prefix, got %v", err)
+	}
+	p, model, err = engine.SelectProvider(hintCtx(map[string]string{HeaderProvider: "claude"}), &models.ChatCompletionRequest{Model: "anthropic/claude"}, hintStrategy)
+	if err != nil || p.Name() != "anthropic" || model != "claude" {
+		t.Errorf("expected a header agreeing with the prefix to keep the model, got %v/%s (err %v)", p, model, err)
+	}
+	_, _, err = engine.SelectProvider(hintCtx(map[string]string{HeaderStrategy: "cheapest"}), req(), hintStrategy)
+	if !errors.Is(err, ErrInvalidHint) {
+		t.Errorf("expected ErrInvalidHint for an unknown strategy, got %v", err)
+	}
+}
+
+func TestHints_KeyPolicy(t *testing.T) {
+	engine := hintsTestEngine(t, config.RoutingHintsConfig{Enabled: true, AllowedKeys: []string{"sk-ops"}})
+	req := func() *models.ChatCompletionRequest { return &models.ChatCompletionRequest{Model: "anthropic/claude"} }
+
+	_, _, err := engine.SelectProvider(hintCtx(map[string]string{"Authorization": "Bearer sk-app"}), req(), hintStrategy)
+	if !errors.Is(err, ErrOverrideNotAllowed) {
+		t.Errorf("expected ErrOverrideNotAllowed for another key, got %v", err)
+	}
+	_, _, err = engine.SelectProvider(context.Background(), req(), hintStrategy)
+	if !errors.Is(err, ErrOverrideNotAllowed) {
+		t.Errorf("expected ErrOverrideNotAllowed without a key, got %v", err)
+	}
+
+	p, _, err := engine.SelectProvider(hintCtx(map[string]string{"Authorization": "Bearer sk-ops"}), req(), hintStrategy)
+	if err != nil || p.Name() != "anthropic" {
+		t.Errorf("expected the allowed key to override routing, got %v (err %v)", p, err)
+	}
+
+	// Requests without hints are unaffected by the policy.
+	p, _, err = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "gpt-4o"}, hintStrategy)
+	if err != nil || p.Name() != "google" {
+		t.Errorf("expected normal routing without hints, got %v (err %v)", p, err)
+	}
+}
+
+func TestHints_DenyByDefault(t *testing.T) {
+	engine := hintsTestEngine(t, config.RoutingHintsConfig{Enabled: true})
+
+	_, _, err := engine.SelectProvider(hintCtx(map[string]string{"Authorization": "Bearer sk-app"}), &models.ChatCompletionRequest{Model: "anthropic/claude"}, hintStrategy)
+	if !errors.Is(err, ErrOverrideNotAllowed) {
+		t.Errorf("expected ErrOverrideNotAllowed without allowed keys or allow_all, got %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestMeta carries the transport-level attributes of an inbound request that are
+// not part of the OpenAI payload, such as the request path and headers.
+type RequestMeta struct {
+	Path    string
+	Headers http.Header
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta returns a copy of ctx carrying meta for SelectProvider.
+func WithRequestMeta(ctx context.Context, meta *RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFrom returns the metadata attached to ctx, or an empty RequestMeta.
+func RequestMetaFrom(ctx context.Context) *RequestMeta {
+	if meta, ok := ctx.Value(requestMetaKey{}).(*RequestMeta); ok && meta != nil {
+		return meta
+	}
+	return &RequestMeta{Headers: http.Header{}}
+}
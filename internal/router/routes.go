@@ -0,0 +1,208 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/logger"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+// route is a compiled entry of the route table.
+type route struct {
+	cfg        config.RouteConfig
+	expression *vm.Program
+	window     *config.Window
+}
+
+// compileRoutes prepares the configured route table. Expressions may only read dims.
+// Time windows without a timezone use loc. Every invalid route is reported, since
+// skipping one would send its traffic to the next matching route.
+func compileRoutes(cfgs []config.RouteConfig, loc *time.Location, dims []string) ([]route, error) {
+	env := make(map[string]interface{}, len(dims))
+	for _, d := range dims {
+		env[d] = 0.0
+	}
+	var routes []route
+	var errs []error
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("route-%d", i)
+		}
+		r := route{cfg: cfg}
+		if cfg.Match.Expression != "" {
+			program, err := expr.Compile(cfg.Match.Expression, expr.Env(env), expr.AsBool())
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route %s: invalid expression: %w", cfg.Name, err))
+			}
+			r.expression = program
+		}
+		if cfg.Match.TimeWindow != nil {
+			w, err := cfg.Match.TimeWindow.Parse(loc)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("route %s: invalid time window: %w", cfg.Name, err))
+			}
+			r.window = w
+		}
+		if b := cfg.Target.Builtin; b != "" && !slices.Contains(builtinRoutes, b) {
+			errs = append(errs, fmt.Errorf("route %s targets unknown built-in route %q", cfg.Name, b))
+		}
+		routes = append(routes, r)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// matches reports whether st meets every condition of the route. The intent vector is
+// only computed for routes with an expression, after the cheaper conditions passed.
+func (e *defaultEngine) matches(r *route, st *routeState) bool {
+	m := r.cfg.Match
+
+	if len(m.Models) > 0 && !matchesAnyGlob(m.Models, st.req.Model) {
+		return false
+	}
+	if len(m.Paths) > 0 && !matchesAnyGlob(m.Paths, st.meta.Path) {
+		return false
+	}
+	for name, want := range m.Headers {
+		got := st.meta.Headers.Get(name)
+		if got == "" || (want != "*" && got != want) {
+			return false
+		}
+	}
+	if len(m.Keys) > 0 && !slices.Contains(m.Keys, callerKey(st.meta)) {
+		return false
+	}
+	if m.MinMessages > 0 && len(st.req.Messages) < m.MinMessages {
+		return false
+	}
+	if m.MaxMessages > 0 && len(st.req.Messages) > m.MaxMessages {
+		return false
+	}
+	if r.window != nil && !r.window.Contains(e.now()) {
+		return false
+	}
+	if r.expression != nil {
+		env := map[string]interface{}{}
+		for k, v := range e.timeDimensions() {
+			env[k] = v
+		}
+		for k, v := range e.intentVector(st) {
+			env[k] = v
+		}
+		res, err := expr.Run(r.expression, env)
+		if err != nil {
+			logger.Warnf("[Router] Expression of route %s failed: %v", r.cfg.Name, err)
+			return false
+		}
+		if b, ok := res.(bool); !ok || !b {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAnyGlob(patterns []string, s string) bool {
+	return slices.ContainsFunc(patterns, func(p string) bool {
+		matched, err := path.Match(p, s)
+		return err == nil && matched
+	})
+}
+
+// selectRoute walks the route table. ok is false when no route matched and resolved.
+func (e *defaultEngine) selectRoute(st *routeState) (p providers.Provider, model string, ok bool, err error) {
+	for i := range e.routes {
+		r := &e.routes[i]
+		if !e.matches(r, st) {
+			continue
+		}
+		p, model, ok, err = e.routeTarget(st, r)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("route %s: %w", r.cfg.Name, err)
+		}
+		if !ok {
+			logger.Debugf("[Router] Route %s matched but its target did not resolve, trying next route", r.cfg.Name)
+			continue
+		}
+		applyTransforms(st.req, r.cfg.Transforms)
+		logger.Infof("[Router] Route %s selected %s/%s", r.cfg.Name, p.Name(), model)
+		if st.trace != nil {
+			st.trace.Route = r.cfg.Name
+			if r.cfg.Target.Builtin == "" {
+				st.trace.Stage = "route"
+			}
+		}
+		return p, model, true, nil
+	}
+	return nil, "", false, nil
+}
+
+// routeTarget resolves the target of a matched route.
+func (e *defaultEngine) routeTarget(st *routeState, r *route) (providers.Provider, string, bool, error) {
+	t := r.cfg.Target
+	switch {
+	case t.Builtin != "":
+		return e.selectBuiltin(st, t.Builtin)
+
+	case t.Provider != "":
+		p, ok := e.providerMap[t.Provider]
+		if !ok {
+			logger.Warnf("[Router] Route %s targets unconfigured provider %s", r.cfg.Name, t.Provider)
+			return nil, "", false, nil
+		}
+		model := t.Model
+		if model == "" {
+			model = strategyModel(t.Provider, st.remoteCfg, st.req.Model)
+		}
+		return p, model, true, nil
+
+	case len(t.Fallback) > 0:
+		var targets []chainTarget
+		for _, ft := range t.Fallback {
+			if p, ok := e.providerMap[ft.Provider]; ok {
+				targets = append(targets, chainTarget{provider: p, model: ft.Model})
+			}
+		}
+		switch len(targets) {
+		case 0:
+			return nil, "", false, nil
+		case 1:
+			return targets[0].provider, targets[0].model, true, nil
+		}
+		return &chainProvider{name: r.cfg.Name, targets: targets}, targets[0].model, true, nil
+
+	case len(t.Splits) > 0:
+		remoteCfg := config.RemoteStrategy{Splits: t.Splits}
+		if st.remoteCfg != nil {
+			remoteCfg.LocalModel, remoteCfg.RemoteModel = st.remoteCfg.LocalModel, st.remoteCfg.RemoteModel
+		}
+		sub := *st
+		sub.remoteCfg = &remoteCfg
+		p, model, ok := e.selectSplit(&sub)
+		return p, model, ok, nil
+	}
+	return nil, "", false, fmt.Errorf("no target configured")
+}
+
+// applyTransforms adjusts the request for its route.
+func applyTransforms(req *models.ChatCompletionRequest, t config.RouteTransforms) {
+	if t.MaxTokens > 0 && (req.MaxTokens <= 0 || req.MaxTokens > t.MaxTokens) {
+		req.MaxTokens = t.MaxTokens
+	}
+	if t.Temperature != nil {
+		req.Temperature = *t.Temperature
+	}
+	if t.SystemPrompt != "" {
+		req.Messages = append([]models.Message{{Role: "system", Content: t.SystemPrompt}}, req.Messages...)
+	}
+}
@@ -0,0 +1,189 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func routesTestEngine(t *testing.T, routes []config.RouteConfig) *defaultEngine {
+	t.Helper()
+	return aliasTestEngine(t, &config.Config{Routes: routes}, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+	}).(*defaultEngine)
+}
+
+func TestRoutes_FirstMatchWins(t *testing.T) {
+	engine := routesTestEngine(t, []config.RouteConfig{
+		{Name: "coders", Match: config.RouteMatch{Models: []string{"code-*"}}, Target: config.RouteTarget{Provider: "local_vllm", Model: "qwen-coder"}},
+		{Name: "catch-all", Target: config.RouteTarget{Provider: "google", Model: "gemini"}},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+
+	p, model, err := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "code-large"}, rcfg)
+	if err != nil || p.Name() != "local_vllm" || model != "qwen-coder" {
+		t.Errorf("expected local_vllm/qwen-coder, got %v/%s (err %v)", p, model, err)
+	}
+	p, model, _ = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "chat"}, rcfg)
+	if p.Name() != "google" || model != "gemini" {
+		t.Errorf("expected the catch-all route, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestRoutes_NoMatchFallsThroughToBuiltins(t *testing.T) {
+	engine := routesTestEngine(t, []config.RouteConfig{
+		{Name: "premium", Match: config.RouteMatch{Headers: map[string]string{"X-Tier": "premium"}}, Target: config.RouteTarget{Provider: "google"}},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	p, model, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected the strategy route, got %s/%s", p.Name(), model)
+	}
+
+	h := http.Header{}
+	h.Set("X-Tier", "premium")
+	ctx := WithRequestMeta(context.Background(), &RequestMeta{Path: "/v1/chat/completions", Headers: h})
+	p, model, _ = engine.SelectProvider(ctx, &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if p.Name() != "google" || model != "gemini" {
+		t.Errorf("expected google with the strategy's remote model, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestRoutes_UnresolvedTargetTriesNextRoute(t *testing.T) {
+	engine := routesTestEngine(t, []config.RouteConfig{
+		{Name: "missing", Target: config.RouteTarget{Provider: "openai"}},
+		{Name: "builtin", Target: config.RouteTarget{Builtin: BuiltinStrategy}},
+	})
+	trace := &Trace{}
+	ctx := WithTrace(context.Background(), trace)
+
+	p, _, err := engine.SelectProvider(ctx, &models.ChatCompletionRequest{Model: "m"}, &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini"})
+	if err != nil || p.Name() != "google" {
+		t.Fatalf("expected google via the builtin route, got %v (err %v)", p, err)
+	}
+	if trace.Route != "builtin" || trace.Stage != "strategy" {
+		t.Errorf("expected route builtin with stage strategy, got %q/%q", trace.Route, trace.Stage)
+	}
+}
+
+func TestRoutes_MatchConditions(t *testing.T) {
+	cases := []struct {
+		name  string
+		match config.RouteMatch
+		want  bool
+	}{
+		{"path", config.RouteMatch{Paths: []string{"/v1/chat/*"}}, true},
+		{"other path", config.RouteMatch{Paths: []string{"/v2/*"}}, false},
+		{"any header value", config.RouteMatch{Headers: map[string]string{"X-Team": "*"}}, true},
+		{"missing header", config.RouteMatch{Headers: map[string]string{"X-Other": "*"}}, false},
+		{"key", config.RouteMatch{Keys: []string{"sk-team"}}, true},
+		{"other key", config.RouteMatch{Keys: []string{"sk-other"}}, false},
+		{"min messages", config.RouteMatch{MinMessages: 3}, true},
+		{"max messages", config.RouteMatch{MaxMessages: 2}, false},
+		{"expression", config.RouteMatch{Expression: "hour >= 0"}, true},
+		{"false expression", config.RouteMatch{Expression: "hour < 0"}, false},
+	}
+
+	h := http.Header{}
+	h.Set("X-Team", "search")
+	h.Set("Authorization", "Bearer sk-team")
+	for _, c := range cases {
+		engine := routesTestEngine(t, []config.RouteConfig{{Name: c.name, Match: c.match, Target: config.RouteTarget{Provider: "google"}}})
+		ctx := WithRequestMeta(context.Background(), &RequestMeta{Path: "/v1/chat/completions", Headers: h})
+		p, _, _ := engine.SelectProvider(ctx, conversation("a", "b", "c"), &config.RemoteStrategy{Strategy: "local"})
+		if got := p.Name() == "google"; got != c.want {
+			t.Errorf("%s: expected match %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRoutes_InvalidRoutesRejected(t *testing.T) {
+	_, err := compileRoutes([]config.RouteConfig{
+		{Name: "bad-expr", Match: config.RouteMatch{Expression: "(("}, Target: config.RouteTarget{Provider: "google"}},
+		{Name: "bad-window", Match: config.RouteMatch{TimeWindow: &config.TimeWindow{Start: "25:00"}}, Target: config.RouteTarget{Provider: "google"}},
+		{Name: "bad-builtin", Target: config.RouteTarget{Builtin: "nope"}},
+		{Name: "typo", Match: config.RouteMatch{Expression: "complexty > 0.5"}, Target: config.RouteTarget{Provider: "google"}},
+		{Name: "ok", Match: config.RouteMatch{Expression: "complexity > 0.5 && hour >= 9"}, Target: config.RouteTarget{Provider: "google"}},
+	}, time.UTC, append([]string{"complexity"}, clockDimensions...))
+	if err == nil {
+		t.Fatal("expected the invalid routes to be rejected")
+	}
+	for _, name := range []string{"bad-expr", "bad-window", "bad-builtin", "typo"} {
+		if !strings.Contains(err.Error(), "route "+name) {
+			t.Errorf("expected route %s to be reported, got %v", name, err)
+		}
+	}
+	if strings.Contains(err.Error(), "route ok") {
+		t.Errorf("expected the valid route to pass, got %v", err)
+	}
+
+	config.GlobalConfig = &config.Config{Routes: []config.RouteConfig{{Name: "typo", Match: config.RouteMatch{Expression: "complexty > 0.5"}}}}
+	defer func() { config.GlobalConfig = nil }()
+	if _, err := NewEngine(map[string]providers.Provider{}); err == nil {
+		t.Error("expected NewEngine to reject the invalid route")
+	}
+}
+
+func TestRoutes_Transforms(t *testing.T) {
+	temp := 0.2
+	engine := routesTestEngine(t, []config.RouteConfig{{
+		Name:       "capped",
+		Target:     config.RouteTarget{Provider: "local_vllm", Model: "qwen"},
+		Transforms: config.RouteTransforms{MaxTokens: 256, Temperature: &temp, SystemPrompt: "Be brief."},
+	}})
+
+	req := &models.ChatCompletionRequest{Model: "m", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+	if _, _, err := engine.SelectProvider(context.Background(), req, &config.RemoteStrategy{Strategy: "local"}); err != nil {
+		t.Fatal(err)
+	}
+	if req.MaxTokens != 256 || req.Temperature != 0.2 {
+		t.Errorf("expected max_tokens 256 and temperature 0.2, got %d/%v", req.MaxTokens, req.Temperature)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Role != "system" || req.Messages[0].Content != "Be brief." {
+		t.Errorf("expected a prepended system prompt, got %+v", req.Messages)
+	}
+
+	req = &models.ChatCompletionRequest{Model: "m", MaxTokens: 100}
+	engine.SelectProvider(context.Background(), req, &config.RemoteStrategy{Strategy: "local"})
+	if req.MaxTokens != 100 {
+		t.Errorf("expected a smaller max_tokens to be kept, got %d", req.MaxTokens)
+	}
+}
+
+func TestRoutes_FallbackTarget(t *testing.T) {
+	engine := routesTestEngine(t, []config.RouteConfig{{
+		Name:   "resilient",
+		Target: config.RouteTarget{Fallback: []config.AliasTarget{{Provider: "local_vllm", Model: "qwen"}, {Provider: "google", Model: "gemini"}}},
+	}})
+
+	p, model, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, &config.RemoteStrategy{Strategy: "local"})
+	if p.Name() != "alias:resilient" || model != "qwen" {
+		t.Errorf("expected a fallback chain starting at qwen, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestRoutes_TimeWindowUsesEngineClock(t *testing.T) {
+	engine := routesTestEngine(t, []config.RouteConfig{{
+		Name:   "off-hours",
+		Match:  config.RouteMatch{TimeWindow: &config.TimeWindow{Start: "20:00", End: "08:00"}},
+		Target: config.RouteTarget{Provider: "local_vllm"},
+	}})
+	rcfg := &config.RemoteStrategy{Strategy: "remote"}
+
+	engine.now = func() time.Time { return time.Date(2026, 10, 14, 22, 0, 0, 0, time.UTC) }
+	if p, _, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, rcfg); p.Name() != "local_vllm" {
+		t.Errorf("expected local_vllm off hours, got %s", p.Name())
+	}
+	engine.now = func() time.Time { return time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }
+	if p, _, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, rcfg); p.Name() != "google" {
+		t.Errorf("expected google during the day, got %s", p.Name())
+	}
+}
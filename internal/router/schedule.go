@@ -0,0 +1,114 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/strategy"
+)
+
+// schedule is a local schedule block with its parsed window.
+type schedule struct {
+	cfg    config.Schedule
+	window *config.Window
+}
+
+// newEngineLocation returns the gateway timezone, UTC when unset.
+func newEngineLocation() (*time.Location, error) {
+	if config.GlobalConfig == nil {
+		return time.UTC, nil
+	}
+	return configLocation(config.GlobalConfig)
+}
+
+// configLocation returns the timezone of cfg, UTC when unset.
+func configLocation(cfg *config.Config) (*time.Location, error) {
+	if cfg.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := config.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+	}
+	return loc, nil
+}
+
+// newEngineSchedules parses the local schedules.
+func newEngineSchedules(loc *time.Location) ([]schedule, error) {
+	if config.GlobalConfig == nil {
+		return nil, nil
+	}
+	return compileSchedules(config.GlobalConfig.Schedules, loc)
+}
+
+// compileSchedules parses schedule blocks whose windows default to loc. Every invalid
+// block is reported, since skipping one would silently keep its strategy switch off.
+func compileSchedules(cfgs []config.Schedule, loc *time.Location) ([]schedule, error) {
+	var schedules []schedule
+	var errs []error
+	for i, cfg := range cfgs {
+		if cfg.Name == "" {
+			cfg.Name = fmt.Sprintf("schedule-%d", i)
+		}
+		w, err := cfg.TimeWindow.Parse(loc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("schedule %s: %w", cfg.Name, err))
+			continue
+		}
+		schedules = append(schedules, schedule{cfg: cfg, window: w})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// clock returns the current time in the gateway timezone.
+func (e *defaultEngine) clock() time.Time {
+	return e.now().In(e.loc)
+}
+
+// timeDimensions returns the time of the request as intent vector dimensions.
+func (e *defaultEngine) timeDimensions() map[string]float64 {
+	now := e.clock()
+	_, offset := now.Zone()
+	return map[string]float64{
+		strategy.DimHour:           float64(now.Hour()),
+		strategy.DimWeekday:        float64(now.Weekday()),
+		strategy.DimUTCOffsetHours: float64(offset) / 3600,
+	}
+}
+
+// scheduledStrategy applies the first active schedule to remoteCfg and returns the
+// result with the schedule's name. Remote schedules are checked before local ones.
+// Without an active schedule remoteCfg is returned unchanged with an empty name.
+func (e *defaultEngine) scheduledStrategy(remoteCfg *config.RemoteStrategy) (*config.RemoteStrategy, string) {
+	if remoteCfg == nil && len(e.schedules) == 0 {
+		return remoteCfg, ""
+	}
+	now := e.now()
+
+	if remoteCfg != nil {
+		for i, s := range remoteCfg.Schedules {
+			w, err := s.TimeWindow.Parse(e.loc)
+			if err != nil {
+				continue // reported when the remote strategy was fetched
+			}
+			if w.Contains(now) {
+				name := s.Name
+				if name == "" {
+					name = fmt.Sprintf("remote-schedule-%d", i)
+				}
+				return remoteCfg.Overlay(s.ModelStrategy), name
+			}
+		}
+	}
+	for _, s := range e.schedules {
+		if s.window.Contains(now) {
+			return remoteCfg.Overlay(s.cfg.ModelStrategy), s.cfg.Name
+		}
+	}
+	return remoteCfg, ""
+}
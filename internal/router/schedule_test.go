@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/strategy"
+)
+
+func scheduleTestEngine(t *testing.T, cfg *config.Config, now time.Time) *defaultEngine {
+	t.Helper()
+	e := aliasTestEngine(t, cfg, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+		"deepseek":   &MockProvider{name: "deepseek"},
+	}).(*defaultEngine)
+	e.now = func() time.Time { return now }
+	return e
+}
+
+func TestSchedule_RemoteSwitchesStrategy(t *testing.T) {
+	rcfg := &config.RemoteStrategy{
+		Strategy:   "local",
+		LocalModel: "qwen",
+		Schedules: []config.Schedule{{
+			Name:          "training",
+			TimeWindow:    config.TimeWindow{Days: []string{"mon-fri"}, Start: "09:00", End: "17:00"},
+			ModelStrategy: config.ModelStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"},
+		}},
+	}
+
+	// Wednesday 10:00 UTC: the local GPU is reserved.
+	engine := scheduleTestEngine(t, &config.Config{}, time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC))
+	trace := &Trace{}
+	p, model, err := engine.SelectProvider(WithTrace(context.Background(), trace), &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if err != nil || p.Name() != "google" || model != "gemini" {
+		t.Errorf("expected google/gemini during the schedule, got %v/%s (err %v)", p, model, err)
+	}
+	if trace.Schedule != "training" {
+		t.Errorf("expected schedule training in the trace, got %q", trace.Schedule)
+	}
+
+	// Saturday: the base strategy applies.
+	engine.now = func() time.Time { return time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC) }
+	p, model, _ = engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if p.Name() != "local_vllm" || model != "qwen" {
+		t.Errorf("expected local_vllm/qwen outside the schedule, got %s/%s", p.Name(), model)
+	}
+}
+
+func TestSchedule_RemoteBeforeLocal(t *testing.T) {
+	engine := scheduleTestEngine(t, &config.Config{
+		Timezone: "Asia/Shanghai",
+		Schedules: []config.Schedule{{
+			Name:          "off-peak",
+			TimeWindow:    config.TimeWindow{Start: "22:00", End: "06:00"},
+			ModelStrategy: config.ModelStrategy{Strategy: "remote", RemoteProvider: "deepseek", RemoteModel: "deepseek-chat"},
+		}},
+	}, time.Date(2026, 10, 14, 15, 0, 0, 0, time.UTC)) // 23:00 in Shanghai
+
+	rcfg := &config.RemoteStrategy{Strategy: "local"}
+	p, model, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if p.Name() != "deepseek" || model != "deepseek-chat" {
+		t.Errorf("expected the local off-peak schedule, got %s/%s", p.Name(), model)
+	}
+
+	rcfg.Schedules = []config.Schedule{{
+		TimeWindow:    config.TimeWindow{Start: "20:00", End: "23:30"},
+		ModelStrategy: config.ModelStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"},
+	}}
+	trace := &Trace{}
+	p, _, _ = engine.SelectProvider(WithTrace(context.Background(), trace), &models.ChatCompletionRequest{Model: "m"}, rcfg)
+	if p.Name() != "google" || trace.Schedule != "remote-schedule-0" {
+		t.Errorf("expected the remote schedule to win, got %s (%q)", p.Name(), trace.Schedule)
+	}
+}
+
+func TestSchedule_TimeVariables(t *testing.T) {
+	engine := scheduleTestEngine(t, &config.Config{
+		Timezone:       "Europe/Berlin",
+		RemoteStrategy: config.RemoteStrategyConfig{Expression: `Hour >= 22 && Weekday == "wed" && Timezone == "Europe/Berlin" ? "local_vllm" : "google"`},
+	}, time.Date(2026, 10, 14, 20, 30, 0, 0, time.UTC)) // Wed 22:30 in Berlin
+
+	p, _, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, &config.RemoteStrategy{Strategy: "remote"})
+	if p.Name() != "local_vllm" {
+		t.Errorf("expected the expression to see the Berlin time, got %s", p.Name())
+	}
+
+	dims := engine.timeDimensions()
+	if dims[strategy.DimHour] != 22 || dims[strategy.DimWeekday] != 3 || dims[strategy.DimUTCOffsetHours] != 2 {
+		t.Errorf("unexpected time dimensions %v", dims)
+	}
+}
+
+func TestSchedule_RouteExpressionSeesTime(t *testing.T) {
+	engine := scheduleTestEngine(t, &config.Config{Routes: []config.RouteConfig{{
+		Name:   "night",
+		Match:  config.RouteMatch{Expression: "hour >= 20 || hour < 6"},
+		Target: config.RouteTarget{Provider: "deepseek"},
+	}}}, time.Date(2026, 10, 14, 21, 0, 0, 0, time.UTC))
+
+	p, _, _ := engine.SelectProvider(context.Background(), &models.ChatCompletionRequest{Model: "m"}, &config.RemoteStrategy{Strategy: "local"})
+	if p.Name() != "deepseek" {
+		t.Errorf("expected the night route, got %s", p.Name())
+	}
+}
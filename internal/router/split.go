@@ -0,0 +1,91 @@
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// pickSplit returns the index of the bucket that point, in [0, 1), falls into.
+// Buckets with a non-positive weight never match. It returns -1 when no bucket has weight.
+func pickSplit(splits []config.TrafficSplit, point float64) int {
+	total := 0.0
+	for _, s := range splits {
+		if s.Weight > 0 {
+			total += s.Weight
+		}
+	}
+	if total <= 0 {
+		return -1
+	}
+
+	target := point * total
+	last := -1
+	for i, s := range splits {
+		if s.Weight <= 0 {
+			continue
+		}
+		if target < s.Weight {
+			return i
+		}
+		target -= s.Weight
+		last = i
+	}
+	return last // rounding left target just past the final bucket
+}
+
+// splitPoint maps a conversation onto [0, 1). Requests that cannot be attributed to a
+// conversation are spread randomly.
+func splitPoint(id string) float64 {
+	if id == "" {
+		return rand.Float64()
+	}
+	sum := sha256.Sum256([]byte(id))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+// selectSplit routes the request through the weighted splits of the remote strategy.
+// ok is false when the strategy defines no usable split.
+func (e *defaultEngine) selectSplit(st *routeState) (p providers.Provider, model string, ok bool) {
+	remoteCfg := st.remoteCfg
+	if remoteCfg == nil || len(remoteCfg.Splits) == 0 {
+		return nil, "", false
+	}
+
+	point := splitPoint(conversationID(st, sessionHeader()))
+	i := pickSplit(remoteCfg.Splits, point)
+	if i < 0 {
+		logger.Warnf("[Router] Traffic split has no bucket with positive weight, ignoring splits")
+		return nil, "", false
+	}
+	split := remoteCfg.Splits[i]
+
+	p, exists := e.providerMap[split.Provider]
+	if !exists {
+		logger.Warnf("[Router] Traffic split bucket %d references unknown provider %s, ignoring splits", i, split.Provider)
+		return nil, "", false
+	}
+
+	model = split.Model
+	if model == "" {
+		if split.Provider == "local_vllm" {
+			model = remoteCfg.LocalModel
+		} else {
+			model = remoteCfg.RemoteModel
+		}
+	}
+
+	name := split.Name
+	if name == "" {
+		name = split.Provider + "/" + model
+	}
+	if st.trace != nil {
+		st.trace.Split = &SplitTrace{Bucket: i, Name: name, Point: point}
+	}
+	logger.Infof("[Router] Traffic split selected bucket %d (%s) at point %.4f: %s/%s", i, name, point, split.Provider, model)
+	return p, model, true
+}
@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+func TestPickSplit(t *testing.T) {
+	splits := []config.TrafficSplit{
+		{Provider: "a", Weight: 10},
+		{Provider: "skipped", Weight: 0},
+		{Provider: "b", Weight: 30},
+	}
+	cases := []struct {
+		point float64
+		want  int
+	}{
+		{0, 0},
+		{0.249, 0},
+		{0.25, 2},
+		{0.999999, 2},
+		{1, 2}, // rounding past the end lands in the last bucket
+	}
+	for _, c := range cases {
+		if got := pickSplit(splits, c.point); got != c.want {
+			t.Errorf("pickSplit(%v) = %d, want %d", c.point, got, c.want)
+		}
+	}
+
+	if got := pickSplit([]config.TrafficSplit{{Provider: "a"}}, 0.5); got != -1 {
+		t.Errorf("expected -1 without positive weights, got %d", got)
+	}
+}
+
+func TestSplitPoint_Deterministic(t *testing.T) {
+	if splitPoint("user:alice") != splitPoint("user:alice") {
+		t.Error("expected the same conversation to map to the same point")
+	}
+	for _, id := range []string{"user:alice", "user:bob", "session:1", ""} {
+		if p := splitPoint(id); p < 0 || p >= 1 {
+			t.Errorf("splitPoint(%q) = %v out of [0, 1)", id, p)
+		}
+	}
+}
+
+func TestSelectProvider_Splits(t *testing.T) {
+	engine := newTestEngine(t, map[string]providers.Provider{
+		"local_vllm": &MockProvider{name: "local_vllm"},
+		"google":     &MockProvider{name: "google"},
+	})
+	rcfg := &config.RemoteStrategy{
+		LocalModel:  "qwen",
+		RemoteModel: "gemini",
+		Splits: []config.TrafficSplit{
+			{Name: "canary", Provider: "google", Model: "gemini-next", Weight: 10},
+			{Provider: "local_vllm", Weight: 90},
+		},
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		req := &models.ChatCompletionRequest{User: fmt.Sprintf("user-%d", i)}
+		p, model, err := engine.SelectProvider(context.Background(), req, rcfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[p.Name()+"/"+model]++
+
+		// The same user always lands in the same bucket.
+		p2, model2, _ := engine.SelectProvider(context.Background(), req, rcfg)
+		if p2.Name() != p.Name() || model2 != model {
+			t.Fatalf("user %s changed bucket: %s/%s then %s/%s", req.User, p.Name(), model, p2.Name(), model2)
+		}
+	}
+	if counts["google/gemini-next"] < 100 || counts["google/gemini-next"] > 320 {
+		t.Errorf("expected roughly 10%% canary traffic, got %v", counts)
+	}
+	if counts["google/gemini-next"]+counts["local_vllm/qwen"] != 2000 {
+		t.Errorf("unexpected targets: %v", counts)
+	}
+}
+
+func TestSelectProvider_SplitsUnknownProvider(t *testing.T) {
+	engine := newTestEngine(t, map[string]providers.Provider{"google": &MockProvider{name: "google"}})
+	req := &models.ChatCompletionRequest{User: "alice"}
+
+	// With a strategy to fall back on, the unusable split is ignored.
+	rcfg := &config.RemoteStrategy{Strategy: "remote", RemoteModel: "gemini", Splits: []config.TrafficSplit{{Provider: "missing", Weight: 1}}}
+	p, _, err := engine.SelectProvider(context.Background(), req, rcfg)
+	if err != nil || p.Name() != "google" {
+		t.Errorf("expected fallback to the remote strategy, got %v (err %v)", p, err)
+	}
+
+	// Without one, routing fails.
+	rcfg.Strategy = ""
+	if _, _, err := engine.SelectProvider(context.Background(), req, rcfg); err == nil {
+		t.Error("expected an error without a usable split or strategy")
+	}
+}
@@ -0,0 +1,123 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/evaluator"
+)
+
+// stagedTestEngine runs a builtin length check in stage 0 and a counting "complexity"
+// evaluator, standing in for an expensive LLM call, in stage 1.
+func stagedTestEngine(t *testing.T) (*defaultEngine, *countingEvaluator) {
+	t.Helper()
+	e := affinityTestEngine(t, &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators: []config.EvaluatorConfig{
+				{Name: "length_check", Type: "builtin", Threshold: 20},
+				{Name: "complexity", Type: "builtin", Stage: 1},
+			},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:            "dynamic_expression",
+				DefaultProvider: "local_vllm",
+				Rules: []config.ResolutionRuleConfig{
+					{Condition: "length_check >= 1", TargetProvider: "google"},
+					{Condition: "complexity > 0.5", TargetProvider: "google"},
+				},
+			},
+		},
+	}).(*defaultEngine)
+	ev := &countingEvaluator{}
+	e.evaluators[1] = ev
+	return e, ev
+}
+
+func TestStaged_EarlyDecisionSkipsLaterStages(t *testing.T) {
+	e, ev := stagedTestEngine(t)
+	trace := &Trace{}
+	p, _, err := e.SelectProvider(WithTrace(context.Background(), trace), conversation(strings.Repeat("hard ", 10)), &config.RemoteStrategy{Strategy: "local"})
+	if err != nil || p.Name() != "google" {
+		t.Fatalf("expected google, got %v, %v", p, err)
+	}
+	if ev.runs != 0 {
+		t.Errorf("expected the complexity stage to be skipped, got %d runs", ev.runs)
+	}
+	if len(trace.Evaluators) != 2 || !trace.Evaluators[1].Skipped || trace.Evaluators[1].Score != nil {
+		t.Errorf("expected complexity to be traced as skipped, got %+v", trace.Evaluators)
+	}
+
+	e, ev = stagedTestEngine(t)
+	p, _, err = e.SelectProvider(context.Background(), conversation("hi"), &config.RemoteStrategy{Strategy: "local"})
+	if err != nil || p.Name() != "google" || ev.runs != 1 {
+		t.Errorf("expected the complexity stage to decide, got %v after %d runs, %v", p, ev.runs, err)
+	}
+}
+
+func TestStaged_FullVectorCompletesSkippedEvaluators(t *testing.T) {
+	e, ev := stagedTestEngine(t)
+	req := conversation(strings.Repeat("hard ", 10))
+	st := &routeState{ctx: context.Background(), req: req, promptTokens: e.counter.CountMessages(req.Model, req.Messages)}
+	if _, _, ok := e.selectGenerative(st); !ok || ev.runs != 0 {
+		t.Fatalf("expected an early generative decision, got ok=%v after %d runs", ok, ev.runs)
+	}
+	if _, ok := st.vector["complexity"]; ok {
+		t.Error("expected the partial vector to lack complexity")
+	}
+	if v := e.intentVector(st); v["complexity"] != 0.9 || v["length_check"] != 1 || ev.runs != 1 {
+		t.Errorf("expected the full vector after one more run, got %v after %d runs", v, ev.runs)
+	}
+	e.intentVector(st)
+	if ev.runs != 1 {
+		t.Errorf("expected the completed vector to be reused, got %d runs", ev.runs)
+	}
+}
+
+func TestEvaluatorStages_Order(t *testing.T) {
+	e := &defaultEngine{
+		evaluators: []evaluator.Evaluator{&countingEvaluator{}, &namedEvaluator{name: "a"}, &namedEvaluator{name: "b"}},
+		stages:     map[string]int{"complexity": 2, "b": -1},
+	}
+	stages := e.evaluatorStages()
+	if len(stages) != 3 || stages[0][0].Name() != "b" || stages[1][0].Name() != "a" || stages[2][0].Name() != "complexity" {
+		t.Errorf("unexpected stage order %v", stages)
+	}
+}
+
+type namedEvaluator struct {
+	countingEvaluator
+	name string
+}
+
+func (n *namedEvaluator) Name() string { return n.name }
+
+func TestEvaluationCache(t *testing.T) {
+	e := affinityTestEngine(t, &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled: true,
+			Cache:   config.EvaluationCacheConfig{Enabled: true},
+			Evaluators: []config.EvaluatorConfig{
+				{Name: "length_check", Type: "builtin", Threshold: 20},
+				{Name: "live", Type: "builtin", NoCache: true},
+			},
+			Resolution: config.ResolutionStrategyConfig{Type: "dynamic_expression", DefaultProvider: "local_vllm"},
+		},
+	})
+
+	var trace *Trace
+	for i := 0; i < 2; i++ {
+		trace = &Trace{}
+		if _, _, err := e.SelectProvider(WithTrace(context.Background(), trace), conversation("hi"), &config.RemoteStrategy{Strategy: "local"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(trace.Evaluators) != 2 || !trace.Evaluators[0].Cached || trace.Evaluators[1].Cached {
+		t.Errorf("expected only the cached evaluator to hit, got %+v", trace.Evaluators)
+	}
+	if c := trace.EvaluatorCache; c == nil || c.Hits != 1 || c.Misses != 1 {
+		t.Errorf("unexpected cache stats %+v", c)
+	}
+}
@@ -0,0 +1,135 @@
+package router
+
+import (
+	"context"
+	"slices"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/evaluator"
+)
+
+// Trace records how SelectProvider reached its decision. Routing with a Trace attached
+// via WithTrace is a dry run: the decision is explained but not pinned for the session.
+type Trace struct {
+	RemoteStrategy *config.RemoteStrategy `json:"remote_strategy"`          // effective for the requested model
+	ModelStrategy  string                 `json:"model_strategy,omitempty"` // matching model_strategies key
+	Schedule       string                 `json:"schedule,omitempty"`       // active schedule block
+	PromptTokens   int                    `json:"prompt_tokens"`
+	Affinity       *AffinityTrace         `json:"affinity,omitempty"`
+	Evaluators     []EvaluatorTrace       `json:"evaluators,omitempty"`
+	IntentVector   map[string]float64     `json:"intent_vector,omitempty"`
+	EvaluatorCache *EvaluatorCacheTrace   `json:"evaluator_cache,omitempty"`
+	Resolution     *ResolutionTrace       `json:"resolution,omitempty"`
+	Expression     *ExpressionTrace       `json:"expression,omitempty"`
+	Split          *SplitTrace            `json:"split,omitempty"`
+	ContextGuard   *ContextGuardTrace     `json:"context_guard,omitempty"`
+
+	// Route is the route table entry that chose the target, if any.
+	Route string `json:"route,omitempty"`
+	// Stage names the step that chose the target: "hint", "alias", "affinity", "route",
+	// "generative", "expression", "split", "strategy" or "default".
+	Stage    string `json:"stage,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// AffinityTrace reports the session affinity lookup.
+type AffinityTrace struct {
+	Key        string `json:"key"`
+	Pinned     bool   `json:"pinned"`               // the pinned choice was reused
+	Escalation string `json:"escalation,omitempty"` // rule that overrode the pinned choice
+}
+
+// EvaluatorTrace reports a single evaluator run.
+type EvaluatorTrace struct {
+	Name       string             `json:"name"`
+	Score      *float64           `json:"score,omitempty"`      // nil when the evaluator failed
+	Dimensions map[string]float64 `json:"dimensions,omitempty"` // set instead of score by multi-dimension evaluators
+	LatencyMs  float64            `json:"latency_ms"`
+	Error      string             `json:"error,omitempty"`
+	Skipped    bool               `json:"skipped,omitempty"` // an earlier evaluator stage decided the route
+	Cached     bool               `json:"cached,omitempty"`  // served from the evaluation cache
+}
+
+// EvaluatorCacheTrace reports the counters of the evaluation cache since startup.
+type EvaluatorCacheTrace struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+}
+
+// ResolutionTrace reports the generative routing resolver decision.
+type ResolutionTrace struct {
+	Resolver string `json:"resolver,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+}
+
+// ExpressionTrace reports the local remote_strategy.expression override.
+type ExpressionTrace struct {
+	Expression string `json:"expression"`
+	Result     any    `json:"result,omitempty"`
+	Fired      bool   `json:"fired"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SplitTrace reports the traffic split bucket.
+type SplitTrace struct {
+	Bucket int     `json:"bucket"`
+	Name   string  `json:"name"`
+	Point  float64 `json:"point"`
+}
+
+// ContextGuardTrace reports a reroute by the context window guard.
+type ContextGuardTrace struct {
+	Provider string `json:"provider"` // target before the reroute
+	Model    string `json:"model"`
+}
+
+type traceKey struct{}
+
+// WithTrace returns a copy of ctx that makes SelectProvider record its decision into t.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+func traceFrom(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// decided records the stage that chose the target. It is safe on a nil *Trace.
+func (t *Trace) decided(stage string) {
+	if t != nil {
+		t.Stage = stage
+	}
+}
+
+func (t *Trace) recordEvaluators(outcomes []evaluator.Outcome) {
+	if t == nil {
+		return
+	}
+	for _, o := range outcomes {
+		et := EvaluatorTrace{Name: o.Name, LatencyMs: float64(o.Latency.Microseconds()) / 1000}
+		switch {
+		case o.Skipped:
+			et.Skipped = true
+		case o.Err != nil:
+			et.Error = o.Err.Error()
+		case o.Dimensions != nil:
+			et.Dimensions, et.Cached = o.Dimensions, o.Cached
+		default:
+			score := o.Score
+			et.Score, et.Cached = &score, o.Cached
+		}
+		// A skipped evaluator run later replaces its earlier entry
+		if i := slices.IndexFunc(t.Evaluators, func(prev EvaluatorTrace) bool { return prev.Name == o.Name }); i >= 0 {
+			t.Evaluators[i] = et
+			continue
+		}
+		t.Evaluators = append(t.Evaluators, et)
+	}
+}
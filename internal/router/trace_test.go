@@ -0,0 +1,97 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+func TestTrace_Generative(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:         true,
+			GlobalTimeoutMs: 500,
+			Evaluators:      []config.EvaluatorConfig{{Name: "length_check", Type: "builtin", Threshold: 20}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type:            "dynamic_expression",
+				DefaultProvider: "local_vllm",
+				Rules:           []config.ResolutionRuleConfig{{Condition: "length_check >= 1", TargetProvider: "google"}},
+			},
+		},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen", RemoteModel: "gemini"}
+
+	trace := &Trace{}
+	req := &models.ChatCompletionRequest{Messages: []models.Message{{Role: "user", Content: strings.Repeat("hard ", 10)}}}
+	if _, _, err := engine.SelectProvider(WithTrace(context.Background(), trace), req, rcfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if trace.RemoteStrategy != rcfg || trace.PromptTokens == 0 {
+		t.Errorf("expected the strategy snapshot and prompt size, got %+v", trace)
+	}
+	if len(trace.Evaluators) != 1 || trace.Evaluators[0].Name != "length_check" || trace.Evaluators[0].Score == nil || *trace.Evaluators[0].Score != 1 {
+		t.Errorf("unexpected evaluator trace: %+v", trace.Evaluators)
+	}
+	if trace.IntentVector["length_check"] != 1 {
+		t.Errorf("unexpected intent vector: %v", trace.IntentVector)
+	}
+	if trace.Resolution == nil || trace.Resolution.Resolver != "dynamic_expression" || trace.Resolution.Rule != "length_check >= 1" {
+		t.Errorf("unexpected resolution trace: %+v", trace.Resolution)
+	}
+	if trace.Stage != "generative" || trace.Provider != "google" || trace.Model != "gemini" {
+		t.Errorf("expected generative decision for google/gemini, got %s %s/%s", trace.Stage, trace.Provider, trace.Model)
+	}
+}
+
+func TestTrace_Expression(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{
+		RemoteStrategy: config.RemoteStrategyConfig{Expression: `EstimatedTokens > 1000 ? "google" : "none"`},
+	})
+	rcfg := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+
+	trace := &Trace{}
+	engine.SelectProvider(WithTrace(context.Background(), trace), conversation("hi"), rcfg)
+	if trace.Expression == nil || trace.Expression.Fired || trace.Expression.Result != "none" {
+		t.Errorf("expected an expression that did not fire, got %+v", trace.Expression)
+	}
+	if trace.Stage != "strategy" || trace.Provider != "local_vllm" || trace.Model != "qwen" {
+		t.Errorf("expected the local strategy to decide, got %s %s/%s", trace.Stage, trace.Provider, trace.Model)
+	}
+}
+
+func TestTrace_Error(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{})
+
+	trace := &Trace{}
+	_, _, err := engine.SelectProvider(WithTrace(context.Background(), trace), conversation("hi"), &config.RemoteStrategy{Strategy: "bogus"})
+	if err == nil || trace.Error != err.Error() || trace.Provider != "" {
+		t.Errorf("expected the routing error in the trace, got %+v", trace)
+	}
+}
+
+func TestTrace_DryRunDoesNotPin(t *testing.T) {
+	engine := affinityTestEngine(t, &config.Config{SessionAffinity: config.SessionAffinityConfig{Enabled: true}})
+	local := &config.RemoteStrategy{Strategy: "local", LocalModel: "qwen"}
+	remote := &config.RemoteStrategy{Strategy: "remote", RemoteProvider: "google", RemoteModel: "gemini"}
+
+	trace := &Trace{}
+	engine.SelectProvider(WithTrace(context.Background(), trace), conversation("hi"), local)
+	if trace.Affinity == nil || trace.Affinity.Pinned || trace.Affinity.Key == "" {
+		t.Errorf("expected an unpinned affinity lookup, got %+v", trace.Affinity)
+	}
+
+	p, _, _ := engine.SelectProvider(context.Background(), conversation("hi", "and then?"), remote)
+	if p.Name() != "google" {
+		t.Errorf("expected the dry run not to pin the session, got %s", p.Name())
+	}
+
+	trace = &Trace{}
+	engine.SelectProvider(WithTrace(context.Background(), trace), conversation("hi", "more"), local)
+	if trace.Stage != "affinity" || !trace.Affinity.Pinned || trace.Provider != "google" {
+		t.Errorf("expected the pinned choice to be explained, got %+v", trace)
+	}
+}
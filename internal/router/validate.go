@@ -0,0 +1,118 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/shadow"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/evaluator"
+	"agentic-llm-gateway/pkg/strategy"
+)
+
+// clockDimensions are the time of the request, available to every routing expression.
+var clockDimensions = []string{
+	strategy.DimHour,
+	strategy.DimWeekday,
+	strategy.DimUTCOffsetHours,
+}
+
+// routerDimensions are the intent vector dimensions added by the router itself.
+var routerDimensions = append([]string{
+	strategy.DimEstimatedTokens,
+	strategy.DimExpectedOutputTokens,
+	strategy.DimNeedsVision,
+}, clockDimensions...)
+
+// vectorDimensions returns the dimensions of the intent vector, none with generative
+// routing disabled.
+func vectorDimensions(cfg *config.Config) []string {
+	if cfg == nil || cfg.GenerativeRouting == nil || !cfg.GenerativeRouting.Enabled {
+		return nil
+	}
+	dims := slices.Clone(routerDimensions)
+	for _, ev := range cfg.GenerativeRouting.Evaluators {
+		dims = append(dims, evaluator.Dimensions(ev)...)
+	}
+	return dims
+}
+
+// routeDimensions returns the variables a route expression can read: the time of the
+// request and, with generative routing enabled, the intent vector.
+func routeDimensions(cfg *config.Config) []string {
+	if dims := vectorDimensions(cfg); dims != nil {
+		return dims
+	}
+	return clockDimensions
+}
+
+// ValidateConfig checks the routing configuration of cfg against the names of the
+// configured providers: the remote_strategy expression, the route table, the timezone,
+// the schedules, the session escalation rules and the shadow filter must compile and,
+// with generative routing enabled, the resolution strategy must be valid and pass its
+// tests. Cascade verifiers must be complete, with compiling patterns, and evaluators,
+// including cascade judges, must use a registered evaluator type.
+func ValidateConfig(cfg *config.Config, providerNames []string) error {
+	var errs []error
+	if src := cfg.RemoteStrategy.Expression; src != "" {
+		if _, err := compileExpression(src); err != nil {
+			errs = append(errs, fmt.Errorf("remote_strategy.expression: %w", err))
+		}
+	}
+	loc, err := configLocation(cfg)
+	if err != nil {
+		errs = append(errs, err)
+		loc = time.UTC
+	}
+	if _, err := compileRoutes(cfg.Routes, loc, routeDimensions(cfg)); err != nil {
+		errs = append(errs, fmt.Errorf("routes: %w", err))
+	}
+	if _, err := compileSchedules(cfg.Schedules, loc); err != nil {
+		errs = append(errs, fmt.Errorf("schedules: %w", err))
+	}
+	if cfg.SessionAffinity.Enabled {
+		if _, err := compileEscalations(cfg.SessionAffinity.Escalation, vectorDimensions(cfg)); err != nil {
+			errs = append(errs, fmt.Errorf("session_affinity: %w", err))
+		}
+	}
+	if err := shadow.Validate(cfg.Shadow, providerNames); err != nil {
+		errs = append(errs, err)
+	}
+	for i, vCfg := range cfg.Cascade.Verifiers {
+		if err := checkVerifier(vCfg); err != nil {
+			errs = append(errs, fmt.Errorf("cascade.verifiers[%d]: %w", i, err))
+		}
+	}
+
+	gen := cfg.GenerativeRouting
+	if gen == nil || !gen.Enabled {
+		return errors.Join(errs...)
+	}
+	for _, ev := range gen.Evaluators {
+		if !evaluator.Registered(ev.Type) {
+			errs = append(errs, fmt.Errorf("generative_routing.evaluators: %s has unknown type %q", ev.Name, ev.Type))
+		}
+	}
+	dims := vectorDimensions(cfg)
+	if err := strategy.Validate(gen.Resolution, dims, providerNames); err != nil {
+		errs = append(errs, fmt.Errorf("generative_routing.resolution_strategy: %w", err))
+	} else if len(gen.Resolution.Tests) > 0 {
+		cat := catalog.New(cfg.ModelCatalog).Restrict(func(provider string) bool {
+			return slices.Contains(providerNames, provider)
+		})
+		resolver, err := strategy.NewResolver(gen.Resolution, cat)
+		if err == nil {
+			err = strategy.RunTests(resolver, gen.Resolution.Tests)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("generative_routing.resolution_strategy.tests: %w", err))
+		}
+	}
+	if gen.FallbackProvider != "" && !slices.Contains(providerNames, gen.FallbackProvider) {
+		errs = append(errs, fmt.Errorf("generative_routing.fallback_provider references unconfigured provider %q", gen.FallbackProvider))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	providers := []string{"local_vllm", "google"}
+	cfg := &config.Config{
+		RemoteStrategy: config.RemoteStrategyConfig{Expression: `has_code() ? "google" : "local_vllm"`},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:    true,
+			Evaluators: []config.EvaluatorConfig{{Name: "complexity", Type: "builtin"}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type: "dynamic_expression",
+				Rules: []config.ResolutionRuleConfig{
+					{Condition: "complexity > 0.7 || estimated_tokens > 8000", TargetProvider: "google"},
+					{Condition: "hour >= 22", TargetProvider: "local_vllm"},
+				},
+				DefaultProvider: "local_vllm",
+				Tests: []config.ResolutionTestConfig{
+					{Vector: map[string]float64{"complexity": 0.9}, Expect: "google"},
+					{Vector: map[string]float64{"complexity": 0.1, "estimated_tokens": 9000}, Expect: "google"},
+				},
+			},
+		},
+	}
+	if err := ValidateConfig(cfg, providers); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+
+	cfg.RemoteStrategy.Expression = `Req.Nope`
+	cfg.GenerativeRouting.Resolution.Tests[0].Expect = "local_vllm"
+	err := ValidateConfig(cfg, providers)
+	if err == nil || !strings.Contains(err.Error(), "remote_strategy.expression") || !strings.Contains(err.Error(), "tests") {
+		t.Errorf("expected the expression and the test to be reported, got %v", err)
+	}
+
+	cfg.RemoteStrategy.Expression = ""
+	cfg.GenerativeRouting.Evaluators = nil
+	if err := ValidateConfig(cfg, providers); err == nil || !strings.Contains(err.Error(), "complexity") {
+		t.Errorf("expected a rule on a dimension without evaluator to be rejected, got %v", err)
+	}
+
+	cfg.GenerativeRouting.Enabled = false
+	if err := ValidateConfig(cfg, providers); err != nil {
+		t.Errorf("expected disabled generative routing to be skipped, got %v", err)
+	}
+}
+
+func TestValidateConfig_UnknownEvaluatorType(t *testing.T) {
+	cfg := &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:    true,
+			Evaluators: []config.EvaluatorConfig{{Name: "complexity", Type: "llm_magic"}},
+			Resolution: config.ResolutionStrategyConfig{Type: "dynamic_expression", DefaultProvider: "local_vllm"},
+		},
+		Cascade: config.CascadeConfig{Verifiers: []config.VerifierConfig{
+			{Type: "judge", Judge: &config.EvaluatorConfig{Name: "quality", Type: "oracle"}},
+		}},
+	}
+	err := ValidateConfig(cfg, []string{"local_vllm"})
+	if err == nil || !strings.Contains(err.Error(), `"llm_magic"`) || !strings.Contains(err.Error(), `"oracle"`) {
+		t.Errorf("expected both unknown evaluator types to be reported, got %v", err)
+	}
+}
+
+func TestValidateConfig_SchemaDimensions(t *testing.T) {
+	cfg := &config.Config{
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled: true,
+			Evaluators: []config.EvaluatorConfig{{Name: "intent", Type: "llm_structured_api", Schema: []config.SchemaFieldConfig{
+				{Name: "complexity", Type: "number"},
+				{Name: "domain", Type: "enum", Values: []string{"code", "chat"}},
+			}}},
+			Resolution: config.ResolutionStrategyConfig{
+				Type: "dynamic_expression",
+				Rules: []config.ResolutionRuleConfig{
+					{Condition: "complexity > 0.7 || domain_code == 1", TargetProvider: "google"},
+				},
+				DefaultProvider: "local_vllm",
+			},
+		},
+	}
+	if err := ValidateConfig(cfg, []string{"local_vllm", "google"}); err != nil {
+		t.Fatalf("expected schema fields to be dimensions, got %v", err)
+	}
+
+	cfg.GenerativeRouting.Resolution.Rules[0].Condition = "intent > 0.5"
+	if err := ValidateConfig(cfg, []string{"local_vllm", "google"}); err == nil || !strings.Contains(err.Error(), "intent") {
+		t.Errorf("expected the evaluator name not to be a dimension, got %v", err)
+	}
+}
+
+func TestValidateConfig_Routes(t *testing.T) {
+	cfg := &config.Config{
+		Routes: []config.RouteConfig{
+			{Name: "night", Match: config.RouteMatch{Expression: "hour >= 22"}, Target: config.RouteTarget{Provider: "local_vllm"}},
+			{Name: "hard", Match: config.RouteMatch{Expression: "complexity > 0.7"}, Target: config.RouteTarget{Provider: "google"}},
+		},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:    true,
+			Evaluators: []config.EvaluatorConfig{{Name: "complexity", Type: "builtin"}},
+			Resolution: config.ResolutionStrategyConfig{Type: "dynamic_expression", DefaultProvider: "local_vllm"},
+		},
+	}
+	if err := ValidateConfig(cfg, []string{"local_vllm", "google"}); err != nil {
+		t.Fatalf("expected valid routes, got %v", err)
+	}
+
+	// without generative routing the intent vector is not computed
+	cfg.GenerativeRouting.Enabled = false
+	err := ValidateConfig(cfg, []string{"local_vllm", "google"})
+	if err == nil || !strings.Contains(err.Error(), "route hard") || strings.Contains(err.Error(), "route night") {
+		t.Errorf("expected only the route reading the vector to be reported, got %v", err)
+	}
+}
+
+func TestValidateConfig_Schedules(t *testing.T) {
+	cfg := &config.Config{
+		Timezone: "Asia/Shanghai",
+		Schedules: []config.Schedule{
+			{Name: "off-peak", TimeWindow: config.TimeWindow{Start: "22:00", End: "06:00"}},
+			{Name: "gpu-training", TimeWindow: config.TimeWindow{Days: []string{"mon-fri"}, Start: "09:00", End: "18:00"}},
+		},
+	}
+	if err := ValidateConfig(cfg, nil); err != nil {
+		t.Fatalf("expected valid schedules, got %v", err)
+	}
+
+	cfg.Timezone = "Mars/Olympus"
+	cfg.Schedules[1].Days = []string{"mon-fry"}
+	err := ValidateConfig(cfg, nil)
+	if err == nil || !strings.Contains(err.Error(), "timezone") || !strings.Contains(err.Error(), "schedule gpu-training") {
+		t.Errorf("expected the timezone and the schedule to be reported, got %v", err)
+	}
+
+	config.GlobalConfig = &config.Config{Schedules: cfg.Schedules}
+	defer func() { config.GlobalConfig = nil }()
+	if _, err := NewEngine(nil); err == nil {
+		t.Error("expected NewEngine to reject the invalid schedule")
+	}
+}
+
+func TestValidateConfig_SessionEscalation(t *testing.T) {
+	cfg := &config.Config{
+		SessionAffinity: config.SessionAffinityConfig{Enabled: true, Escalation: []string{"complexity > prev.complexity + 0.3"}},
+		GenerativeRouting: &config.GenerativeRoutingConfig{
+			Enabled:    true,
+			Evaluators: []config.EvaluatorConfig{{Name: "complexity", Type: "builtin"}},
+			Resolution: config.ResolutionStrategyConfig{Type: "dynamic_expression", DefaultProvider: "local_vllm"},
+		},
+	}
+	if err := ValidateConfig(cfg, []string{"local_vllm"}); err != nil {
+		t.Fatalf("expected a valid escalation rule, got %v", err)
+	}
+
+	cfg.SessionAffinity.Escalation = []string{"complexty > prev.complexity"}
+	if err := ValidateConfig(cfg, []string{"local_vllm"}); err == nil || !strings.Contains(err.Error(), "session_affinity") {
+		t.Errorf("expected the escalation rule to be reported, got %v", err)
+	}
+}
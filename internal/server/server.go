@@ -3,12 +3,18 @@ package server
 import (
 	"agentic-llm-gateway/pkg/logger"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"agentic-llm-gateway/internal/config"
 	"agentic-llm-gateway/internal/models"
 	"agentic-llm-gateway/internal/providers"
 	"agentic-llm-gateway/internal/router"
+	"agentic-llm-gateway/internal/shadow"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/tokenizer"
 )
 
 // StrategyManager is the read-only interface the Server needs from RemoteManager.
@@ -20,16 +26,41 @@ type StrategyManager interface {
 
 // Server encapsulates the HTTP handler and routing logic
 type Server struct {
-	rm     StrategyManager
-	engine router.StrategyEngine
+	rm      StrategyManager
+	engine  router.StrategyEngine
+	catalog *catalog.Catalog
+	counter *tokenizer.Counter
+	shadow  *shadow.Mirror
+}
+
+// Option configures optional Server dependencies.
+type Option func(*Server)
+
+// WithCatalog enables logging of the estimated and actual cost of each request.
+func WithCatalog(c *catalog.Catalog) Option {
+	return func(s *Server) { s.catalog = c }
+}
+
+// WithTokenCounter counts prompt tokens with BPE vocabularies for cost estimates.
+func WithTokenCounter(c *tokenizer.Counter) Option {
+	return func(s *Server) { s.counter = c }
+}
+
+// WithShadow mirrors sampled requests to a candidate model for offline comparison.
+func WithShadow(m *shadow.Mirror) Option {
+	return func(s *Server) { s.shadow = m }
 }
 
 // NewServer initialises the HTTP gateway.
-func NewServer(rm StrategyManager, engine router.StrategyEngine) *Server {
-	return &Server{
+func NewServer(rm StrategyManager, engine router.StrategyEngine, opts ...Option) *Server {
+	s := &Server{
 		rm:     rm,
 		engine: engine,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Start starts the standard library net/http server
@@ -37,6 +68,8 @@ func (s *Server) Start(addr string) error {
 	// Go 1.24 enhanced routing
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	mux.HandleFunc("POST /v1/route/explain", s.handleRouteExplain)
+	mux.HandleFunc("GET /v1/models", s.handleListModels)
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
@@ -60,9 +93,22 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 
 	strategy := s.rm.GetStrategy()
 
-	provider, targetModel, err := s.engine.SelectProvider(&req, strategy)
+	ctx := router.WithRequestMeta(r.Context(), &router.RequestMeta{Path: r.URL.Path, Headers: r.Header})
+	provider, targetModel, err := s.engine.SelectProvider(ctx, &req, strategy)
 	if err != nil {
 		logger.Printf("[Server] Routing failed: %v", err)
+		if errors.Is(err, router.ErrContextOverflow) {
+			http.Error(w, "Prompt exceeds the context window of every available model", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, router.ErrOverrideNotAllowed) {
+			http.Error(w, "Routing override not allowed for this API key", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, router.ErrInvalidHint) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Internal Routing Error", http.StatusInternalServerError)
 		return
 	}
@@ -70,28 +116,143 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	// Update the request's mapped model
 	req.Model = targetModel
 	logger.Printf("[Server] Selected Provider: %s. Overriding model to: %s. Stream: %v", provider.Name(), targetModel, req.Stream)
+	s.logEstimatedCost(provider.Name(), &req)
 
+	mirrored := s.shadow.Start(&req, provider.Name())
+	started := time.Now()
+	var primary shadow.Result
 	if req.Stream {
-		s.handleStream(w, r, provider, &req)
+		primary = s.handleStream(w, r, provider, &req)
 	} else {
-		s.handleSync(w, r, provider, &req)
+		primary = s.handleSync(w, r, provider, &req)
+	}
+	primary.LatencyMs = time.Since(started).Milliseconds()
+	mirrored.Finish(primary)
+}
+
+// handleListModels lists the model aliases clients can request, in the OpenAI list format.
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	list := models.ModelList{Object: "list", Data: []models.ModelInfo{}}
+	for _, name := range router.ModelAliasNames(s.rm.GetStrategy()) {
+		list.Data = append(list.Data, models.ModelInfo{ID: name, Object: "model", OwnedBy: "agentic-llm-gateway"})
 	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
 }
 
-func (s *Server) handleSync(w http.ResponseWriter, r *http.Request, provider providers.Provider, req *models.ChatCompletionRequest) {
+// handleRouteExplain runs the routing decision for a chat completion request as a dry
+// run and returns its trace instead of calling the upstream.
+func (s *Server) handleRouteExplain(w http.ResponseWriter, r *http.Request) {
+	var req models.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request JSON", http.StatusBadRequest)
+		return
+	}
+
+	trace := &router.Trace{}
+	ctx := router.WithRequestMeta(r.Context(), &router.RequestMeta{Path: r.URL.Path, Headers: r.Header})
+	ctx = router.WithTrace(ctx, trace)
+	if _, _, err := s.engine.SelectProvider(ctx, &req, s.rm.GetStrategy()); err != nil {
+		logger.Printf("[Server] Routing explain failed: %v", err)
+		if trace.Error == "" {
+			trace.Error = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trace)
+}
+
+// handleSync proxies a non-streaming completion and returns its outcome for shadow comparison.
+func (s *Server) handleSync(w http.ResponseWriter, r *http.Request, provider providers.Provider, req *models.ChatCompletionRequest) shadow.Result {
 	// Need context timeout? Usually upstream manages it or client aborts
 	resp, err := provider.ChatCompletion(r.Context(), req)
 	if err != nil {
 		logger.Printf("[Server] Upstream Error (%s): %v", provider.Name(), err)
 		http.Error(w, "Bad Gateway", http.StatusBadGateway)
-		return
+		return shadow.Result{Provider: provider.Name(), Model: req.Model, Error: err.Error()}
+	}
+
+	// Providers resolve req.Model in place, so it names the model actually billed, and
+	// composite providers report the provider that served it.
+	served := providers.ServedBy(provider)
+	s.logActualCost(served, req.Model, resp.Usage)
+
+	// Clients asking for an alias must see which upstream model answered.
+	if resp.Model == "" {
+		resp.Model = req.Model
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
+
+	result := shadow.Result{Provider: served, Model: req.Model, Usage: &resp.Usage}
+	if len(resp.Choices) > 0 {
+		result.Content = resp.Choices[0].Message.Content
+	}
+	return result
 }
 
-func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, provider providers.Provider, req *models.ChatCompletionRequest) {
+// logEstimatedCost logs the pre-flight cost estimate when the target is in the catalog.
+func (s *Server) logEstimatedCost(providerName string, req *models.ChatCompletionRequest) {
+	entry, ok := s.catalog.Lookup(providerName, req.Model)
+	if !ok {
+		return
+	}
+	promptTokens := s.counter.CountMessages(req.Model, req.Messages)
+	outputTokens := req.MaxTokens
+	if outputTokens <= 0 {
+		outputTokens = catalog.DefaultOutputTokens
+	}
+	logger.Info("[Server] Estimated request cost",
+		"provider", providerName,
+		"model", req.Model,
+		"prompt_tokens", promptTokens,
+		"output_tokens", outputTokens,
+		"estimated_cost_usd", catalog.EstimateCost(entry, promptTokens, 0, outputTokens),
+	)
+}
+
+// logActualCost logs the cost of a completed request from the upstream usage report.
+func (s *Server) logActualCost(providerName, model string, usage models.Usage) {
+	entry, ok := s.catalog.Lookup(providerName, model)
+	if !ok {
+		return
+	}
+	logger.Info("[Server] Actual request cost",
+		"provider", providerName,
+		"model", model,
+		"prompt_tokens", usage.PromptTokens,
+		"cached_tokens", usage.CachedTokens(),
+		"completion_tokens", usage.CompletionTokens,
+		"actual_cost_usd", catalog.EstimateCost(entry, usage.PromptTokens, usage.CachedTokens(), usage.CompletionTokens),
+	)
+}
+
+// logStreamCost logs the cost of a finished stream from the usage reported on its final
+// chunk, or from the token estimate of the prompt and streamed content when there is none.
+func (s *Server) logStreamCost(providerName string, req *models.ChatCompletionRequest, result shadow.Result) {
+	if _, ok := s.catalog.Lookup(providerName, req.Model); !ok {
+		return // spare the token count
+	}
+	usage := models.Usage{
+		PromptTokens:     s.counter.CountMessages(req.Model, req.Messages),
+		CompletionTokens: s.counter.CountText(req.Model, result.Content),
+	}
+	if result.Usage != nil {
+		usage = *result.Usage
+	}
+	s.logActualCost(providerName, req.Model, usage)
+}
+
+// handleStream proxies a streaming completion and returns its outcome for shadow comparison.
+// The streamed content is accumulated for the comparison and, when the upstream reports no
+// usage, for the cost estimate logged at the end of the stream.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, provider providers.Provider, req *models.ChatCompletionRequest) shadow.Result {
+	result := shadow.Result{Provider: provider.Name(), Model: req.Model}
+	var content strings.Builder
+
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
@@ -99,7 +260,8 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, provider p
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
-		return
+		result.Error = "streaming unsupported"
+		return result
 	}
 
 	streamChan := make(chan *models.ChatCompletionStreamResponse)
@@ -108,19 +270,32 @@ func (s *Server) handleStream(w http.ResponseWriter, r *http.Request, provider p
 	if err != nil {
 		logger.Printf("[Server] Upstream Stream Init Error (%s): %v", provider.Name(), err)
 		http.Error(w, "Bad Gateway", http.StatusBadGateway)
-		return
+		result.Error = err.Error()
+		return result
 	}
+	result.Provider, result.Model = providers.ServedBy(provider), req.Model
 
 	for {
 		select {
 		case <-r.Context().Done():
-			return
+			result.Content = content.String()
+			result.Error = "client disconnected"
+			s.logStreamCost(result.Provider, req, result)
+			return result
 		case chunk, ok := <-streamChan:
 			if !ok {
 				// Channel closed, output DONE
 				w.Write([]byte("data: [DONE]\n\n"))
 				flusher.Flush()
-				return
+				result.Content = content.String()
+				s.logStreamCost(result.Provider, req, result)
+				return result
+			}
+			if len(chunk.Choices) > 0 {
+				content.WriteString(chunk.Choices[0].Delta.Content)
+			}
+			if chunk.Usage != nil {
+				result.Usage = chunk.Usage
 			}
 
 			data, _ := json.Marshal(chunk)
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+type usageProvider struct{ stubProvider }
+
+func (p *usageProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	resp, _ := p.stubProvider.ChatCompletion(ctx, req)
+	resp.Usage = models.Usage{
+		PromptTokens:        1000,
+		CompletionTokens:    500,
+		TotalTokens:         1500,
+		PromptTokensDetails: &models.PromptTokensDetails{CachedTokens: 400},
+	}
+	return resp, nil
+}
+
+type usageEngine struct{}
+
+func (e *usageEngine) SelectProvider(_ context.Context, _ *models.ChatCompletionRequest, _ *config.RemoteStrategy) (providers.Provider, string, error) {
+	return &usageProvider{}, "priced-model", nil
+}
+
+// captureServerLogs redirects the global logger into a buffer for the rest of the test.
+func captureServerLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	logger.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() { logger.SetLogger(prev) })
+	return &buf
+}
+
+func TestHandleChatCompletions_LogsCost(t *testing.T) {
+	buf := captureServerLogs(t)
+
+	cat := catalog.New([]config.ModelCatalogEntry{
+		{Provider: "mock", Model: "priced-model", InputPrice: 1, OutputPrice: 4, CachedInputPrice: 0.5},
+	})
+	srv := NewServer(&stubRM{}, &usageEngine{}, WithCatalog(cat))
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", chatReqBody(t, false))
+	w := httptest.NewRecorder()
+	srv.handleChatCompletions(w, req)
+
+	out := buf.String()
+	if !strings.Contains(out, "estimated_cost_usd=") {
+		t.Errorf("expected estimated cost in logs, got: %s", out)
+	}
+	// 600 * $1 + 400 * $0.5 + 500 * $4 per million = $0.0028
+	if !strings.Contains(out, "actual_cost_usd=0.0028") {
+		t.Errorf("expected actual cost 0.0028 in logs, got: %s", out)
+	}
+}
+
+func TestHandleChatCompletions_NoCatalogNoCostLogs(t *testing.T) {
+	buf := captureServerLogs(t)
+
+	srv := NewServer(&stubRM{}, &usageEngine{})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", chatReqBody(t, false))
+	srv.handleChatCompletions(httptest.NewRecorder(), req)
+
+	if strings.Contains(buf.String(), "cost_usd") {
+		t.Errorf("expected no cost logs without a catalog, got: %s", buf.String())
+	}
+}
+
+// streamProvider streams two content chunks, the last one carrying usage when set.
+type streamProvider struct {
+	stubProvider
+	usage *models.Usage
+}
+
+func (p *streamProvider) ChatCompletionStream(_ context.Context, _ *models.ChatCompletionRequest, ch chan<- *models.ChatCompletionStreamResponse) error {
+	go func() {
+		defer close(ch)
+		for i, text := range []string{"hello ", "world"} {
+			chunk := &models.ChatCompletionStreamResponse{Choices: make([]struct {
+				Index int `json:"index"`
+				Delta struct {
+					Role    string `json:"role,omitempty"`
+					Content string `json:"content,omitempty"`
+				} `json:"delta"`
+				FinishReason *string          `json:"finish_reason"`
+				Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
+			}, 1)}
+			chunk.Choices[0].Delta.Content = text
+			if i == 1 {
+				chunk.Usage = p.usage
+			}
+			ch <- chunk
+		}
+	}()
+	return nil
+}
+
+func TestHandleStream_LogsCost(t *testing.T) {
+	cat := catalog.New([]config.ModelCatalogEntry{
+		{Provider: "mock", Model: "priced-model", InputPrice: 1, OutputPrice: 4},
+	})
+	srv := NewServer(&stubRM{}, &usageEngine{}, WithCatalog(cat))
+	creq := &models.ChatCompletionRequest{Model: "priced-model", Stream: true, Messages: []models.Message{{Role: "user", Content: "hi"}}}
+
+	// The usage of the final chunk is priced: 1000 * $1 + 500 * $4 per million = $0.003
+	buf := captureServerLogs(t)
+	usage := &models.Usage{PromptTokens: 1000, CompletionTokens: 500}
+	srv.handleStream(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/chat/completions", nil), &streamProvider{usage: usage}, creq)
+	if out := buf.String(); !strings.Contains(out, "actual_cost_usd=0.003\n") {
+		t.Errorf("expected actual cost 0.003 in logs, got: %s", out)
+	}
+
+	// Without usage the cost is estimated from the prompt and the streamed content.
+	buf.Reset()
+	srv.handleStream(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/chat/completions", nil), &streamProvider{}, creq)
+	if out := buf.String(); !strings.Contains(out, "Actual request cost") || strings.Contains(out, "completion_tokens=0 ") {
+		t.Errorf("expected an estimated cost in logs, got: %s", out)
+	}
+}
+
+// compositeProvider is named like an alias chain but reports the stub as its server.
+type compositeProvider struct{ streamProvider }
+
+func (p *compositeProvider) Name() string     { return "alias:fast" }
+func (p *compositeProvider) ServedBy() string { return p.stubProvider.Name() }
+
+func TestHandleRequests_LogCostOfServingProvider(t *testing.T) {
+	cat := catalog.New([]config.ModelCatalogEntry{
+		{Provider: "mock", Model: "priced-model", InputPrice: 1, OutputPrice: 4},
+	})
+	srv := NewServer(&stubRM{}, &usageEngine{}, WithCatalog(cat))
+	creq := &models.ChatCompletionRequest{Model: "priced-model", Messages: []models.Message{{Role: "user", Content: "hi"}}}
+	p := &compositeProvider{streamProvider{usage: &models.Usage{PromptTokens: 1000, CompletionTokens: 500}}}
+
+	buf := captureServerLogs(t)
+	res := srv.handleSync(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/chat/completions", nil), p, creq)
+	if out := buf.String(); !strings.Contains(out, "cost\" provider=mock") || res.Provider != "mock" {
+		t.Errorf("expected the sync cost under mock, got %s: %s", res.Provider, out)
+	}
+
+	buf.Reset()
+	res = srv.handleStream(httptest.NewRecorder(), httptest.NewRequest("POST", "/v1/chat/completions", nil), p, creq)
+	if out := buf.String(); !strings.Contains(out, "actual_cost_usd=0.003\n") || res.Provider != "mock" {
+		t.Errorf("expected the stream cost under mock, got %s: %s", res.Provider, out)
+	}
+}
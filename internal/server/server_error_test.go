@@ -19,7 +19,7 @@ import (
 
 type errEngine struct{ err error }
 
-func (e *errEngine) SelectProvider(_ *models.ChatCompletionRequest, _ *config.RemoteStrategy) (providers.Provider, string, error) {
+func (e *errEngine) SelectProvider(_ context.Context, _ *models.ChatCompletionRequest, _ *config.RemoteStrategy) (providers.Provider, string, error) {
 	return nil, "", e.err
 }
 
@@ -70,3 +70,39 @@ func TestHandleStream_UpstreamError(t *testing.T) {
 		t.Errorf("expected 502, got %d", w.Code)
 	}
 }
+
+func TestHandleChatCompletions_ContextOverflow(t *testing.T) {
+	srv := NewServer(&stubRM{}, &errEngine{err: fmt.Errorf("wrapped: %w", router.ErrContextOverflow)})
+	body, _ := json.Marshal(models.ChatCompletionRequest{
+		Model:    "x",
+		Messages: []models.Message{{Role: "user", Content: "hi"}},
+	})
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.handleChatCompletions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleChatCompletions_RoutingHintErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{router.ErrOverrideNotAllowed, http.StatusForbidden},
+		{fmt.Errorf("%w: bad header", router.ErrInvalidHint), http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		srv := NewServer(&stubRM{}, &errEngine{err: c.err})
+		body, _ := json.Marshal(models.ChatCompletionRequest{
+			Model:    "x",
+			Messages: []models.Message{{Role: "user", Content: "hi"}},
+		})
+		w := httptest.NewRecorder()
+		srv.handleChatCompletions(w, httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewReader(body)))
+		if w.Code != c.code {
+			t.Errorf("%v: expected %d, got %d", c.err, c.code, w.Code)
+		}
+	}
+}
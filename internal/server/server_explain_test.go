@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/internal/router"
+)
+
+type countingProvider struct {
+	stubProvider
+	calls int
+}
+
+func (p *countingProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	p.calls++
+	return p.stubProvider.ChatCompletion(ctx, req)
+}
+
+func TestHandleRouteExplain(t *testing.T) {
+	upstream := &countingProvider{}
+	engine, err := router.NewEngine(map[string]providers.Provider{"mock": upstream})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(&stubRM{}, engine)
+
+	w := httptest.NewRecorder()
+	srv.handleRouteExplain(w, httptest.NewRequest("POST", "/v1/route/explain", chatReqBody(t, false)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var trace router.Trace
+	if err := json.Unmarshal(w.Body.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid trace %q: %v", w.Body.String(), err)
+	}
+	if trace.RemoteStrategy == nil || trace.RemoteStrategy.Strategy != "remote" {
+		t.Errorf("expected the remote strategy snapshot, got %+v", trace.RemoteStrategy)
+	}
+	if trace.Stage != "strategy" || trace.Provider != "mock" {
+		t.Errorf("expected the remote strategy to pick mock, got %s %s", trace.Stage, trace.Provider)
+	}
+	if upstream.calls != 0 {
+		t.Errorf("expected no upstream call, got %d", upstream.calls)
+	}
+}
+
+func TestHandleRouteExplain_InvalidJSON(t *testing.T) {
+	srv := newTestServer()
+	w := httptest.NewRecorder()
+	srv.handleRouteExplain(w, httptest.NewRequest("POST", "/v1/route/explain", strings.NewReader("{")))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/internal/shadow"
+)
+
+type candidateProvider struct{ stubProvider }
+
+func (p *candidateProvider) Name() string { return "candidate" }
+func (p *candidateProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	resp, _ := p.stubProvider.ChatCompletion(ctx, req)
+	resp.Choices[0].Message.Content = "candidate answer"
+	return resp, nil
+}
+
+func TestHandleChatCompletions_ShadowNotReturned(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "shadow.jsonl")
+	mirror, err := shadow.New(config.ShadowConfig{Enabled: true, Provider: "candidate", Model: "candidate-model", SampleRate: 1, OutputPath: out},
+		map[string]providers.Provider{"candidate": &candidateProvider{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := NewServer(&stubRM{}, &stubEngine{}, WithShadow(mirror))
+
+	w := httptest.NewRecorder()
+	srv.handleChatCompletions(w, httptest.NewRequest("POST", "/v1/chat/completions", chatReqBody(t, false)))
+	if strings.Contains(w.Body.String(), "candidate answer") {
+		t.Fatalf("shadow response leaked to the client: %s", w.Body.String())
+	}
+	mirror.Close()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rec shadow.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		t.Fatalf("invalid record %q: %v", data, err)
+	}
+	if rec.Primary.Provider != "mock" || rec.Primary.Model != "stub-model" || rec.Primary.Content != "ok" {
+		t.Errorf("unexpected primary result: %+v", rec.Primary)
+	}
+	if rec.Shadow.Provider != "candidate" || rec.Shadow.Model != "candidate-model" || rec.Shadow.Content != "candidate answer" {
+		t.Errorf("unexpected shadow result: %+v", rec.Shadow)
+	}
+}
@@ -30,9 +30,10 @@ func (p *stubProvider) ChatCompletion(_ context.Context, req *models.ChatComplet
 		ID:    "cmpl-stub",
 		Model: req.Model,
 		Choices: []struct {
-			Index        int            `json:"index"`
-			Message      models.Message `json:"message"`
-			FinishReason string         `json:"finish_reason"`
+			Index        int              `json:"index"`
+			Message      models.Message   `json:"message"`
+			FinishReason string           `json:"finish_reason"`
+			Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
 		}{{Message: models.Message{Role: "assistant", Content: "ok"}}},
 	}, nil
 }
@@ -43,7 +44,7 @@ func (p *stubProvider) ChatCompletionStream(_ context.Context, _ *models.ChatCom
 
 type stubEngine struct{}
 
-func (e *stubEngine) SelectProvider(_ *models.ChatCompletionRequest, _ *config.RemoteStrategy) (providers.Provider, string, error) {
+func (e *stubEngine) SelectProvider(_ context.Context, _ *models.ChatCompletionRequest, _ *config.RemoteStrategy) (providers.Provider, string, error) {
 	return &stubProvider{}, "stub-model", nil
 }
 
@@ -111,3 +112,22 @@ func TestHandleChatCompletions_Stream(t *testing.T) {
 		t.Errorf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestHandleListModels(t *testing.T) {
+	config.GlobalConfig = &config.Config{ModelAliases: map[string]config.ModelAlias{
+		"smart": {Targets: []config.AliasTarget{{Provider: "google"}}},
+		"fast":  {Targets: []config.AliasTarget{{Provider: "local_vllm"}}},
+	}}
+	defer func() { config.GlobalConfig = nil }()
+
+	w := httptest.NewRecorder()
+	newTestServer().handleListModels(w, httptest.NewRequest("GET", "/v1/models", nil))
+
+	var list models.ModelList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid body %q: %v", w.Body.String(), err)
+	}
+	if list.Object != "list" || len(list.Data) != 2 || list.Data[0].ID != "fast" || list.Data[1].ID != "smart" {
+		t.Errorf("unexpected model list: %+v", list)
+	}
+}
@@ -0,0 +1,255 @@
+// Package shadow mirrors a sample of live requests to a candidate provider/model and
+// records both responses side by side, so the candidate can be compared offline before
+// it receives real traffic. Shadow responses are never returned to clients.
+package shadow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+	"agentic-llm-gateway/pkg/logger"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+)
+
+const (
+	defaultMaxConcurrent = 4
+	defaultTimeout       = 60 * time.Second
+)
+
+// Env is the environment of the sampling filter expression.
+type Env struct {
+	Req      *models.ChatCompletionRequest
+	Provider string // primary provider chosen by the router
+}
+
+// Result describes one completion of a mirrored request.
+type Result struct {
+	Provider  string        `json:"provider"`
+	Model     string        `json:"model"`
+	LatencyMs int64         `json:"latency_ms"`
+	Content   string        `json:"content,omitempty"`
+	Usage     *models.Usage `json:"usage,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Record is one line of the output file.
+type Record struct {
+	Timestamp time.Time        `json:"timestamp"`
+	Messages  []models.Message `json:"messages"`
+	Primary   Result           `json:"primary"`
+	Shadow    Result           `json:"shadow"`
+}
+
+// Mirror sends sampled requests to the candidate. A nil *Mirror mirrors nothing.
+type Mirror struct {
+	provider   providers.Provider
+	model      string
+	sampleRate float64
+	filter     *vm.Program
+	timeout    time.Duration
+	sem        chan struct{}
+
+	mu  sync.Mutex
+	out *os.File
+	enc *json.Encoder
+
+	wg      sync.WaitGroup
+	dropped atomic.Uint64
+	random  func() float64
+}
+
+// Validate checks cfg against the names of the configured providers without opening the
+// output file. All problems are reported together.
+func Validate(cfg config.ShadowConfig, providerNames []string) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	var errs []error
+	if !slices.Contains(providerNames, cfg.Provider) {
+		errs = append(errs, fmt.Errorf("shadow provider %q not configured", cfg.Provider))
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		errs = append(errs, fmt.Errorf("shadow sample_rate must be between 0 and 1, got %v", cfg.SampleRate))
+	}
+	if cfg.OutputPath == "" {
+		errs = append(errs, fmt.Errorf("shadow output_path is required"))
+	}
+	if _, err := compileFilter(cfg.Filter); err != nil {
+		errs = append(errs, fmt.Errorf("compile shadow filter: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// compileFilter compiles the sampling filter, nil when unset.
+func compileFilter(src string) (*vm.Program, error) {
+	if src == "" {
+		return nil, nil
+	}
+	return expr.Compile(src, expr.Env(Env{}), expr.AsBool())
+}
+
+// New creates the Mirror described by cfg. It returns nil when mirroring is disabled.
+func New(cfg config.ShadowConfig, pMap map[string]providers.Provider) (*Mirror, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := Validate(cfg, slices.Collect(maps.Keys(pMap))); err != nil {
+		return nil, err
+	}
+	p := pMap[cfg.Provider]
+	filter, _ := compileFilter(cfg.Filter)
+
+	maxConcurrent := cfg.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultMaxConcurrent
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	out, err := os.OpenFile(cfg.OutputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open shadow output: %w", err)
+	}
+
+	return &Mirror{
+		provider:   p,
+		model:      cfg.Model,
+		sampleRate: cfg.SampleRate,
+		filter:     filter,
+		timeout:    timeout,
+		sem:        make(chan struct{}, maxConcurrent),
+		out:        out,
+		enc:        json.NewEncoder(out),
+		random:     rand.Float64,
+	}, nil
+}
+
+// Pending is a mirrored request waiting for the primary result.
+type Pending struct {
+	primary chan Result
+}
+
+// Finish hands the primary result to the mirrored request. It is safe on a nil *Pending.
+func (p *Pending) Finish(primary Result) {
+	if p == nil {
+		return
+	}
+	select {
+	case p.primary <- primary:
+	default: // already finished
+	}
+}
+
+// Start mirrors req if it is sampled and the concurrency cap allows it, returning nil
+// otherwise. The caller must Finish the returned Pending with the primary result.
+// req must already carry the model chosen for primaryProvider.
+func (m *Mirror) Start(req *models.ChatCompletionRequest, primaryProvider string) *Pending {
+	if m == nil {
+		return nil
+	}
+	if primaryProvider == m.provider.Name() && (m.model == "" || m.model == req.Model) {
+		return nil // the candidate already serves this request
+	}
+	if m.random() >= m.sampleRate {
+		return nil
+	}
+	if m.filter != nil {
+		matched, err := expr.Run(m.filter, Env{Req: req, Provider: primaryProvider})
+		if err != nil {
+			logger.Warnf("[Shadow] Filter failed: %v", err)
+			return nil
+		}
+		if b, ok := matched.(bool); !ok || !b {
+			return nil
+		}
+	}
+
+	select {
+	case m.sem <- struct{}{}:
+	default:
+		m.dropped.Add(1)
+		logger.Debugf("[Shadow] Concurrency cap reached, dropping sample")
+		return nil
+	}
+
+	shadowReq := *req
+	shadowReq.Stream = false
+	shadowReq.Model = m.model
+	shadowReq.Messages = append([]models.Message(nil), req.Messages...)
+
+	p := &Pending{primary: make(chan Result, 1)}
+	m.wg.Add(1)
+	go m.run(&shadowReq, p)
+	return p
+}
+
+func (m *Mirror) run(req *models.ChatCompletionRequest, p *Pending) {
+	defer m.wg.Done()
+	defer func() { <-m.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	started := time.Now()
+	resp, err := m.provider.ChatCompletion(ctx, req)
+	// Providers resolve req.Model in place, so it names the model that actually answered.
+	result := Result{Provider: m.provider.Name(), Model: req.Model, LatencyMs: time.Since(started).Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Usage = &resp.Usage
+		if len(resp.Choices) > 0 {
+			result.Content = resp.Choices[0].Message.Content
+		}
+	}
+
+	var primary Result
+	select {
+	case primary = <-p.primary:
+	case <-time.After(m.timeout):
+		primary = Result{Error: "primary result not reported"}
+	}
+
+	m.write(Record{Timestamp: started.UTC(), Messages: req.Messages, Primary: primary, Shadow: result})
+}
+
+func (m *Mirror) write(rec Record) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.enc.Encode(rec); err != nil {
+		logger.Errorf("[Shadow] Failed to write record: %v", err)
+	}
+}
+
+// Dropped returns the number of samples dropped because of the concurrency cap.
+func (m *Mirror) Dropped() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.dropped.Load()
+}
+
+// Close waits for in-flight mirrored requests and closes the output file.
+func (m *Mirror) Close() error {
+	if m == nil {
+		return nil
+	}
+	m.wg.Wait()
+	return m.out.Close()
+}
@@ -0,0 +1,250 @@
+package shadow
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/internal/providers"
+)
+
+type stubProvider struct {
+	name    string
+	block   chan struct{}
+	err     error
+	lastReq *models.ChatCompletionRequest
+}
+
+func (p *stubProvider) Name() string { return p.name }
+func (p *stubProvider) ChatCompletion(ctx context.Context, req *models.ChatCompletionRequest) (*models.ChatCompletionResponse, error) {
+	p.lastReq = req
+	if p.block != nil {
+		<-p.block
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	resp := &models.ChatCompletionResponse{Usage: models.Usage{PromptTokens: 7, CompletionTokens: 2, TotalTokens: 9}}
+	resp.Choices = append(resp.Choices, struct {
+		Index        int              `json:"index"`
+		Message      models.Message   `json:"message"`
+		FinishReason string           `json:"finish_reason"`
+		Logprobs     *models.Logprobs `json:"logprobs,omitempty"`
+	}{Message: models.Message{Role: "assistant", Content: "shadow answer"}})
+	return resp, nil
+}
+func (p *stubProvider) ChatCompletionStream(ctx context.Context, req *models.ChatCompletionRequest, streamChan chan<- *models.ChatCompletionStreamResponse) error {
+	return errors.New("not supported")
+}
+
+func newTestMirror(t *testing.T, cfg config.ShadowConfig, candidate *stubProvider) *Mirror {
+	t.Helper()
+	cfg.Enabled = true
+	cfg.Provider = candidate.name
+	cfg.OutputPath = filepath.Join(t.TempDir(), "shadow.jsonl")
+	m, err := New(cfg, map[string]providers.Provider{candidate.name: candidate})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m
+}
+
+func readRecords(t *testing.T, m *Mirror) []Record {
+	t.Helper()
+	f, err := os.Open(m.out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var records []Record
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec Record
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatalf("invalid record %q: %v", sc.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func chatRequest(model string) *models.ChatCompletionRequest {
+	return &models.ChatCompletionRequest{Model: model, Stream: true, Messages: []models.Message{{Role: "user", Content: "hi"}}}
+}
+
+func TestNew_Validation(t *testing.T) {
+	pMap := map[string]providers.Provider{"deepseek": &stubProvider{name: "deepseek"}}
+	out := filepath.Join(t.TempDir(), "shadow.jsonl")
+
+	if m, err := New(config.ShadowConfig{}, pMap); m != nil || err != nil {
+		t.Errorf("expected nil mirror when disabled, got %v, %v", m, err)
+	}
+
+	bad := []config.ShadowConfig{
+		{Enabled: true, Provider: "missing", SampleRate: 1, OutputPath: out},
+		{Enabled: true, Provider: "deepseek", SampleRate: 1.5, OutputPath: out},
+		{Enabled: true, Provider: "deepseek", SampleRate: 1},
+		{Enabled: true, Provider: "deepseek", SampleRate: 1, OutputPath: out, Filter: "len(Req.Messages) >"},
+	}
+	for _, cfg := range bad {
+		if _, err := New(cfg, pMap); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestMirror_RecordsBothResponses(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek"}
+	m := newTestMirror(t, config.ShadowConfig{Model: "deepseek-chat", SampleRate: 1}, candidate)
+
+	req := chatRequest("gemini")
+	p := m.Start(req, "google")
+	if p == nil {
+		t.Fatal("expected the request to be mirrored")
+	}
+	p.Finish(Result{Provider: "google", Model: "gemini", LatencyMs: 12, Content: "primary answer"})
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if candidate.lastReq.Stream || candidate.lastReq.Model != "deepseek-chat" {
+		t.Errorf("expected a non-streaming request for the candidate model, got %+v", candidate.lastReq)
+	}
+	if req.Model != "gemini" || !req.Stream {
+		t.Errorf("expected the primary request to be left untouched, got %+v", req)
+	}
+
+	records := readRecords(t, m)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	rec := records[0]
+	if rec.Primary.Content != "primary answer" || rec.Primary.LatencyMs != 12 {
+		t.Errorf("unexpected primary result: %+v", rec.Primary)
+	}
+	if rec.Shadow.Provider != "deepseek" || rec.Shadow.Model != "deepseek-chat" || rec.Shadow.Content != "shadow answer" {
+		t.Errorf("unexpected shadow result: %+v", rec.Shadow)
+	}
+	if rec.Shadow.Usage == nil || rec.Shadow.Usage.TotalTokens != 9 {
+		t.Errorf("expected shadow usage to be recorded, got %+v", rec.Shadow.Usage)
+	}
+	if len(rec.Messages) != 1 || rec.Messages[0].Content != "hi" {
+		t.Errorf("unexpected messages: %+v", rec.Messages)
+	}
+}
+
+func TestMirror_RecordsShadowError(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek", err: errors.New("upstream down")}
+	m := newTestMirror(t, config.ShadowConfig{SampleRate: 1}, candidate)
+
+	m.Start(chatRequest("gemini"), "google").Finish(Result{Provider: "google"})
+	m.Close()
+
+	records := readRecords(t, m)
+	if len(records) != 1 || records[0].Shadow.Error != "upstream down" {
+		t.Errorf("expected the shadow error to be recorded, got %+v", records)
+	}
+}
+
+func TestMirror_Sampling(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek"}
+	m := newTestMirror(t, config.ShadowConfig{SampleRate: 0.5}, candidate)
+	defer m.Close()
+
+	m.random = func() float64 { return 0.7 }
+	if m.Start(chatRequest("gemini"), "google") != nil {
+		t.Error("expected a request above the sample rate to be skipped")
+	}
+	m.random = func() float64 { return 0.2 }
+	p := m.Start(chatRequest("gemini"), "google")
+	if p == nil {
+		t.Error("expected a request below the sample rate to be mirrored")
+	}
+	p.Finish(Result{})
+}
+
+func TestMirror_Filter(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek"}
+	m := newTestMirror(t, config.ShadowConfig{SampleRate: 1, Filter: "Provider == 'google' && len(Req.Messages) > 1"}, candidate)
+	defer m.Close()
+
+	if m.Start(chatRequest("gemini"), "google") != nil {
+		t.Error("expected the filter to reject a single-message request")
+	}
+	req := chatRequest("gemini")
+	req.Messages = append(req.Messages, models.Message{Role: "user", Content: "more"})
+	if m.Start(req, "local_vllm") != nil {
+		t.Error("expected the filter to reject another primary provider")
+	}
+	p := m.Start(req, "google")
+	if p == nil {
+		t.Error("expected the filter to accept the request")
+	}
+	p.Finish(Result{})
+}
+
+func TestMirror_SkipsCandidateTraffic(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek"}
+	m := newTestMirror(t, config.ShadowConfig{Model: "deepseek-chat", SampleRate: 1}, candidate)
+	defer m.Close()
+
+	if m.Start(chatRequest("deepseek-chat"), "deepseek") != nil {
+		t.Error("expected requests already served by the candidate not to be mirrored")
+	}
+}
+
+func TestMirror_ConcurrencyCap(t *testing.T) {
+	candidate := &stubProvider{name: "deepseek", block: make(chan struct{})}
+	m := newTestMirror(t, config.ShadowConfig{SampleRate: 1, MaxConcurrent: 1}, candidate)
+
+	first := m.Start(chatRequest("gemini"), "google")
+	if first == nil {
+		t.Fatal("expected the first request to be mirrored")
+	}
+	if m.Start(chatRequest("gemini"), "google") != nil {
+		t.Error("expected the second request to be dropped at the cap")
+	}
+	if m.Dropped() != 1 {
+		t.Errorf("expected 1 dropped sample, got %d", m.Dropped())
+	}
+
+	close(candidate.block)
+	first.Finish(Result{})
+	m.Close()
+}
+
+func TestNilMirror(t *testing.T) {
+	var m *Mirror
+	p := m.Start(chatRequest("gemini"), "google")
+	if p != nil {
+		t.Error("expected nil mirror to mirror nothing")
+	}
+	p.Finish(Result{})
+	if m.Dropped() != 0 || m.Close() != nil {
+		t.Error("expected nil mirror to be inert")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := config.ShadowConfig{Enabled: true, Provider: "deepseek", SampleRate: 1, OutputPath: "shadow.jsonl", Filter: "len(Req.Messages) > 2"}
+	if err := Validate(cfg, []string{"deepseek"}); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+	if _, err := os.Stat("shadow.jsonl"); !os.IsNotExist(err) {
+		t.Error("expected Validate not to create the output file")
+	}
+
+	cfg.Provider, cfg.Filter = "missing", "Req.Nope"
+	err := Validate(cfg, []string{"deepseek"})
+	if err == nil || !strings.Contains(err.Error(), "missing") || !strings.Contains(err.Error(), "filter") {
+		t.Errorf("expected the provider and the filter to be reported, got %v", err)
+	}
+}
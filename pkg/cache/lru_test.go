@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRU_GetSet(t *testing.T) {
+	c := New[string, int](0, 0)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected miss on empty cache")
+	}
+	c.Set("a", 1)
+	c.Set("a", 2)
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Errorf("expected 2, got %v (ok=%v)", v, ok)
+	}
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected miss after delete")
+	}
+
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 2 || s.Size != 0 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int](2, 0)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // a is now more recent than b
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected a to survive")
+	}
+	if c.Len() != 2 || c.Stats().Evictions != 1 {
+		t.Errorf("unexpected state: len=%d stats=%+v", c.Len(), c.Stats())
+	}
+}
+
+func TestLRU_Expiry(t *testing.T) {
+	c := New[string, int](0, time.Minute)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected entry to be live before the TTL")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected entry to expire after the TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired entry to be reclaimed, len=%d", c.Len())
+	}
+}
+
+func TestLRU_Concurrent(t *testing.T) {
+	c := New[int, int](100, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Set(j%150, i)
+				c.Get(j % 150)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if c.Len() > 100 {
+		t.Errorf("expected at most 100 entries, got %d", c.Len())
+	}
+}
@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"path"
+	"strings"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+// DefaultOutputTokens is the completion length assumed for cost estimates when
+// neither the request nor the configuration bounds it.
+const DefaultOutputTokens = 512
+
+// Catalog indexes the model_catalog section for pricing and capability lookups.
+// A nil *Catalog is valid and behaves as an empty catalog.
+type Catalog struct {
+	entries []config.ModelCatalogEntry
+}
+
+// Requirements describes what a request needs from the model that serves it.
+type Requirements struct {
+	PromptTokens int
+	OutputTokens int
+	Tools        bool
+	Vision       bool
+}
+
+// New builds a catalog from the configured entries, preserving their order.
+func New(entries []config.ModelCatalogEntry) *Catalog {
+	return &Catalog{entries: entries}
+}
+
+// Entries returns the catalog entries in configuration order.
+func (c *Catalog) Entries() []config.ModelCatalogEntry {
+	if c == nil {
+		return nil
+	}
+	return c.entries
+}
+
+// Lookup returns the entry describing provider/model. Exact model names take
+// precedence over glob entries; the first matching glob wins.
+func (c *Catalog) Lookup(provider, model string) (config.ModelCatalogEntry, bool) {
+	if c == nil {
+		return config.ModelCatalogEntry{}, false
+	}
+	for _, e := range c.entries {
+		if e.Provider == provider && e.Model == model {
+			return e, true
+		}
+	}
+	for _, e := range c.entries {
+		if e.Provider != provider || !isGlob(e.Model) {
+			continue
+		}
+		if ok, _ := path.Match(e.Model, model); ok {
+			return e, true
+		}
+	}
+	return config.ModelCatalogEntry{}, false
+}
+
+// Restrict returns a catalog holding only the entries whose provider satisfies keep.
+func (c *Catalog) Restrict(keep func(provider string) bool) *Catalog {
+	var kept []config.ModelCatalogEntry
+	for _, e := range c.Entries() {
+		if keep(e.Provider) {
+			kept = append(kept, e)
+		}
+	}
+	return New(kept)
+}
+
+// Cheapest returns the concrete (non-glob) entry with the lowest estimated cost that
+// satisfies r. allowed, if non-nil, restricts the candidate providers.
+// Ties keep configuration order.
+func (c *Catalog) Cheapest(r Requirements, allowed func(provider string) bool) (config.ModelCatalogEntry, bool) {
+	var best config.ModelCatalogEntry
+	bestCost := -1.0
+	for _, e := range c.Entries() {
+		if isGlob(e.Model) || !Satisfies(e, r) {
+			continue
+		}
+		if allowed != nil && !allowed(e.Provider) {
+			continue
+		}
+		cost := EstimateCost(e, r.PromptTokens, 0, r.OutputTokens)
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = e, cost
+		}
+	}
+	return best, bestCost >= 0
+}
+
+// Satisfies reports whether e can serve a request with requirements r.
+// A zero ContextWindow is treated as unknown and never rejects a request.
+func Satisfies(e config.ModelCatalogEntry, r Requirements) bool {
+	if r.Tools && !e.Tools {
+		return false
+	}
+	if r.Vision && !e.Vision {
+		return false
+	}
+	if e.ContextWindow > 0 && r.PromptTokens+r.OutputTokens > e.ContextWindow {
+		return false
+	}
+	return true
+}
+
+// EstimateCost returns the USD cost of promptTokens input tokens, cachedTokens of
+// which were served from the upstream prompt cache, plus outputTokens completion tokens.
+func EstimateCost(e config.ModelCatalogEntry, promptTokens, cachedTokens, outputTokens int) float64 {
+	if cachedTokens > promptTokens {
+		cachedTokens = promptTokens
+	}
+	cachedPrice := e.CachedInputPrice
+	if cachedPrice == 0 {
+		cachedPrice = e.InputPrice
+	}
+	cost := float64(promptTokens-cachedTokens) * e.InputPrice
+	cost += float64(cachedTokens) * cachedPrice
+	cost += float64(outputTokens) * e.OutputPrice
+	return cost / 1_000_000
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"math"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+func testEntries() []config.ModelCatalogEntry {
+	return []config.ModelCatalogEntry{
+		{Provider: "local_vllm", Model: "qwen-32b", InputPrice: 0, OutputPrice: 0, ContextWindow: 32768},
+		{Provider: "deepseek", Model: "deepseek-chat", InputPrice: 0.27, OutputPrice: 1.10, CachedInputPrice: 0.07, ContextWindow: 65536, Tools: true},
+		{Provider: "google", Model: "gemini-2.5-flash", InputPrice: 0.30, OutputPrice: 2.50, ContextWindow: 1048576, Tools: true, Vision: true},
+		{Provider: "google", Model: "gemini-*", InputPrice: 1.25, OutputPrice: 10},
+	}
+}
+
+func TestCatalog_Lookup(t *testing.T) {
+	c := New(testEntries())
+
+	e, ok := c.Lookup("google", "gemini-2.5-flash")
+	if !ok || e.InputPrice != 0.30 {
+		t.Errorf("expected exact entry, got %+v (ok=%v)", e, ok)
+	}
+
+	e, ok = c.Lookup("google", "gemini-2.5-pro")
+	if !ok || e.InputPrice != 1.25 {
+		t.Errorf("expected glob entry, got %+v (ok=%v)", e, ok)
+	}
+
+	if _, ok := c.Lookup("openai", "gpt-5"); ok {
+		t.Error("expected no entry for unknown provider")
+	}
+}
+
+func TestCatalog_NilSafe(t *testing.T) {
+	var c *Catalog
+	if _, ok := c.Lookup("google", "x"); ok {
+		t.Error("expected nil catalog lookup to miss")
+	}
+	if _, ok := c.Cheapest(Requirements{}, nil); ok {
+		t.Error("expected nil catalog to have no cheapest entry")
+	}
+}
+
+func TestCatalog_Cheapest(t *testing.T) {
+	c := New(testEntries())
+
+	tests := []struct {
+		name     string
+		req      Requirements
+		allowed  func(string) bool
+		expected string
+	}{
+		{"Free local model wins", Requirements{PromptTokens: 1000, OutputTokens: 500}, nil, "qwen-32b"},
+		{"Context overflow skips local", Requirements{PromptTokens: 40000, OutputTokens: 500}, nil, "deepseek-chat"},
+		{"Tools skip local", Requirements{PromptTokens: 1000, Tools: true}, nil, "deepseek-chat"},
+		{"Vision needs gemini", Requirements{PromptTokens: 1000, Vision: true}, nil, "gemini-2.5-flash"},
+		{"Huge prompt needs gemini", Requirements{PromptTokens: 200000}, nil, "gemini-2.5-flash"},
+		{"Allowed filter", Requirements{PromptTokens: 10}, func(p string) bool { return p == "google" }, "gemini-2.5-flash"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, ok := c.Cheapest(tc.req, tc.allowed)
+			if !ok {
+				t.Fatal("expected a candidate")
+			}
+			if e.Model != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, e.Model)
+			}
+		})
+	}
+
+	if _, ok := c.Cheapest(Requirements{PromptTokens: 2000000}, nil); ok {
+		t.Error("expected no candidate when nothing fits")
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	e := config.ModelCatalogEntry{InputPrice: 2, OutputPrice: 8, CachedInputPrice: 0.5}
+	got := EstimateCost(e, 1_000_000, 500_000, 250_000)
+	// 0.5M * 2 + 0.5M * 0.5 + 0.25M * 8 = 1 + 0.25 + 2
+	if math.Abs(got-3.25) > 1e-9 {
+		t.Errorf("expected 3.25, got %v", got)
+	}
+
+	// Cached tokens fall back to the input price when no cached price is configured.
+	e.CachedInputPrice = 0
+	got = EstimateCost(e, 1_000_000, 1_000_000, 0)
+	if math.Abs(got-2) > 1e-9 {
+		t.Errorf("expected 2, got %v", got)
+	}
+}
+
+func TestCatalog_Restrict(t *testing.T) {
+	c := New(testEntries()).Restrict(func(p string) bool { return p != "local_vllm" })
+	if _, ok := c.Lookup("local_vllm", "qwen-32b"); ok {
+		t.Error("expected local_vllm entries to be dropped")
+	}
+	if len(c.Entries()) != 3 {
+		t.Errorf("expected 3 remaining entries, got %d", len(c.Entries()))
+	}
+}
@@ -0,0 +1,265 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// The rule-based builtins inspect the latest message and up to history_rounds earlier
+// messages without calling a model.
+
+// BuiltinKeywordsEvaluator scores the highest scoring rule that matches a message, or 0
+// when none does.
+type BuiltinKeywordsEvaluator struct {
+	name          string
+	historyRounds int
+	rules         []keywordRule
+}
+
+type keywordRule struct {
+	keywords []string // lowercased
+	patterns []*regexp.Regexp
+	score    float64
+}
+
+// NewBuiltinKeywordsEvaluator compiles the rules of cfg.
+func NewBuiltinKeywordsEvaluator(cfg config.EvaluatorConfig) (*BuiltinKeywordsEvaluator, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("no rules configured")
+	}
+	e := &BuiltinKeywordsEvaluator{name: cfg.Name, historyRounds: cfg.HistoryRounds}
+	for i, rCfg := range cfg.Rules {
+		if len(rCfg.Keywords) == 0 && len(rCfg.Patterns) == 0 {
+			return nil, fmt.Errorf("rule %d has neither keywords nor patterns", i)
+		}
+		r := keywordRule{score: 1}
+		if rCfg.Score != nil {
+			r.score = *rCfg.Score
+		}
+		for _, kw := range rCfg.Keywords {
+			r.keywords = append(r.keywords, strings.ToLower(kw))
+		}
+		for _, p := range rCfg.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			r.patterns = append(r.patterns, re)
+		}
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+func (e *BuiltinKeywordsEvaluator) Name() string {
+	return e.name
+}
+
+func (e *BuiltinKeywordsEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+func (e *BuiltinKeywordsEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+	score := 0.0
+	for _, m := range recentMessages(messages, e.historyRounds) {
+		lower := strings.ToLower(m.Content)
+		for _, r := range e.rules {
+			if r.score > score && r.matches(m.Content, lower) {
+				score = r.score
+			}
+		}
+	}
+	logger.Debugf("[Evaluator %s] Verbose Output Score: %f", e.name, score)
+	return &EvaluationResult{Dimension: e.name, Score: score}, nil
+}
+
+func (r keywordRule) matches(content, lower string) bool {
+	for _, kw := range r.keywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// Signals of BuiltinCodeEvaluator
+var codeSignals = map[string]*regexp.Regexp{
+	// fenced markdown code blocks
+	"fenced": regexp.MustCompile("(?m)^\\s*(?:```|~~~)"),
+	// Go panics, Python tracebacks, Java/JS stack frames
+	"stack_trace": regexp.MustCompile(`(?m)^goroutine \d+ \[|Traceback \(most recent call last\)|(?m)^\s+at [\w$.<>]+\s*\(.*:\d+(?::\d+)?\)|(?m)^\s+File ".+", line \d+`),
+	// unified diff hunks and git diff headers
+	"diff": regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+\d+(?:,\d+)? @@|(?m)^diff --git |(?m)^--- a/.+\n\+\+\+ b/`),
+	// indented blocks, declarations at the start of a line and calls followed by a brace
+	// or semicolon
+	"source": regexp.MustCompile("(?m)^(?:    |\\t)\\S|(?m)^\\s*(?:def|func|class|import|package|#include)\\s+\\w|\\w\\([^()\\n]*\\)\\s*[{;]"),
+}
+
+// Signals of BuiltinAttachmentsEvaluator
+var attachmentSignals = map[string]*regexp.Regexp{
+	"url":   regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`),
+	"image": regexp.MustCompile(`(?i)data:image/|!\[[^\]]*\]\([^)]+\)|\S+\.(?:png|jpe?g|gif|webp|bmp|svg)\b`),
+	"file":  regexp.MustCompile(`(?i)data:application/|\S+\.(?:pdf|docx?|xlsx?|pptx?|csv|zip|tar\.gz)\b`),
+}
+
+// HasCode reports whether any message contains code, by the signals of builtin_code.
+func HasCode(messages []models.Message) bool {
+	return anySignal(messages, codeSignals) != ""
+}
+
+// HasImages reports whether any message embeds or links an image, by the image signal
+// of builtin_attachments.
+func HasImages(messages []models.Message) bool {
+	return anySignal(messages, imageSignals) != ""
+}
+
+var imageSignals = map[string]*regexp.Regexp{"image": attachmentSignals["image"]}
+
+// anySignal returns the name of the first signal found in messages, or "".
+func anySignal(messages []models.Message, signals map[string]*regexp.Regexp) string {
+	for _, m := range messages {
+		for name, re := range signals {
+			if re.MatchString(m.Content) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// BuiltinSignalEvaluator scores 1 when any of its signals is found in a message and 0
+// otherwise. It implements the builtin_code and builtin_attachments types.
+type BuiltinSignalEvaluator struct {
+	name          string
+	historyRounds int
+	signals       map[string]*regexp.Regexp
+}
+
+// NewBuiltinCodeEvaluator detects code: fenced blocks, stack traces and diff hunks.
+func NewBuiltinCodeEvaluator(cfg config.EvaluatorConfig) (*BuiltinSignalEvaluator, error) {
+	return newBuiltinSignalEvaluator(cfg, codeSignals)
+}
+
+// NewBuiltinAttachmentsEvaluator detects URLs, images and attached files.
+func NewBuiltinAttachmentsEvaluator(cfg config.EvaluatorConfig) (*BuiltinSignalEvaluator, error) {
+	return newBuiltinSignalEvaluator(cfg, attachmentSignals)
+}
+
+func newBuiltinSignalEvaluator(cfg config.EvaluatorConfig, known map[string]*regexp.Regexp) (*BuiltinSignalEvaluator, error) {
+	e := &BuiltinSignalEvaluator{name: cfg.Name, historyRounds: cfg.HistoryRounds, signals: known}
+	if len(cfg.Signals) == 0 {
+		return e, nil
+	}
+	e.signals = make(map[string]*regexp.Regexp, len(cfg.Signals))
+	for _, s := range cfg.Signals {
+		re, ok := known[s]
+		if !ok {
+			return nil, fmt.Errorf("unknown signal %q", s)
+		}
+		e.signals[s] = re
+	}
+	return e, nil
+}
+
+func (e *BuiltinSignalEvaluator) Name() string {
+	return e.name
+}
+
+func (e *BuiltinSignalEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+func (e *BuiltinSignalEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+	if name := anySignal(recentMessages(messages, e.historyRounds), e.signals); name != "" {
+		logger.Debugf("[Evaluator %s] Detected %s", e.name, name)
+		return &EvaluationResult{Dimension: e.name, Score: 1}, nil
+	}
+	return &EvaluationResult{Dimension: e.name, Score: 0}, nil
+}
+
+// cjkScripts make up the "cjk" script of BuiltinLanguageEvaluator.
+var cjkScripts = []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul}
+
+// BuiltinLanguageEvaluator scores the share of letters written in a Unicode script, from
+// 0 to 1. Digits, punctuation and whitespace are ignored.
+type BuiltinLanguageEvaluator struct {
+	name          string
+	historyRounds int
+	script        []*unicode.RangeTable
+}
+
+// NewBuiltinLanguageEvaluator creates a language evaluator for cfg.Script, "cjk" by default
+// or any script name of the unicode package.
+func NewBuiltinLanguageEvaluator(cfg config.EvaluatorConfig) (*BuiltinLanguageEvaluator, error) {
+	e := &BuiltinLanguageEvaluator{name: cfg.Name, historyRounds: cfg.HistoryRounds}
+	switch script := cfg.Script; script {
+	case "", "cjk":
+		e.script = cjkScripts
+	default:
+		table, ok := unicode.Scripts[script]
+		if !ok {
+			return nil, fmt.Errorf("unknown script %q", script)
+		}
+		e.script = []*unicode.RangeTable{table}
+	}
+	return e, nil
+}
+
+func (e *BuiltinLanguageEvaluator) Name() string {
+	return e.name
+}
+
+func (e *BuiltinLanguageEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+func (e *BuiltinLanguageEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+	var letters, inScript int
+	for _, m := range recentMessages(messages, e.historyRounds) {
+		for _, r := range m.Content {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			if unicode.IsOneOf(e.script, r) {
+				inScript++
+			}
+		}
+	}
+	score := 0.0
+	if letters > 0 {
+		score = float64(inScript) / float64(letters)
+	}
+	logger.Debugf("[Evaluator %s] Verbose Output Score: %f", e.name, score)
+	return &EvaluationResult{Dimension: e.name, Score: score}, nil
+}
+
+// recentMessages returns the latest message preceded by up to historyRounds earlier ones,
+// or the whole conversation for a negative historyRounds.
+func recentMessages(messages []models.Message, historyRounds int) []models.Message {
+	if historyRounds < 0 {
+		return messages
+	}
+	return messages[max(len(messages)-1-historyRounds, 0):]
+}
@@ -0,0 +1,170 @@
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+func ruleScore(t *testing.T, cfg config.EvaluatorConfig, contents ...string) float64 {
+	t.Helper()
+	ev, err := New(cfg, Deps{})
+	if err != nil {
+		t.Fatalf("%s: %v", cfg.Type, err)
+	}
+	var msgs []models.Message
+	for i, c := range contents {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		msgs = append(msgs, models.Message{Role: role, Content: c})
+	}
+	res, err := ev.Evaluate(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("%s: %v", cfg.Type, err)
+	}
+	return res.Score
+}
+
+func TestBuiltinKeywordsEvaluator(t *testing.T) {
+	contractScore := 0.6
+	cfg := config.EvaluatorConfig{Name: "legal", Type: "builtin_keywords", Rules: []config.KeywordRuleConfig{
+		{Keywords: []string{"Contract", "liability"}, Score: &contractScore},
+		{Patterns: []string{`(?i)\bGDPR\b`}},
+	}}
+	cases := []struct {
+		contents []string
+		want     float64
+	}{
+		{[]string{"Review this CONTRACT"}, 0.6},
+		{[]string{"is this contract GDPR compliant?"}, 1},
+		{[]string{"tell me a joke"}, 0},
+		{[]string{"about gdpr", "sure", "thanks"}, 0}, // history_rounds 0 only looks at the last message
+	}
+	for _, c := range cases {
+		if got := ruleScore(t, cfg, c.contents...); got != c.want {
+			t.Errorf("%v: expected %v, got %v", c.contents, c.want, got)
+		}
+	}
+
+	cfg.HistoryRounds = 2
+	if got := ruleScore(t, cfg, "about gdpr", "sure", "thanks"); got != 1 {
+		t.Errorf("expected history to be searched, got %v", got)
+	}
+
+	for _, bad := range [][]config.KeywordRuleConfig{nil, {{Score: &contractScore}}, {{Patterns: []string{"("}}}} {
+		if _, err := NewBuiltinKeywordsEvaluator(config.EvaluatorConfig{Rules: bad}); err == nil {
+			t.Errorf("expected rules %+v to be rejected", bad)
+		}
+	}
+}
+
+func TestBuiltinKeywordsEvaluator_ZeroScore(t *testing.T) {
+	zero := 0.0
+	cfg := config.EvaluatorConfig{Name: "smalltalk", Type: "builtin_keywords", Rules: []config.KeywordRuleConfig{
+		{Keywords: []string{"weather"}, Score: &zero},
+	}}
+	if got := ruleScore(t, cfg, "how is the weather?"); got != 0 {
+		t.Errorf("expected an explicit score of 0 to stay 0, got %v", got)
+	}
+}
+
+func TestBuiltinCodeEvaluator(t *testing.T) {
+	cfg := config.EvaluatorConfig{Name: "code", Type: "builtin_code"}
+	cases := map[string]float64{
+		"```go\nfunc main() {}\n```":                                          1,
+		"panic: boom\n\ngoroutine 1 [running]:\nmain.main()":                  1,
+		"Traceback (most recent call last):\n  File \"x.py\", line 3, in <m>": 1,
+		"Exception in thread\n    at com.acme.App.run(App.java:42)":           1,
+		"@@ -1,3 +1,4 @@\n-old\n+new":                                         1,
+		"diff --git a/x.go b/x.go":                                            1,
+		"func main() {\n\tfmt.Println(1)\n}":                                  1,
+		"what is a goroutine?":                                                0,
+	}
+	for content, want := range cases {
+		if got := ruleScore(t, cfg, content); got != want {
+			t.Errorf("%q: expected %v, got %v", content, want, got)
+		}
+	}
+
+	cfg.Signals = []string{"diff"}
+	if got := ruleScore(t, cfg, "```go\nx\n```"); got != 0 {
+		t.Errorf("expected only diffs to be detected, got %v", got)
+	}
+	if _, err := NewBuiltinCodeEvaluator(config.EvaluatorConfig{Signals: []string{"nope"}}); err == nil {
+		t.Error("expected an unknown signal to be rejected")
+	}
+}
+
+func TestBuiltinLanguageEvaluator(t *testing.T) {
+	cfg := config.EvaluatorConfig{Name: "chinese", Type: "builtin_language"}
+	if got := ruleScore(t, cfg, "请帮我翻译这段话"); got != 1 {
+		t.Errorf("expected 1 for Chinese, got %v", got)
+	}
+	if got := ruleScore(t, cfg, "hello, world! 123"); got != 0 {
+		t.Errorf("expected 0 for English, got %v", got)
+	}
+	if got := ruleScore(t, cfg, "用Go写"); got != 0.5 {
+		t.Errorf("expected 0.5 for mixed text, got %v", got)
+	}
+
+	cfg.Script = "Cyrillic"
+	if got := ruleScore(t, cfg, "привет"); got != 1 {
+		t.Errorf("expected 1 for Russian, got %v", got)
+	}
+	if _, err := NewBuiltinLanguageEvaluator(config.EvaluatorConfig{Script: "Klingon"}); err == nil {
+		t.Error("expected an unknown script to be rejected")
+	}
+}
+
+func TestBuiltinAttachmentsEvaluator(t *testing.T) {
+	cfg := config.EvaluatorConfig{Name: "attachments", Type: "builtin_attachments"}
+	cases := map[string]float64{
+		"see https://example.com/docs":  1,
+		"![chart](chart.png)":           1,
+		"summarize report.pdf for me":   1,
+		"data:image/png;base64,iVBORw0": 1,
+		"summarize the report for me":   0,
+	}
+	for content, want := range cases {
+		if got := ruleScore(t, cfg, content); got != want {
+			t.Errorf("%q: expected %v, got %v", content, want, got)
+		}
+	}
+
+	cfg.Signals = []string{"image"}
+	if got := ruleScore(t, cfg, "see https://example.com/docs"); got != 0 {
+		t.Errorf("expected only images to be detected, got %v", got)
+	}
+}
+
+func TestHasCodeAndImages(t *testing.T) {
+	msgs := []models.Message{{Role: "user", Content: "describe ![chart](https://x.test/chart.png)"}}
+	if HasCode(msgs) || !HasImages(msgs) {
+		t.Errorf("expected an image and no code in %q", msgs[0].Content)
+	}
+	msgs = append(msgs, models.Message{Role: "user", Content: "import os\nos.exit(1);"})
+	if !HasCode(msgs) {
+		t.Error("expected code in the conversation")
+	}
+}
+
+func TestRecentMessages_NegativeHistoryRounds(t *testing.T) {
+	msgs := []models.Message{{Role: "user", Content: "a"}, {Role: "assistant", Content: "b"}, {Role: "user", Content: "c"}}
+	if got := recentMessages(msgs, -1); len(got) != 3 {
+		t.Errorf("expected the whole conversation, got %v", got)
+	}
+	if got := recentMessages(msgs, 1); len(got) != 2 || got[0].Content != "b" {
+		t.Errorf("expected the last two messages, got %v", got)
+	}
+
+	// a rule matching only the first message still fires over the whole conversation
+	cfg := config.EvaluatorConfig{Name: "legal", Type: "builtin_keywords", HistoryRounds: -1,
+		Rules: []config.KeywordRuleConfig{{Keywords: []string{"contract"}}}}
+	if score := ruleScore(t, cfg, "review this contract", "sure", "thanks"); score != 1 {
+		t.Errorf("expected 1, got %v", score)
+	}
+}
@@ -0,0 +1,154 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
+	"agentic-llm-gateway/pkg/tokenizer"
+)
+
+// Token count scopes of BuiltinTokensEvaluator
+const (
+	ScopeLastMessage = "last_message"
+	ScopeRounds      = "rounds"
+	ScopeTotal       = "total"
+)
+
+// Output modes of BuiltinTokensEvaluator
+const (
+	OutputBinary     = "binary"
+	OutputNormalized = "normalized"
+)
+
+// BuiltinTokensEvaluator counts prompt tokens over a configurable slice of the conversation.
+// In binary mode it scores 1 when the count reaches the threshold; in normalized mode it
+// scores count/threshold, capped at 1.
+//
+// Scopes:
+//   - last_message: the latest message only
+//   - rounds: system messages plus the current round and history_rounds previous rounds,
+//     where a round starts at a user message
+//   - total: the whole conversation, system prompt included
+type BuiltinTokensEvaluator struct {
+	name          string
+	model         string
+	scope         string
+	output        string
+	threshold     int
+	historyRounds int
+	counter       *tokenizer.Counter
+}
+
+// NewBuiltinTokensEvaluator creates a token counting evaluator. counter selects the BPE
+// vocabulary for cfg.Model and may be nil to use the character estimate.
+func NewBuiltinTokensEvaluator(cfg config.EvaluatorConfig, counter *tokenizer.Counter) (*BuiltinTokensEvaluator, error) {
+	scope := cfg.Scope
+	if scope == "" {
+		scope = ScopeLastMessage
+	}
+	if scope != ScopeLastMessage && scope != ScopeRounds && scope != ScopeTotal {
+		return nil, fmt.Errorf("unknown token count scope %q", scope)
+	}
+
+	output := cfg.Output
+	if output == "" {
+		output = OutputBinary
+	}
+	if output != OutputBinary && output != OutputNormalized {
+		return nil, fmt.Errorf("unknown output mode %q", output)
+	}
+	if cfg.Threshold <= 0 {
+		return nil, fmt.Errorf("threshold must be positive")
+	}
+
+	return &BuiltinTokensEvaluator{
+		name:          cfg.Name,
+		model:         cfg.Model,
+		scope:         scope,
+		output:        output,
+		threshold:     cfg.Threshold,
+		historyRounds: cfg.HistoryRounds,
+		counter:       counter,
+	}, nil
+}
+
+func (e *BuiltinTokensEvaluator) Name() string {
+	return e.name
+}
+
+// HistoryRounds returns -1 for the total scope, which consumes the whole conversation.
+func (e *BuiltinTokensEvaluator) HistoryRounds() int {
+	switch e.scope {
+	case ScopeTotal:
+		return -1
+	case ScopeRounds:
+		return e.historyRounds
+	default:
+		return 0
+	}
+}
+
+// ConsumedMessages returns the messages counted for the configured scope.
+func (e *BuiltinTokensEvaluator) ConsumedMessages(messages []models.Message) []models.Message {
+	switch e.scope {
+	case ScopeTotal:
+		return messages
+	case ScopeRounds:
+		return lastRounds(messages, e.historyRounds+1)
+	default:
+		return recentMessages(messages, 0)
+	}
+}
+
+func (e *BuiltinTokensEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	scoped := e.ConsumedMessages(messages)
+	tokens := e.counter.CountMessages(e.model, scoped)
+	logger.Debugf("[Evaluator %s] Verbose Token Count (%s over %d messages): %d", e.name, e.scope, len(scoped), tokens)
+
+	score := 0.0
+	if e.output == OutputNormalized {
+		score = float64(tokens) / float64(e.threshold)
+		if score > 1 {
+			score = 1
+		}
+	} else if tokens >= e.threshold {
+		score = 1.0
+	}
+	logger.Debugf("[Evaluator %s] Verbose Output Score: %f", e.name, score)
+
+	return &EvaluationResult{
+		Dimension: e.name,
+		Score:     score,
+	}, nil
+}
+
+// lastRounds keeps all system messages plus the messages of the last n rounds,
+// where a round starts at a user message.
+func lastRounds(messages []models.Message, n int) []models.Message {
+	start := 0
+	seen := 0
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			seen++
+			if seen == n {
+				start = i
+				break
+			}
+		}
+	}
+
+	scoped := make([]models.Message, 0, len(messages)-start)
+	for _, m := range messages[:start] {
+		if m.Role == "system" {
+			scoped = append(scoped, m)
+		}
+	}
+	return append(scoped, messages[start:]...)
+}
@@ -0,0 +1,119 @@
+package evaluator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+func tokensConversation() []models.Message {
+	// The character estimate counts 4 overhead tokens per message, 3 for reply
+	// priming, and one token per four ASCII characters.
+	return []models.Message{
+		{Role: "system", Content: strings.Repeat("s", 400)},    // 100 tokens
+		{Role: "user", Content: strings.Repeat("a", 40)},       // 10 tokens
+		{Role: "assistant", Content: strings.Repeat("b", 80)},  // 20 tokens
+		{Role: "user", Content: strings.Repeat("c", 120)},      // 30 tokens
+		{Role: "assistant", Content: strings.Repeat("d", 160)}, // 40 tokens
+		{Role: "user", Content: strings.Repeat("e", 200)},      // 50 tokens
+	}
+}
+
+func TestBuiltinTokensEvaluator_Scopes(t *testing.T) {
+	tests := []struct {
+		name          string
+		scope         string
+		historyRounds int
+		expected      float64
+	}{
+		// 3 + 4 + 50
+		{"Last message", ScopeLastMessage, 0, 57},
+		// system + current round: 3 + 2*4 + 100 + 50
+		{"Current round keeps system prompt", ScopeRounds, 0, 161},
+		// system + last two rounds: 3 + 4*4 + 100 + 30 + 40 + 50
+		{"Two rounds", ScopeRounds, 1, 239},
+		// everything: 3 + 6*4 + 250
+		{"Total", ScopeTotal, 0, 277},
+		{"Rounds beyond history", ScopeRounds, 10, 277},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			eval, err := NewBuiltinTokensEvaluator(config.EvaluatorConfig{
+				Name:          "tokens",
+				Scope:         tc.scope,
+				Output:        OutputNormalized,
+				Threshold:     1000,
+				HistoryRounds: tc.historyRounds,
+			}, nil)
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			res, err := eval.Evaluate(context.Background(), tokensConversation())
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if got := res.Score * 1000; got < tc.expected-1e-6 || got > tc.expected+1e-6 {
+				t.Errorf("expected %v tokens, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuiltinTokensEvaluator_Binary(t *testing.T) {
+	eval, err := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "tokens", Scope: ScopeTotal, Threshold: 277}, nil)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	res, _ := eval.Evaluate(context.Background(), tokensConversation())
+	if res.Score != 1.0 || res.Dimension != "tokens" {
+		t.Errorf("expected score 1 at the threshold, got %+v", res)
+	}
+
+	eval, _ = NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "tokens", Scope: ScopeTotal, Threshold: 278}, nil)
+	res, _ = eval.Evaluate(context.Background(), tokensConversation())
+	if res.Score != 0.0 {
+		t.Errorf("expected score 0 below the threshold, got %v", res.Score)
+	}
+}
+
+func TestBuiltinTokensEvaluator_NormalizedCaps(t *testing.T) {
+	eval, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "tokens", Scope: ScopeTotal, Output: OutputNormalized, Threshold: 10}, nil)
+	res, _ := eval.Evaluate(context.Background(), tokensConversation())
+	if res.Score != 1.0 {
+		t.Errorf("expected normalized score to cap at 1, got %v", res.Score)
+	}
+}
+
+func TestBuiltinTokensEvaluator_HistoryRounds(t *testing.T) {
+	total, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Scope: ScopeTotal, Threshold: 1}, nil)
+	rounds, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Scope: ScopeRounds, HistoryRounds: 2, Threshold: 1}, nil)
+	last, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{HistoryRounds: 5, Threshold: 1}, nil)
+	if total.HistoryRounds() != -1 || rounds.HistoryRounds() != 2 || last.HistoryRounds() != 0 {
+		t.Errorf("unexpected history rounds: %d, %d, %d", total.HistoryRounds(), rounds.HistoryRounds(), last.HistoryRounds())
+	}
+}
+
+func TestNewBuiltinTokensEvaluator_InvalidConfig(t *testing.T) {
+	bad := []config.EvaluatorConfig{
+		{Scope: "everything", Threshold: 1},
+		{Output: "percent", Threshold: 1},
+		{Output: OutputNormalized},
+		{Output: OutputBinary},
+		{Output: OutputBinary, Threshold: -1},
+	}
+	for _, cfg := range bad {
+		if _, err := NewBuiltinTokensEvaluator(cfg, nil); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestBuiltinTokensEvaluator_NoMessages(t *testing.T) {
+	eval, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "tokens", Threshold: 1}, nil)
+	if _, err := eval.Evaluate(context.Background(), nil); err == nil {
+		t.Error("expected error for empty messages")
+	}
+}
@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/cache"
+)
+
+// Cache memoizes evaluation results. Entries are keyed by evaluator name and a hash of
+// the exact messages the evaluator consumes, so retries and requests resending the same
+// recent turns skip the evaluator call. Failed evaluations are not cached.
+type Cache struct {
+	lru *cache.LRU[string, EvaluationResult]
+}
+
+// NewCache creates a cache holding at most maxEntries results for ttl each.
+func NewCache(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{lru: cache.New[string, EvaluationResult](maxEntries, ttl)}
+}
+
+// Wrap returns ev with its results served from and stored in c.
+func (c *Cache) Wrap(ev Evaluator) Evaluator {
+	return &cachedEvaluator{Evaluator: ev, cache: c}
+}
+
+// Stats returns the hit, miss and eviction counters of the cache.
+func (c *Cache) Stats() cache.Stats {
+	return c.lru.Stats()
+}
+
+type cachedEvaluator struct {
+	Evaluator
+	cache *Cache
+}
+
+func (e *cachedEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	key := cacheKey(e.Name(), ConsumedMessages(e.Evaluator, messages))
+	if res, ok := e.cache.lru.Get(key); ok {
+		res.Cached = true
+		return &res, nil
+	}
+	res, err := e.Evaluator.Evaluate(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	e.cache.lru.Set(key, *res)
+	return res, nil
+}
+
+// ConsumedMessages keeps the scope of the wrapped evaluator visible.
+func (e *cachedEvaluator) ConsumedMessages(messages []models.Message) []models.Message {
+	return ConsumedMessages(e.Evaluator, messages)
+}
+
+func (e *cachedEvaluator) WaitReady(ctx context.Context) error {
+	return WaitReady(ctx, e.Evaluator)
+}
+
+func cacheKey(name string, messages []models.Message) string {
+	h := sha256.New()
+	for _, m := range messages {
+		h.Write([]byte(m.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(m.Content))
+		h.Write([]byte{0})
+	}
+	return name + ":" + hex.EncodeToString(h.Sum(nil))
+}
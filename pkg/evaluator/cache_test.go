@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+// countingStub scores 0.5 and counts its runs; it fails while fail is set.
+type countingStub struct {
+	rounds int
+	runs   int
+	fail   bool
+}
+
+func (c *countingStub) Name() string       { return "stub" }
+func (c *countingStub) HistoryRounds() int { return c.rounds }
+func (c *countingStub) Evaluate(_ context.Context, _ []models.Message) (*EvaluationResult, error) {
+	c.runs++
+	if c.fail {
+		return nil, errors.New("boom")
+	}
+	return &EvaluationResult{Dimension: "stub", Score: 0.5}, nil
+}
+
+func msgs(contents ...string) []models.Message {
+	var out []models.Message
+	for i, c := range contents {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		out = append(out, models.Message{Role: role, Content: c})
+	}
+	return out
+}
+
+func TestCache_KeyedByConsumedMessages(t *testing.T) {
+	c := NewCache(100, time.Minute)
+	stub := &countingStub{rounds: 1}
+	ev := c.Wrap(stub)
+
+	res, err := ev.Evaluate(context.Background(), msgs("a", "b", "c"))
+	if err != nil || res.Score != 0.5 || res.Cached {
+		t.Fatalf("expected a fresh result, got %+v, %v", res, err)
+	}
+	res, _ = ev.Evaluate(context.Background(), msgs("a", "b", "c"))
+	if !res.Cached || stub.runs != 1 {
+		t.Errorf("expected a retry to hit, got %+v after %d runs", res, stub.runs)
+	}
+	// Only the last message and one history round are consumed
+	if res, _ = ev.Evaluate(context.Background(), msgs("x", "b", "c")); !res.Cached || stub.runs != 1 {
+		t.Errorf("expected a different unconsumed prefix to hit, got %+v after %d runs", res, stub.runs)
+	}
+	if res, _ = ev.Evaluate(context.Background(), msgs("a", "x", "c")); res.Cached || stub.runs != 2 {
+		t.Errorf("expected a changed consumed message to miss, got %+v after %d runs", res, stub.runs)
+	}
+
+	s := c.Stats()
+	if s.Hits != 2 || s.Misses != 2 || s.Size != 2 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestCache_ErrorsNotCached(t *testing.T) {
+	c := NewCache(100, time.Minute)
+	stub := &countingStub{fail: true}
+	ev := c.Wrap(stub)
+	if _, err := ev.Evaluate(context.Background(), msgs("a")); err == nil {
+		t.Fatal("expected the error to pass through")
+	}
+	stub.fail = false
+	if res, err := ev.Evaluate(context.Background(), msgs("a")); err != nil || res.Cached || stub.runs != 2 {
+		t.Errorf("expected the failure not to be cached, got %+v, %v after %d runs", res, err, stub.runs)
+	}
+}
+
+func TestConsumedMessages(t *testing.T) {
+	conv := []models.Message{
+		{Role: "system", Content: "s"}, {Role: "user", Content: "u1"}, {Role: "assistant", Content: "a1"}, {Role: "user", Content: "u2"},
+	}
+	if got := ConsumedMessages(&countingStub{rounds: 0}, conv); len(got) != 1 || got[0].Content != "u2" {
+		t.Errorf("unexpected last message scope %v", got)
+	}
+	if got := ConsumedMessages(&countingStub{rounds: -1}, conv); len(got) != 4 {
+		t.Errorf("expected a negative history to consume everything, got %v", got)
+	}
+	tokens, _ := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "t", Scope: ScopeRounds, HistoryRounds: 0, Threshold: 1}, nil)
+	if got := ConsumedMessages(tokens, conv); len(got) != 2 || got[0].Role != "system" || got[1].Content != "u2" {
+		t.Errorf("expected the rounds scope with system messages, got %v", got)
+	}
+}
+
+func TestCache_EmptyMessages(t *testing.T) {
+	c := NewCache(100, time.Minute)
+	for _, scope := range []string{ScopeLastMessage, ScopeRounds, ScopeTotal} {
+		tokens, err := NewBuiltinTokensEvaluator(config.EvaluatorConfig{Name: "t", Scope: scope, Threshold: 1}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Wrap(tokens).Evaluate(context.Background(), nil); err == nil {
+			t.Errorf("expected empty messages to be rejected for scope %s", scope)
+		}
+	}
+	if s := c.Stats(); s.Size != 0 {
+		t.Errorf("expected nothing cached, got %+v", s)
+	}
+}
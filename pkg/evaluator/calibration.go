@@ -0,0 +1,291 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"sort"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+// Calibration methods
+const (
+	CalibrationPlatt    = "platt"
+	CalibrationIsotonic = "isotonic"
+)
+
+// Calibration maps raw evaluator scores to calibrated probabilities, so that a score of
+// 0.8 means 80% of such requests carry the label.
+type Calibration struct {
+	cfg config.CalibrationConfig
+}
+
+// NewCalibration checks cfg and returns its calibration.
+func NewCalibration(cfg config.CalibrationConfig) (*Calibration, error) {
+	switch cfg.Method {
+	case CalibrationPlatt:
+		if cfg.A == 0 && cfg.B == 0 {
+			return nil, fmt.Errorf("platt calibration without a or b")
+		}
+	case CalibrationIsotonic:
+		if len(cfg.Points) == 0 {
+			return nil, fmt.Errorf("isotonic calibration without points")
+		}
+		for i := 1; i < len(cfg.Points); i++ {
+			if cfg.Points[i].Score < cfg.Points[i-1].Score || cfg.Points[i].Probability < cfg.Points[i-1].Probability {
+				return nil, fmt.Errorf("isotonic calibration points must be non-decreasing in score and probability")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown calibration method %q", cfg.Method)
+	}
+	return &Calibration{cfg: cfg}, nil
+}
+
+// Config returns the configuration of c, e.g. to write it out after fitting.
+func (c *Calibration) Config() config.CalibrationConfig {
+	return c.cfg
+}
+
+// Apply returns the calibrated probability of the raw score.
+func (c *Calibration) Apply(score float64) float64 {
+	if c.cfg.Method == CalibrationPlatt {
+		return sigmoid(c.cfg.A*score + c.cfg.B)
+	}
+	points := c.cfg.Points
+	i := sort.Search(len(points), func(i int) bool { return points[i].Score >= score })
+	switch {
+	case i == 0:
+		return points[0].Probability
+	case i == len(points):
+		return points[len(points)-1].Probability
+	}
+	lo, hi := points[i-1], points[i]
+	if hi.Score == lo.Score {
+		return hi.Probability
+	}
+	return lo.Probability + (hi.Probability-lo.Probability)*(score-lo.Score)/(hi.Score-lo.Score)
+}
+
+// FitPlatt fits a logistic curve to scores and their labels with Newton's method, using
+// Platt's smoothed targets so that separable data does not diverge.
+func FitPlatt(scores []float64, positive []bool) *Calibration {
+	var nPos, nNeg float64
+	for _, p := range positive {
+		if p {
+			nPos++
+		} else {
+			nNeg++
+		}
+	}
+	hiTarget, loTarget := (nPos+1)/(nPos+2), 1/(nNeg+2)
+	targets := make([]float64, len(positive))
+	for i, p := range positive {
+		targets[i] = loTarget
+		if p {
+			targets[i] = hiTarget
+		}
+	}
+
+	// Negative log-likelihood of p = sigmoid(a*s + b)
+	loss := func(a, b float64) float64 {
+		sum := 0.0
+		for i, s := range scores {
+			z := a*s + b
+			// log(1 + exp(z)) - t*z, computed without overflow
+			if z >= 0 {
+				sum += z - targets[i]*z + math.Log1p(math.Exp(-z))
+			} else {
+				sum += -targets[i]*z + math.Log1p(math.Exp(z))
+			}
+		}
+		return sum
+	}
+
+	a, b := 0.0, math.Log((nPos+1)/(nNeg+1))
+	fval := loss(a, b)
+	for iter := 0; iter < 100; iter++ {
+		h11, h22, h21 := 1e-12, 1e-12, 0.0
+		g1, g2 := 0.0, 0.0
+		for i, s := range scores {
+			p := sigmoid(a*s + b)
+			d2 := p * (1 - p)
+			h11 += s * s * d2
+			h22 += d2
+			h21 += s * d2
+			d1 := p - targets[i]
+			g1 += s * d1
+			g2 += d1
+		}
+		if math.Abs(g1) < 1e-5 && math.Abs(g2) < 1e-5 {
+			break
+		}
+		det := h11*h22 - h21*h21
+		da := -(h22*g1 - h21*g2) / det
+		db := -(h11*g2 - h21*g1) / det
+		gd := g1*da + g2*db
+
+		// Backtracking line search
+		step := 1.0
+		for ; step >= 1e-10; step /= 2 {
+			na, nb := a+step*da, b+step*db
+			if nf := loss(na, nb); nf < fval+1e-4*step*gd {
+				a, b, fval = na, nb, nf
+				break
+			}
+		}
+		if step < 1e-10 {
+			break
+		}
+	}
+	return &Calibration{cfg: config.CalibrationConfig{Method: CalibrationPlatt, A: a, B: b}}
+}
+
+// FitIsotonic fits the non-decreasing step function closest to the labels with the pool
+// adjacent violators algorithm. Each pooled block contributes its lowest and highest
+// score as points.
+func FitIsotonic(scores []float64, positive []bool) *Calibration {
+	type block struct {
+		lo, hi   float64 // score range
+		sum, num float64 // positives and examples
+	}
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] < scores[order[j]] })
+
+	var blocks []block
+	for _, i := range order {
+		y := 0.0
+		if positive[i] {
+			y = 1
+		}
+		// Equal scores must share a probability
+		if n := len(blocks); n > 0 && blocks[n-1].hi == scores[i] {
+			blocks[n-1].sum += y
+			blocks[n-1].num++
+		} else {
+			blocks = append(blocks, block{lo: scores[i], hi: scores[i], sum: y, num: 1})
+		}
+		for n := len(blocks); n > 1 && blocks[n-2].sum/blocks[n-2].num >= blocks[n-1].sum/blocks[n-1].num; n = len(blocks) {
+			prev, last := blocks[n-2], blocks[n-1]
+			blocks = append(blocks[:n-2], block{lo: prev.lo, hi: last.hi, sum: prev.sum + last.sum, num: prev.num + last.num})
+		}
+	}
+
+	var points []config.CalibrationPoint
+	for _, b := range blocks {
+		p := b.sum / b.num
+		points = append(points, config.CalibrationPoint{Score: b.lo, Probability: p})
+		if b.hi != b.lo {
+			points = append(points, config.CalibrationPoint{Score: b.hi, Probability: p})
+		}
+	}
+	return &Calibration{cfg: config.CalibrationConfig{Method: CalibrationIsotonic, Points: points}}
+}
+
+// ThresholdSuggestion is the lowest threshold reaching a precision target, which
+// maximizes recall among the thresholds that do.
+type ThresholdSuggestion struct {
+	Threshold float64
+	Precision float64
+	Recall    float64
+}
+
+// SuggestThreshold returns the threshold t for a rule "score >= t" whose precision on
+// the labeled scores reaches target with the highest recall. ok is false when no
+// threshold does.
+func SuggestThreshold(scores []float64, positive []bool, target float64) (s ThresholdSuggestion, ok bool) {
+	total := 0
+	for _, p := range positive {
+		if p {
+			total++
+		}
+	}
+	if total == 0 {
+		return s, false
+	}
+	order := make([]int, len(scores))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+
+	tp, predicted := 0, 0
+	for k, i := range order {
+		predicted++
+		if positive[i] {
+			tp++
+		}
+		// Only thresholds between distinct scores are realizable
+		if k+1 < len(order) && scores[order[k+1]] == scores[i] {
+			continue
+		}
+		if precision := float64(tp) / float64(predicted); precision >= target {
+			s, ok = ThresholdSuggestion{Threshold: scores[i], Precision: precision, Recall: float64(tp) / float64(total)}, true
+		}
+	}
+	return s, ok
+}
+
+// BrierScore is the mean squared difference between scores and labels; lower is better.
+func BrierScore(scores []float64, positive []bool) float64 {
+	if len(scores) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for i, s := range scores {
+		y := 0.0
+		if positive[i] {
+			y = 1
+		}
+		sum += (s - y) * (s - y)
+	}
+	return sum / float64(len(scores))
+}
+
+// Calibrate returns ev with its score mapped through c. Evaluators reporting
+// Dimensions only have the one named after them calibrated.
+func Calibrate(ev Evaluator, c *Calibration) Evaluator {
+	return &calibratedEvaluator{Evaluator: ev, calibration: c}
+}
+
+type calibratedEvaluator struct {
+	Evaluator
+	calibration *Calibration
+}
+
+func (e *calibratedEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	res, err := e.Evaluator.Evaluate(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	calibrated := *res
+	calibrated.Score = e.calibration.Apply(res.Score)
+	if raw, ok := res.Dimensions[e.Name()]; ok {
+		calibrated.Dimensions = maps.Clone(res.Dimensions)
+		calibrated.Dimensions[e.Name()] = e.calibration.Apply(raw)
+	}
+	return &calibrated, nil
+}
+
+// ConsumedMessages keeps the scope of the wrapped evaluator visible.
+func (e *calibratedEvaluator) ConsumedMessages(messages []models.Message) []models.Message {
+	return ConsumedMessages(e.Evaluator, messages)
+}
+
+func (e *calibratedEvaluator) WaitReady(ctx context.Context) error {
+	return WaitReady(ctx, e.Evaluator)
+}
+
+func sigmoid(z float64) float64 {
+	if z >= 0 {
+		return 1 / (1 + math.Exp(-z))
+	}
+	ez := math.Exp(z)
+	return ez / (1 + ez)
+}
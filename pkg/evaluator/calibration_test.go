@@ -0,0 +1,129 @@
+package evaluator
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+// overconfident labels: a raw 0.9 is right 60% of the time, a raw 0.1 is 20% positive
+func overconfident() ([]float64, []bool) {
+	var scores []float64
+	var positive []bool
+	for i := 0; i < 10; i++ {
+		scores = append(scores, 0.9, 0.1)
+		positive = append(positive, i < 6, i < 2)
+	}
+	return scores, positive
+}
+
+func TestFitPlatt(t *testing.T) {
+	scores, positive := overconfident()
+	c := FitPlatt(scores, positive)
+	// Platt's smoothed targets pull the fit slightly towards 0.5
+	if p := c.Apply(0.9); math.Abs(p-0.6) > 0.05 {
+		t.Errorf("expected P(0.9) near 0.6, got %v", p)
+	}
+	if p := c.Apply(0.1); math.Abs(p-0.2) > 0.05 {
+		t.Errorf("expected P(0.1) near 0.2, got %v", p)
+	}
+	calibrated := make([]float64, len(scores))
+	for i, s := range scores {
+		calibrated[i] = c.Apply(s)
+	}
+	if BrierScore(calibrated, positive) >= BrierScore(scores, positive) {
+		t.Error("expected calibration to lower the Brier score")
+	}
+}
+
+func TestFitIsotonic(t *testing.T) {
+	scores := []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}
+	positive := []bool{false, true, false, true, true, true}
+	c := FitIsotonic(scores, positive)
+	want := []config.CalibrationPoint{
+		{Score: 0.1, Probability: 0},
+		{Score: 0.2, Probability: 0.5},
+		{Score: 0.3, Probability: 0.5},
+		{Score: 0.4, Probability: 1},
+		{Score: 0.6, Probability: 1},
+	}
+	got := c.Config().Points
+	if len(got) != len(want) {
+		t.Fatalf("points = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("points = %v, want %v", got, want)
+			break
+		}
+	}
+	for score, p := range map[float64]float64{0: 0, 0.15: 0.25, 0.25: 0.5, 0.35: 0.75, 0.9: 1} {
+		if got := c.Apply(score); math.Abs(got-p) > 1e-9 {
+			t.Errorf("Apply(%v) = %v, want %v", score, got, p)
+		}
+	}
+	if _, err := NewCalibration(c.Config()); err != nil {
+		t.Errorf("expected the fitted points to be valid: %v", err)
+	}
+}
+
+func TestSuggestThreshold(t *testing.T) {
+	scores := []float64{0.95, 0.9, 0.8, 0.8, 0.6, 0.3}
+	positive := []bool{true, true, true, false, true, false}
+	s, ok := SuggestThreshold(scores, positive, 0.75)
+	// 0.8 includes both tied scores: 3 of 4 right; 0.6 gives 4 of 5
+	if !ok || s.Threshold != 0.6 || s.Precision != 0.8 || s.Recall != 1 {
+		t.Errorf("unexpected suggestion %+v (ok %v)", s, ok)
+	}
+	s, ok = SuggestThreshold(scores, positive, 0.9)
+	if !ok || s.Threshold != 0.9 || s.Recall != 0.5 {
+		t.Errorf("unexpected suggestion %+v (ok %v)", s, ok)
+	}
+	if _, ok := SuggestThreshold([]float64{0.9}, []bool{false}, 0.5); ok {
+		t.Error("expected no threshold without positives")
+	}
+}
+
+func TestNewCalibration_Invalid(t *testing.T) {
+	for _, cfg := range []config.CalibrationConfig{
+		{Method: "magic"},
+		{Method: CalibrationPlatt},
+		{Method: CalibrationIsotonic},
+		{Method: CalibrationIsotonic, Points: []config.CalibrationPoint{{Score: 0.5, Probability: 0.9}, {Score: 0.6, Probability: 0.1}}},
+	} {
+		if _, err := NewCalibration(cfg); err == nil {
+			t.Errorf("%+v: expected error", cfg)
+		}
+	}
+}
+
+func TestNew_AppliesCalibration(t *testing.T) {
+	ev, err := New(Config{
+		Name: "long", Type: "builtin", Threshold: 3,
+		Calibration: &config.CalibrationConfig{Method: CalibrationPlatt, A: 2, B: -1},
+	}, Deps{})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "long enough"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if want := 1 / (1 + math.Exp(-1)); math.Abs(res.Score-want) > 1e-9 {
+		t.Errorf("expected the raw 1 calibrated to %v, got %v", want, res.Score)
+	}
+
+	platt := &config.CalibrationConfig{Method: CalibrationPlatt, A: 2, B: -1}
+	for _, cfg := range []Config{
+		{Name: "intent", Type: "llm_structured_api", Endpoint: "http://localhost", Schema: structuredSchema, Calibration: platt},
+		{Name: "difficulty", Type: "llm_logprob_api", Endpoint: "http://localhost", Labels: []config.LogprobLabelConfig{{Label: "easy"}, {Label: "hard"}}, Calibration: platt},
+	} {
+		if _, err := New(cfg, Deps{}); err == nil || !strings.Contains(err.Error(), "single score") {
+			t.Errorf("%s: expected calibration of a multi-dimension evaluator to be rejected, got %v", cfg.Type, err)
+		}
+	}
+}
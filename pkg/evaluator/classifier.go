@@ -0,0 +1,199 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"unicode"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+// classifierVersion is the version of the model file format.
+const classifierVersion = 1
+
+// Defaults of TrainOptions
+const (
+	DefaultClassifierBits   = 16
+	DefaultClassifierNGrams = 2
+	DefaultClassifierEpochs = 20
+	DefaultClassifierRate   = 0.5
+)
+
+// ClassifierModel is a logistic regression over hashed word n-gram features, as saved
+// to and loaded from a model file.
+type ClassifierModel struct {
+	Version int       `json:"version"`
+	Bits    int       `json:"bits"`   // 2^Bits feature buckets
+	NGrams  int       `json:"ngrams"` // word n-grams of length 1 to NGrams
+	Bias    float64   `json:"bias"`
+	Weights []float64 `json:"weights"`
+}
+
+// TrainOptions tunes TrainClassifier. Zero fields take the defaults.
+type TrainOptions struct {
+	Bits         int
+	NGrams       int
+	Epochs       int
+	LearningRate float64
+	L2           float64
+	Seed         int64
+}
+
+// TrainClassifier fits a logistic regression to examples with stochastic gradient descent.
+// Example scores are the targets, so soft labels between 0 and 1 are allowed.
+func TrainClassifier(examples []LabeledExample, opts TrainOptions) (*ClassifierModel, error) {
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("no examples")
+	}
+	if opts.Bits == 0 {
+		opts.Bits = DefaultClassifierBits
+	}
+	if opts.Bits < 4 || opts.Bits > 24 {
+		return nil, fmt.Errorf("bits must be between 4 and 24")
+	}
+	if opts.NGrams == 0 {
+		opts.NGrams = DefaultClassifierNGrams
+	}
+	if opts.Epochs == 0 {
+		opts.Epochs = DefaultClassifierEpochs
+	}
+	if opts.LearningRate == 0 {
+		opts.LearningRate = DefaultClassifierRate
+	}
+
+	m := &ClassifierModel{
+		Version: classifierVersion,
+		Bits:    opts.Bits,
+		NGrams:  opts.NGrams,
+		Weights: make([]float64, 1<<opts.Bits),
+	}
+	features := make([][]int, len(examples))
+	for i, ex := range examples {
+		if ex.Score < 0 || ex.Score > 1 {
+			return nil, fmt.Errorf("example %d: score %v outside [0, 1]", i+1, ex.Score)
+		}
+		features[i] = m.features(ex.Text)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	order := rng.Perm(len(examples))
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		rate := opts.LearningRate / (1 + float64(epoch)*0.1)
+		for _, i := range order {
+			grad := examples[i].Score - m.predict(features[i])
+			step := rate * grad / math.Sqrt(float64(len(features[i])+1))
+			m.Bias += step
+			for _, f := range features[i] {
+				m.Weights[f] += step - rate*opts.L2*m.Weights[f]
+			}
+		}
+	}
+	return m, nil
+}
+
+// LoadClassifier reads a model file written by Save.
+func LoadClassifier(path string) (*ClassifierModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read classifier model: %w", err)
+	}
+	var m ClassifierModel
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode classifier model: %w", err)
+	}
+	if m.Version != classifierVersion {
+		return nil, fmt.Errorf("unsupported classifier model version %d", m.Version)
+	}
+	if m.NGrams < 1 || m.Bits < 4 || m.Bits > 24 || len(m.Weights) != 1<<m.Bits {
+		return nil, fmt.Errorf("corrupt classifier model: %d weights for %d bits", len(m.Weights), m.Bits)
+	}
+	return &m, nil
+}
+
+// Save writes the model to path.
+func (m *ClassifierModel) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Predict returns the probability that text belongs to the positive class.
+func (m *ClassifierModel) Predict(text string) float64 {
+	return m.predict(m.features(text))
+}
+
+func (m *ClassifierModel) predict(features []int) float64 {
+	z := m.Bias
+	scale := 1 / math.Sqrt(float64(len(features)+1))
+	for _, f := range features {
+		z += m.Weights[f] * scale
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// features returns the bucket of every word n-gram of text. Words are lowercased runs
+// of letters and digits.
+func (m *ClassifierModel) features(text string) []int {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	mask := uint32(1)<<m.Bits - 1
+	var features []int
+	h := fnv.New32a()
+	for n := 1; n <= m.NGrams; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			h.Reset()
+			fmt.Fprintf(h, "%d:%s", n, strings.Join(words[i:i+n], " "))
+			features = append(features, int(h.Sum32()&mask))
+		}
+	}
+	return features
+}
+
+// ClassifierEvaluator scores a conversation in-process with a trained ClassifierModel.
+// It needs no network and answers in microseconds.
+type ClassifierEvaluator struct {
+	name          string
+	historyRounds int
+	model         *ClassifierModel
+}
+
+// NewClassifierEvaluator loads the model file of cfg.
+func NewClassifierEvaluator(cfg config.EvaluatorConfig) (*ClassifierEvaluator, error) {
+	if cfg.ModelPath == "" {
+		return nil, fmt.Errorf("model_path is required")
+	}
+	m, err := LoadClassifier(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ClassifierEvaluator{name: cfg.Name, historyRounds: cfg.HistoryRounds, model: m}, nil
+}
+
+func (e *ClassifierEvaluator) Name() string {
+	return e.name
+}
+
+func (e *ClassifierEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+func (e *ClassifierEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+	return &EvaluationResult{
+		Dimension: e.name,
+		Score:     e.model.Predict(conversationText(messages, e.historyRounds)),
+	}, nil
+}
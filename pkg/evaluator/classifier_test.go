@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+var classifierExamples = []LabeledExample{
+	{Text: "write a function that parses json", Score: 1},
+	{Text: "fix the bug in my python code", Score: 1},
+	{Text: "refactor this go function", Score: 1},
+	{Text: "why does my code panic", Score: 1},
+	{Text: "what is the capital of france", Score: 0},
+	{Text: "tell me a joke", Score: 0},
+	{Text: "how is the weather today", Score: 0},
+	{Text: "recommend a good book", Score: 0},
+}
+
+func TestTrainClassifier(t *testing.T) {
+	m, err := TrainClassifier(classifierExamples, TrainOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ex := range classifierExamples {
+		if p := m.Predict(ex.Text); (p >= 0.5) != (ex.Score >= 0.5) {
+			t.Errorf("%q: expected score %v, predicted %.3f", ex.Text, ex.Score, p)
+		}
+	}
+	if p := m.Predict("please fix my function"); p < 0.5 {
+		t.Errorf("expected an unseen coding prompt to score high, got %.3f", p)
+	}
+
+	if _, err := TrainClassifier(nil, TrainOptions{}); err == nil {
+		t.Error("expected an empty dataset to be rejected")
+	}
+	if _, err := TrainClassifier([]LabeledExample{{Text: "x", Score: 2}}, TrainOptions{}); err == nil {
+		t.Error("expected a score outside [0, 1] to be rejected")
+	}
+}
+
+func TestClassifierEvaluator(t *testing.T) {
+	m, err := TrainClassifier(classifierExamples, TrainOptions{Bits: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	ev, err := New(config.EvaluatorConfig{Name: "coding", Type: "classifier", ModelPath: path}, Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "fix the bug in my python code"}})
+	if err != nil || res.Dimension != "coding" || res.Score < 0.5 {
+		t.Errorf("expected a high coding score, got %+v, %v", res, err)
+	}
+
+	// a negative history_rounds classifies the whole conversation instead of panicking
+	ev, err = New(config.EvaluatorConfig{Name: "coding", Type: "classifier", ModelPath: path, HistoryRounds: -1}, Deps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "hi"}, {Role: "user", Content: "fix my code"}}); err != nil {
+		t.Errorf("unexpected err: %v", err)
+	}
+
+	if _, err := NewClassifierEvaluator(config.EvaluatorConfig{Name: "coding"}); err == nil {
+		t.Error("expected a missing model_path to be rejected")
+	}
+	if _, err := NewClassifierEvaluator(config.EvaluatorConfig{Name: "coding", ModelPath: filepath.Join(t.TempDir(), "none.json")}); err == nil {
+		t.Error("expected a missing model file to be rejected")
+	}
+}
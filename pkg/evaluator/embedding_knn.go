@@ -0,0 +1,419 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+const (
+	defaultKNN          = 5
+	embeddingBatchSize  = 64
+	embeddingBuildLimit = 5 * time.Minute
+)
+
+// EmbeddingKNNEvaluator scores a conversation by its nearest labeled examples in
+// embedding space. The examples are embedded once, in the background, and cached on
+// disk; at request time only the conversation is embedded. The score is the
+// similarity-weighted mean of the scores of the k most similar examples.
+//
+// Examples are rendered like the conversation, with up to history_rounds earlier
+// messages, so with history_rounds > 0 they should be given as messages: an example
+// given as text is compared as a single message.
+type EmbeddingKNNEvaluator struct {
+	name          string
+	endpoint      string
+	model         string
+	protocol      string // "ollama" or "openai"
+	historyRounds int
+	k             int
+	examples      []LabeledExample
+	cachePath     string
+	client        *http.Client
+
+	mu         sync.Mutex
+	embeddings [][]float64   // of examples, by index; nil until embedded
+	building   chan struct{} // closed when the running build ends, nil when none runs
+	buildErr   error         // of the last build
+}
+
+// embeddingCache is the on-disk cache of example embeddings, keyed by text hash. It is
+// discarded when the embedding model changes.
+type embeddingCache struct {
+	Model      string               `json:"model"`
+	Embeddings map[string][]float64 `json:"embeddings"`
+}
+
+// NewEmbeddingKNNEvaluator loads the examples of cfg and starts embedding those missing
+// from the cache, which is rewritten when anything was added. Evaluations fail until the
+// examples are embedded; see WaitReady.
+func NewEmbeddingKNNEvaluator(cfg config.EvaluatorConfig) (*EmbeddingKNNEvaluator, error) {
+	if cfg.Endpoint == "" || cfg.Model == "" {
+		return nil, fmt.Errorf("endpoint and model are required")
+	}
+	if cfg.ExamplesPath == "" {
+		return nil, fmt.Errorf("examples_path is required")
+	}
+
+	timeout := 10 * time.Second
+	if cfg.TimeoutMs > 0 {
+		timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = "ollama"
+	}
+	if protocol != "ollama" && protocol != "openai" {
+		return nil, fmt.Errorf("unknown protocol %q", protocol)
+	}
+	k := cfg.K
+	if k <= 0 {
+		k = defaultKNN
+	}
+
+	e := &EmbeddingKNNEvaluator{
+		name:          cfg.Name,
+		endpoint:      cfg.Endpoint,
+		model:         cfg.Model,
+		protocol:      protocol,
+		historyRounds: cfg.HistoryRounds,
+		k:             k,
+		client:        &http.Client{Timeout: timeout},
+	}
+
+	examples, err := LoadExamples(cfg.ExamplesPath)
+	if err != nil {
+		return nil, err
+	}
+	e.examples = examples
+	e.cachePath = cfg.CachePath
+	if e.cachePath == "" {
+		e.cachePath = cfg.ExamplesPath + ".embeddings.json"
+	}
+	e.prepare()
+	return e, nil
+}
+
+func (e *EmbeddingKNNEvaluator) Name() string {
+	return e.name
+}
+
+func (e *EmbeddingKNNEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+// WaitReady blocks until the examples are embedded or ctx is done. It returns the error
+// of a failed build, which the next evaluation retries.
+func (e *EmbeddingKNNEvaluator) WaitReady(ctx context.Context) error {
+	embeddings, done, _ := e.prepare()
+	if embeddings != nil {
+		return nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.buildErr
+}
+
+func (e *EmbeddingKNNEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+	embeddings, _, buildErr := e.prepare()
+	if embeddings == nil {
+		if buildErr != nil {
+			return nil, fmt.Errorf("examples not embedded yet, last attempt failed: %w", buildErr)
+		}
+		return nil, fmt.Errorf("examples not embedded yet")
+	}
+	vectors, err := e.embed(ctx, []string{conversationText(messages, e.historyRounds)})
+	if err != nil {
+		return nil, err
+	}
+	score := e.score(vectors[0], embeddings)
+	logger.Debugf("[Evaluator %s] k-NN score %.3f over %d examples", e.name, score, len(e.examples))
+	return &EvaluationResult{
+		Dimension: e.name,
+		Score:     score,
+	}, nil
+}
+
+// score returns the similarity-weighted mean score of the k examples most similar to
+// vector. Negative similarities carry no weight; when no neighbour has any, their scores
+// are averaged plainly.
+func (e *EmbeddingKNNEvaluator) score(vector []float64, embeddings [][]float64) float64 {
+	type neighbour struct {
+		similarity float64
+		score      float64
+	}
+	neighbours := make([]neighbour, len(e.examples))
+	for i, ex := range e.examples {
+		neighbours[i] = neighbour{cosineSimilarity(vector, embeddings[i]), ex.Score}
+	}
+	sort.Slice(neighbours, func(i, j int) bool { return neighbours[i].similarity > neighbours[j].similarity })
+	if len(neighbours) > e.k {
+		neighbours = neighbours[:e.k]
+	}
+
+	var weighted, weights, plain float64
+	for _, n := range neighbours {
+		plain += n.score
+		if n.similarity > 0 {
+			weighted += n.similarity * n.score
+			weights += n.similarity
+		}
+	}
+	if weights == 0 {
+		return plain / float64(len(neighbours))
+	}
+	return weighted / weights
+}
+
+// prepare returns the example embeddings once they are built. Until then it makes sure
+// a build runs in the background and returns a channel closed when it ends, with the
+// error of the previous build.
+func (e *EmbeddingKNNEvaluator) prepare() ([][]float64, <-chan struct{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.embeddings != nil {
+		return e.embeddings, nil, nil
+	}
+	if e.building == nil {
+		done := make(chan struct{})
+		e.building = done
+		go func() {
+			embeddings, err := e.embedExamples()
+			if err != nil {
+				logger.Warnf("[Evaluator %s] %v", e.name, err)
+			}
+			e.mu.Lock()
+			e.embeddings, e.buildErr, e.building = embeddings, err, nil
+			e.mu.Unlock()
+			close(done)
+		}()
+	}
+	return nil, e.building, e.buildErr
+}
+
+// embedExamples returns the embedding of every example, from the cache or from the
+// endpoint.
+func (e *EmbeddingKNNEvaluator) embedExamples() ([][]float64, error) {
+	cache := loadEmbeddingCache(e.cachePath, e.model)
+
+	texts := make([]string, len(e.examples))
+	var missing []string
+	for i, ex := range e.examples {
+		texts[i] = conversationText(ex.Conversation(), e.historyRounds)
+		if _, ok := cache.Embeddings[textHash(texts[i])]; !ok {
+			missing = append(missing, texts[i])
+		}
+	}
+	if len(missing) > 0 {
+		logger.Infof("[Evaluator %s] Embedding %d of %d examples", e.name, len(missing), len(e.examples))
+		ctx, cancel := context.WithTimeout(context.Background(), embeddingBuildLimit)
+		defer cancel()
+		for start := 0; start < len(missing); start += embeddingBatchSize {
+			batch := missing[start:min(start+embeddingBatchSize, len(missing))]
+			vectors, err := e.embed(ctx, batch)
+			if err != nil {
+				return nil, fmt.Errorf("failed to embed examples: %w", err)
+			}
+			for i, text := range batch {
+				cache.Embeddings[textHash(text)] = vectors[i]
+			}
+		}
+		if err := saveEmbeddingCache(e.cachePath, cache); err != nil {
+			logger.Warnf("[Evaluator %s] Failed to write embedding cache %s: %v", e.name, e.cachePath, err)
+		}
+	}
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embeddings[i] = cache.Embeddings[textHash(text)]
+	}
+	return embeddings, nil
+}
+
+// embed returns the embeddings of texts, in order.
+func (e *EmbeddingKNNEvaluator) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	reqBytes, err := json.Marshal(map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var vectors [][]float64
+	if e.protocol == "ollama" {
+		vectors, err = parseOllamaEmbeddings(bodyBytes)
+	} else {
+		vectors, err = parseOpenAIEmbeddings(bodyBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(vectors))
+	}
+	return vectors, nil
+}
+
+// parseOllamaEmbeddings extracts the vectors of an Ollama /api/embed response
+func parseOllamaEmbeddings(body []byte) ([][]float64, error) {
+	var ollamaResp struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+	}
+	return ollamaResp.Embeddings, nil
+}
+
+// parseOpenAIEmbeddings extracts the vectors of an OpenAI /v1/embeddings response
+func parseOpenAIEmbeddings(body []byte) ([][]float64, error) {
+	var openAIResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAI response: %w", err)
+	}
+	vectors := make([][]float64, len(openAIResp.Data))
+	for _, d := range openAIResp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// conversationText renders the current message preceded by up to historyRounds earlier
+// messages, one "role: content" line each, like the History of the LLM evaluators.
+func conversationText(messages []models.Message, historyRounds int) string {
+	history, current := historyAndCurrent(messages, historyRounds)
+	return history + current
+}
+
+// historyAndCurrent splits the messages read for historyRounds into the History of the
+// LLM evaluator prompts, one "role: content" line each, and the current message.
+func historyAndCurrent(messages []models.Message, historyRounds int) (history, current string) {
+	recent := recentMessages(messages, historyRounds)
+	if len(recent) == 0 {
+		return "", ""
+	}
+	var b strings.Builder
+	for _, m := range recent[:len(recent)-1] {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+	}
+	return b.String(), recent[len(recent)-1].Content
+}
+
+// loadEmbeddingCache reads the cache at path. A missing or unreadable cache, or one
+// built with another model, yields an empty cache.
+func loadEmbeddingCache(path, model string) *embeddingCache {
+	empty := &embeddingCache{Model: model, Embeddings: make(map[string][]float64)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("[Evaluator] Ignoring embedding cache %s: %v", path, err)
+		}
+		return empty
+	}
+	var cache embeddingCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		logger.Warnf("[Evaluator] Ignoring embedding cache %s: %v", path, err)
+		return empty
+	}
+	if cache.Model != model || cache.Embeddings == nil {
+		return empty
+	}
+	return &cache
+}
+
+// saveEmbeddingCache writes cache to path through a temporary file, so that a crash never
+// leaves a truncated cache behind.
+func saveEmbeddingCache(path string, cache *embeddingCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,247 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+// keywordEmbeddings embeds a text as its counts of "code" and "weather", answering in
+// the Ollama or OpenAI format, and counts the embedded texts.
+func keywordEmbeddings(t *testing.T, protocol string, embedded *atomic.Int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Model != "embedder" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		embedded.Add(int32(len(req.Input)))
+		var vectors [][]float64
+		for _, text := range req.Input {
+			text = strings.ToLower(text)
+			vectors = append(vectors, []float64{float64(strings.Count(text, "code")), float64(strings.Count(text, "weather")), 0.1})
+		}
+		if protocol == "ollama" {
+			json.NewEncoder(w).Encode(map[string]any{"embeddings": vectors})
+			return
+		}
+		var data []map[string]any
+		for i := len(vectors) - 1; i >= 0; i-- { // out of order on purpose
+			data = append(data, map[string]any{"index": i, "embedding": vectors[i]})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+}
+
+func writeKNNExamples(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "examples.jsonl")
+	lines := `{"text": "review this code", "score": 1}
+{"text": "refactor the code please", "score": 1}
+
+{"text": "what's the weather", "score": 0}
+{"text": "weather tomorrow?", "score": 0}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEmbeddingKNNEvaluator_Score(t *testing.T) {
+	for _, protocol := range []string{"ollama", "openai"} {
+		var embedded atomic.Int32
+		srv := keywordEmbeddings(t, protocol, &embedded)
+		defer srv.Close()
+
+		ev, err := NewEmbeddingKNNEvaluator(config.EvaluatorConfig{
+			Name: "coding", Type: "embedding_knn", Protocol: protocol, Endpoint: srv.URL,
+			Model: "embedder", ExamplesPath: writeKNNExamples(t), K: 2,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", protocol, err)
+		}
+		if err := ev.WaitReady(context.Background()); err != nil {
+			t.Fatalf("%s: %v", protocol, err)
+		}
+		res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "can you check my code"}})
+		if err != nil || res.Dimension != "coding" || res.Score < 0.99 {
+			t.Errorf("%s: expected a coding score near 1, got %+v, %v", protocol, res, err)
+		}
+		res, err = ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "is the weather nice"}})
+		if err != nil || res.Score > 0.01 {
+			t.Errorf("%s: expected a coding score near 0, got %+v, %v", protocol, res, err)
+		}
+	}
+}
+
+func TestEmbeddingKNNEvaluator_DiskCache(t *testing.T) {
+	var embedded atomic.Int32
+	srv := keywordEmbeddings(t, "ollama", &embedded)
+	defer srv.Close()
+
+	cfg := config.EvaluatorConfig{Name: "coding", Endpoint: srv.URL, Model: "embedder", ExamplesPath: writeKNNExamples(t)}
+	ev, err := NewEmbeddingKNNEvaluator(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ev.WaitReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if embedded.Load() != 4 {
+		t.Fatalf("expected the 4 examples to be embedded, got %d", embedded.Load())
+	}
+	if _, err := os.Stat(cfg.ExamplesPath + ".embeddings.json"); err != nil {
+		t.Fatalf("expected the cache to be written: %v", err)
+	}
+
+	f, _ := os.OpenFile(cfg.ExamplesPath, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"text": "debug this code", "score": 0.8}` + "\n")
+	f.Close()
+	if ev, err = NewEmbeddingKNNEvaluator(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := ev.WaitReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if embedded.Load() != 5 {
+		t.Errorf("expected only the new example to be embedded, got %d embeddings in total", embedded.Load())
+	}
+}
+
+func TestEmbeddingKNNEvaluator_EmbedsInBackground(t *testing.T) {
+	var embedded atomic.Int32
+	var down atomic.Bool
+	down.Store(true)
+	release := make(chan struct{})
+	backend := keywordEmbeddings(t, "ollama", &embedded)
+	defer backend.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			<-release
+			http.Error(w, "loading model", http.StatusServiceUnavailable)
+			return
+		}
+		resp, err := http.Post(backend.URL, "application/json", r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer srv.Close()
+
+	// The constructor does not wait for the hanging endpoint.
+	ev, err := NewEmbeddingKNNEvaluator(config.EvaluatorConfig{Name: "coding", Endpoint: srv.URL, Model: "embedder", ExamplesPath: writeKNNExamples(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []models.Message{{Role: "user", Content: "check my code"}}
+	if _, err := ev.Evaluate(context.Background(), msgs); err == nil {
+		t.Error("expected an error before the examples are embedded")
+	}
+
+	// A failed build is reported and retried by the next evaluation.
+	close(release)
+	if err := ev.WaitReady(context.Background()); err == nil {
+		t.Error("expected the failed build to be reported")
+	}
+	down.Store(false)
+	if _, err := ev.Evaluate(context.Background(), msgs); err == nil {
+		t.Error("expected an error while the examples are re-embedded")
+	}
+	if err := ev.WaitReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := ev.Evaluate(context.Background(), msgs); err != nil || res.Score < 0.99 {
+		t.Errorf("expected a coding score near 1, got %+v, %v", res, err)
+	}
+}
+
+func TestEmbeddingKNNEvaluator_ExamplesWithHistory(t *testing.T) {
+	var embedded atomic.Int32
+	srv := keywordEmbeddings(t, "ollama", &embedded)
+	defer srv.Close()
+
+	// The examples are only told apart by the message before the last one, which is
+	// embedded like the history of the conversation.
+	path := filepath.Join(t.TempDir(), "examples.jsonl")
+	lines := `{"messages": [{"role": "user", "content": "my code crashes"}, {"role": "user", "content": "any idea?"}], "score": 1}
+{"messages": [{"role": "user", "content": "rain and weather"}, {"role": "user", "content": "any idea?"}], "score": 0}
+`
+	if err := os.WriteFile(path, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ev, err := NewEmbeddingKNNEvaluator(config.EvaluatorConfig{Name: "coding", Endpoint: srv.URL, Model: "embedder", ExamplesPath: path, K: 1, HistoryRounds: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WaitReady(context.Background(), ev); err != nil {
+		t.Fatal(err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "this code fails"}, {Role: "user", Content: "thoughts?"}})
+	if err != nil || res.Score != 1 {
+		t.Errorf("expected the example with code history, got %+v, %v", res, err)
+	}
+}
+
+func TestEmbeddingKNNEvaluator_Config(t *testing.T) {
+	path := writeKNNExamples(t)
+	bad := []config.EvaluatorConfig{
+		{Name: "x", Model: "embedder", ExamplesPath: path},
+		{Name: "x", Endpoint: "http://127.0.0.1:1", Model: "embedder"},
+		{Name: "x", Endpoint: "http://127.0.0.1:1", Model: "embedder", ExamplesPath: path, Protocol: "grpc"},
+		{Name: "x", Endpoint: "http://127.0.0.1:1", Model: "embedder", ExamplesPath: filepath.Join(t.TempDir(), "missing.jsonl")},
+	}
+	for i, cfg := range bad {
+		if _, err := NewEmbeddingKNNEvaluator(cfg); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+func TestConversationText(t *testing.T) {
+	msgs := []models.Message{{Role: "user", Content: "a"}, {Role: "assistant", Content: "b"}, {Role: "user", Content: "c"}}
+	if got := conversationText(msgs, 1); got != "assistant: b\nc" {
+		t.Errorf("unexpected text %q", got)
+	}
+	if got := conversationText(msgs, 0); got != "c" {
+		t.Errorf("unexpected text %q", got)
+	}
+	// a negative history_rounds reads the whole conversation
+	if got := conversationText(msgs, -1); got != "user: a\nassistant: b\nc" {
+		t.Errorf("unexpected text %q", got)
+	}
+	if got := conversationText(nil, -1); got != "" {
+		t.Errorf("unexpected text %q", got)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if s := cosineSimilarity([]float64{1, 0}, []float64{2, 0}); math.Abs(s-1) > 1e-9 {
+		t.Errorf("expected 1, got %f", s)
+	}
+	if s := cosineSimilarity([]float64{1, 0}, []float64{0, 1}); s != 0 {
+		t.Errorf("expected 0, got %f", s)
+	}
+	if s := cosineSimilarity([]float64{1}, []float64{1, 2}); s != 0 {
+		t.Errorf("expected mismatched lengths to score 0, got %f", s)
+	}
+}
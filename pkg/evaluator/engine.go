@@ -4,18 +4,55 @@ import (
 	"agentic-llm-gateway/pkg/logger"
 	"context"
 	"fmt"
+	"maps"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"golang.org/x/sync/errgroup"
-
 	"agentic-llm-gateway/internal/models"
 )
 
+// Outcome records how a single evaluator fared during EvaluateAllWithOutcomes
+type Outcome struct {
+	Name       string
+	Score      float64
+	Dimensions map[string]float64 // set instead of Score by multi-dimension evaluators
+	Latency    time.Duration
+	Err        error
+	Skipped    bool // an earlier stage decided the route; any result is discarded
+	Cached     bool // the score came from a Cache
+}
+
 // EvaluateAll executes all configured evaluators concurrently
 func EvaluateAll(ctx context.Context, msgs []models.Message, globalTimeoutMs int, evals []Evaluator) map[string]float64 {
+	results, _ := EvaluateAllWithOutcomes(ctx, msgs, globalTimeoutMs, evals)
+	return results
+}
+
+// EvaluateAllWithOutcomes behaves like EvaluateAll and also reports the score, latency
+// and error of every evaluator, in the order of evals.
+func EvaluateAllWithOutcomes(ctx context.Context, msgs []models.Message, globalTimeoutMs int, evals []Evaluator) (map[string]float64, []Outcome) {
+	return EvaluateStaged(ctx, msgs, globalTimeoutMs, [][]Evaluator{evals}, StageOptions{})
+}
+
+// StageOptions tunes EvaluateStaged.
+type StageOptions struct {
+	// Base holds dimensions known before any evaluator runs; they are part of the
+	// returned vector.
+	Base map[string]float64
+	// Decided is called with the vector after every stage but the last. Returning true
+	// stops the evaluation: later stages are skipped and their in-flight calls cancelled.
+	Decided func(vector map[string]float64) bool
+	// Speculative starts every stage at once instead of each after the previous one,
+	// trading evaluator load for latency when no stage decides early.
+	Speculative bool
+}
+
+// EvaluateStaged runs stages of evaluators in order, the evaluators of a stage
+// concurrently, all within the global timeout. It returns the intent vector and the
+// outcome of every evaluator, in stage order.
+func EvaluateStaged(ctx context.Context, msgs []models.Message, globalTimeoutMs int, stages [][]Evaluator, opts StageOptions) (map[string]float64, []Outcome) {
 	timeout := 10 * time.Second
 	if globalTimeoutMs > 0 {
 		timeout = time.Duration(globalTimeoutMs) * time.Millisecond
@@ -24,29 +61,84 @@ func EvaluateAll(ctx context.Context, msgs []models.Message, globalTimeoutMs int
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	var g errgroup.Group
-	var mu sync.Mutex
-	results := make(map[string]float64)
+	results := make(map[string]float64) // evaluator dimensions only
+	// vector merges Base into results; evaluators override the dimensions they share.
+	vector := func() map[string]float64 {
+		v := make(map[string]float64, len(opts.Base)+len(results))
+		maps.Copy(v, opts.Base)
+		maps.Copy(v, results)
+		return v
+	}
+	offsets := make([]int, len(stages))
+	var outcomes []Outcome
+	for s, stage := range stages {
+		offsets[s] = len(outcomes)
+		for _, ev := range stage {
+			outcomes = append(outcomes, Outcome{Name: ev.Name(), Skipped: true})
+		}
+	}
+
+	running := make([]sync.WaitGroup, len(stages))
+	launched := 0
+	launch := func(s int) {
+		for j, ev := range stages[s] {
+			i := offsets[s] + j
+			running[s].Add(1)
+			go func() {
+				defer running[s].Done()
+				started := time.Now()
+				res, err := ev.Evaluate(ctx, msgs)
+				outcomes[i] = Outcome{Name: ev.Name(), Latency: time.Since(started), Err: err}
+				if err == nil {
+					outcomes[i].Score, outcomes[i].Dimensions, outcomes[i].Cached = res.Score, res.Dimensions, res.Cached
+				}
+			}()
+		}
+		launched = s + 1
+	}
+	if opts.Speculative {
+		for s := range stages {
+			launch(s)
+		}
+	}
 
-	for _, ev := range evals {
-		ev := ev // capture loop variable
-		g.Go(func() error {
-			res, err := ev.Evaluate(ctx, msgs)
-			if err != nil {
-				logger.Warnf("Evaluator %s failed or timed out: %v", ev.Name(), err)
-				return nil // do not fail the group, graceful degradation
+	ran := 0
+	for s := range stages {
+		if s >= launched {
+			launch(s)
+		}
+		running[s].Wait()
+		ran = s + 1
+		for _, o := range outcomes[offsets[s] : offsets[s]+len(stages[s])] {
+			if o.Err != nil {
+				logger.Warnf("Evaluator %s failed or timed out: %v", o.Name, o.Err)
+				continue // graceful degradation
+			}
+			if o.Dimensions != nil {
+				maps.Copy(results, o.Dimensions)
+				continue
 			}
-			mu.Lock()
-			results[ev.Name()] = res.Score
-			mu.Unlock()
-			return nil
-		})
+			results[o.Name] = o.Score
+		}
+		if s < len(stages)-1 && opts.Decided != nil && opts.Decided(vector()) {
+			logger.Infof("[Evaluator] Stage %d decided the route, skipping %d later stage(s)", s+1, len(stages)-ran)
+			break
+		}
 	}
 
-	// Wait ignores errors because we swallowed them above to return partial/best-effort
-	_ = g.Wait()
+	// Cancel the speculative stages still in flight and discard their results
+	cancel()
+	for s := ran; s < launched; s++ {
+		running[s].Wait()
+	}
+	if ran < len(stages) {
+		for i := offsets[ran]; i < len(outcomes); i++ {
+			outcomes[i] = Outcome{Name: outcomes[i].Name, Latency: outcomes[i].Latency, Skipped: true}
+		}
+	}
 
-	// Log the complete intent vector for debugging and strategy evaluation
+	// Log the evaluator results for debugging and strategy evaluation; Base is known to
+	// the caller and must not hide that every evaluator failed
 	if len(results) > 0 {
 		// Sort keys for deterministic output
 		keys := make([]string, 0, len(results))
@@ -60,9 +152,9 @@ func EvaluateAll(ctx context.Context, msgs []models.Message, globalTimeoutMs int
 			parts = append(parts, fmt.Sprintf("%s: %.4f", k, results[k]))
 		}
 		logger.Infof("[Evaluator] Intent Vector: {%s}", strings.Join(parts, ", "))
-	} else {
+	} else if len(outcomes) > 0 {
 		logger.Warnf("[Evaluator] Intent Vector: {} (all evaluators failed or timed out)")
 	}
 
-	return results
+	return vector(), outcomes
 }
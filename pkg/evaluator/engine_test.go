@@ -1,11 +1,15 @@
 package evaluator
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
 	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
 )
 
 // --- stubEvaluator is a minimal in-process evaluator for EvaluateAll tests ---
@@ -85,3 +89,117 @@ func TestEvaluateAll_ZeroTimeout_UsesDefault(t *testing.T) {
 		t.Errorf("expected 1.0, got %v", results["x"])
 	}
 }
+
+func TestEvaluateAllWithOutcomes_ReportsEachEvaluator(t *testing.T) {
+	evals := []Evaluator{
+		&stubEvaluator{name: "slow", score: 0.3, sleepMs: 5},
+		&stubEvaluator{name: "bad", returnErr: true},
+	}
+	results, outcomes := EvaluateAllWithOutcomes(context.Background(), nil, 1000, evals)
+	if len(outcomes) != 2 || outcomes[0].Name != "slow" || outcomes[1].Name != "bad" {
+		t.Fatalf("expected outcomes in evaluator order, got %+v", outcomes)
+	}
+	if outcomes[0].Score != 0.3 || outcomes[0].Err != nil || outcomes[0].Latency < 5*time.Millisecond {
+		t.Errorf("unexpected outcome for slow: %+v", outcomes[0])
+	}
+	if outcomes[1].Err == nil {
+		t.Errorf("expected the error of bad to be reported, got %+v", outcomes[1])
+	}
+	if results["slow"] != 0.3 || len(results) != 1 {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+// blockingEvaluator waits for cancellation and counts its starts.
+type blockingEvaluator struct {
+	name    string
+	started chan struct{}
+}
+
+func (b *blockingEvaluator) Name() string       { return b.name }
+func (b *blockingEvaluator) HistoryRounds() int { return 0 }
+func (b *blockingEvaluator) Evaluate(ctx context.Context, _ []models.Message) (*EvaluationResult, error) {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestEvaluateStaged_ShortCircuits(t *testing.T) {
+	slow := &blockingEvaluator{name: "llm", started: make(chan struct{}, 1)}
+	stages := [][]Evaluator{{&stubEvaluator{name: "code", score: 1}}, {slow}}
+	decided := func(v map[string]float64) bool { return v["code"] == 1 && v["hour"] == 9 }
+
+	results, outcomes := EvaluateStaged(context.Background(), nil, 1000, stages, StageOptions{
+		Base:    map[string]float64{"hour": 9},
+		Decided: decided,
+	})
+	if len(slow.started) != 0 {
+		t.Error("expected the later stage not to start")
+	}
+	if results["code"] != 1 || results["hour"] != 9 || len(results) != 2 {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if outcomes[0].Skipped || !outcomes[1].Skipped || outcomes[1].Name != "llm" {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+}
+
+func TestEvaluateStaged_SpeculativeCancelsInFlight(t *testing.T) {
+	slow := &blockingEvaluator{name: "llm", started: make(chan struct{}, 1)}
+	fast := &stubEvaluator{name: "code", score: 1, sleepMs: 5}
+	started := time.Now()
+	results, outcomes := EvaluateStaged(context.Background(), nil, 5000, [][]Evaluator{{fast}, {slow}}, StageOptions{
+		Decided:     func(map[string]float64) bool { return true },
+		Speculative: true,
+	})
+	if len(slow.started) != 1 {
+		t.Error("expected the later stage to start speculatively")
+	}
+	if time.Since(started) > time.Second {
+		t.Error("expected the in-flight evaluator to be cancelled")
+	}
+	if _, ok := results["llm"]; ok || !outcomes[1].Skipped || outcomes[1].Err != nil {
+		t.Errorf("expected the cancelled evaluator to be skipped, got %v, %+v", results, outcomes[1])
+	}
+}
+
+func TestEvaluateStaged_UndecidedRunsAllStages(t *testing.T) {
+	stages := [][]Evaluator{{&stubEvaluator{name: "a", score: 0.1}}, {&stubEvaluator{name: "b", score: 0.2}}}
+	calls := 0
+	results, outcomes := EvaluateStaged(context.Background(), nil, 1000, stages, StageOptions{
+		Decided: func(map[string]float64) bool { calls++; return false },
+	})
+	if calls != 1 {
+		t.Errorf("expected Decided after every stage but the last, got %d calls", calls)
+	}
+	if results["a"] != 0.1 || results["b"] != 0.2 || outcomes[0].Skipped || outcomes[1].Skipped {
+		t.Errorf("unexpected results %v, outcomes %+v", results, outcomes)
+	}
+}
+
+func TestEvaluateStaged_BaseDoesNotHideFailures(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	logger.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer logger.SetLogger(prev)
+
+	stages := [][]Evaluator{{&stubEvaluator{name: "llm", returnErr: true}}}
+	results, _ := EvaluateStaged(context.Background(), nil, 1000, stages, StageOptions{
+		Base: map[string]float64{"hour": 9, "llm": 0.5},
+	})
+	if !strings.Contains(buf.String(), "all evaluators failed") {
+		t.Errorf("expected the failure to be logged, got: %s", buf.String())
+	}
+	if results["hour"] != 9 || results["llm"] != 0.5 || len(results) != 2 {
+		t.Errorf("expected the base dimensions, got %v", results)
+	}
+
+	// Evaluator results override the base dimensions they share.
+	stages = [][]Evaluator{{&stubEvaluator{name: "llm", score: 1}}}
+	results, _ = EvaluateStaged(context.Background(), nil, 1000, stages, StageOptions{
+		Base: map[string]float64{"hour": 9, "llm": 0.5},
+	})
+	if results["llm"] != 1 || results["hour"] != 9 {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"agentic-llm-gateway/internal/models"
 )
 
-// EvaluationResult stores a single dimension's score
+// EvaluationResult stores a single dimension's score, or the scores of several named
+// dimensions when Dimensions is set
 type EvaluationResult struct {
-	Dimension string
-	Score     float64 // 0.0 ~ 1.0 or binary
+	Dimension  string
+	Score      float64            // 0.0 ~ 1.0 or binary
+	Dimensions map[string]float64 // replaces Score for evaluators emitting several dimensions
+	Cached     bool               // served from a Cache without running the evaluator
 }
 
 // Evaluator defines the interface that all intent detection evaluators must implement
@@ -21,3 +24,36 @@ type Evaluator interface {
 	// Evaluate executes the evaluation logic. messages in the context.
 	Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error)
 }
+
+// MessageScoper is implemented by evaluators that read other messages than the latest one
+// and its HistoryRounds predecessors.
+type MessageScoper interface {
+	// ConsumedMessages returns the messages the evaluation of messages depends on.
+	ConsumedMessages(messages []models.Message) []models.Message
+}
+
+// ConsumedMessages returns the messages ev reads when evaluating messages: the latest one
+// and up to HistoryRounds earlier ones, all of them for a negative HistoryRounds, or
+// what a MessageScoper reports.
+func ConsumedMessages(ev Evaluator, messages []models.Message) []models.Message {
+	if s, ok := ev.(MessageScoper); ok {
+		return s.ConsumedMessages(messages)
+	}
+	return recentMessages(messages, ev.HistoryRounds())
+}
+
+// ReadyWaiter is implemented by evaluators that finish their setup in the background and
+// fail evaluations until then.
+type ReadyWaiter interface {
+	// WaitReady blocks until the evaluator is ready or ctx is done, and returns the error
+	// of a failed setup.
+	WaitReady(ctx context.Context) error
+}
+
+// WaitReady waits for ev to finish its background setup, if it has any.
+func WaitReady(ctx context.Context, ev Evaluator) error {
+	if w, ok := ev.(ReadyWaiter); ok {
+		return w.WaitReady(ctx)
+	}
+	return nil
+}
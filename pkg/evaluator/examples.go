@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"agentic-llm-gateway/internal/models"
+)
+
+// LabeledExample is one line of a labeled dataset: a text, or a conversation, with its
+// expected score, usually 0 or 1.
+type LabeledExample struct {
+	Text     string           `json:"text,omitempty"`
+	Messages []models.Message `json:"messages,omitempty"` // Text defaults to the last message
+	Score    float64          `json:"score"`
+}
+
+// Conversation returns the messages of ex, or its text as a single user message.
+func (ex LabeledExample) Conversation() []models.Message {
+	if len(ex.Messages) > 0 {
+		return ex.Messages
+	}
+	return []models.Message{{Role: "user", Content: ex.Text}}
+}
+
+// LoadExamples reads a JSONL file of labeled examples. Blank lines are skipped.
+func LoadExamples(path string) ([]LabeledExample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open examples: %w", err)
+	}
+	defer f.Close()
+
+	var examples []LabeledExample
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var ex LabeledExample
+		if err := json.Unmarshal(raw, &ex); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if ex.Text == "" && len(ex.Messages) > 0 {
+			ex.Text = ex.Messages[len(ex.Messages)-1].Content
+		}
+		if strings.TrimSpace(ex.Text) == "" {
+			return nil, fmt.Errorf("%s:%d: empty text", path, line)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("no examples in %s", path)
+	}
+	return examples, nil
+}
@@ -8,17 +8,20 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
 	"time"
+	"unicode"
 
 	"agentic-llm-gateway/internal/config"
 	"agentic-llm-gateway/internal/models"
 	"agentic-llm-gateway/pkg/logger"
 )
 
-// LLMLogprobEvaluator uses log probabilities of specific tokens to return a continuous decimal score (float 0.0~1.0).
+// LLMLogprobEvaluator uses log probabilities of its label tokens to return a continuous score: the
+// expected value of the labels, which for the default "0" and "1" labels is P("1") (float 0.0~1.0).
 // When using Ollama protocol (which does not support logprobs), it falls back to content-based parsing.
 type LLMLogprobEvaluator struct {
 	name          string
@@ -28,10 +31,23 @@ type LLMLogprobEvaluator struct {
 	historyRounds int
 	timeoutMs     int
 	logitBias     map[string]int
+	labels        []logprobLabel
+	perLabel      bool // expose the probability of every label as a dimension
+	topLogprobs   int
 	promptTpl     *template.Template
 	client        *http.Client
 }
 
+// logprobLabel is an answer of the model and the value it contributes to the score
+type logprobLabel struct {
+	name      string
+	value     float64
+	spellings []string // normalized label and aliases
+}
+
+// defaultLogprobLabels score the probability of a "1" answer
+var defaultLogprobLabels = []config.LogprobLabelConfig{{Label: "0"}, {Label: "1"}}
+
 func NewLLMLogprobEvaluator(cfg config.EvaluatorConfig) (*LLMLogprobEvaluator, error) {
 	tpl, err := template.New(cfg.Name).Parse(cfg.PromptTemplate)
 	if err != nil {
@@ -48,6 +64,19 @@ func NewLLMLogprobEvaluator(cfg config.EvaluatorConfig) (*LLMLogprobEvaluator, e
 		protocol = "ollama"
 	}
 
+	labelCfgs := cfg.Labels
+	if len(labelCfgs) == 0 {
+		labelCfgs = defaultLogprobLabels
+	}
+	labels, err := newLogprobLabels(labelCfgs)
+	if err != nil {
+		return nil, err
+	}
+	topLogprobs := cfg.TopLogprobs
+	if topLogprobs <= 0 {
+		topLogprobs = min(2*len(labels), 20)
+	}
+
 	return &LLMLogprobEvaluator{
 		name:          cfg.Name,
 		endpoint:      cfg.Endpoint,
@@ -56,6 +85,9 @@ func NewLLMLogprobEvaluator(cfg config.EvaluatorConfig) (*LLMLogprobEvaluator, e
 		historyRounds: cfg.HistoryRounds,
 		timeoutMs:     cfg.TimeoutMs,
 		logitBias:     cfg.LogitBias,
+		labels:        labels,
+		perLabel:      len(cfg.Labels) > 0,
+		topLogprobs:   topLogprobs,
 		promptTpl:     tpl,
 		client: &http.Client{
 			Timeout: timeout,
@@ -63,6 +95,46 @@ func NewLLMLogprobEvaluator(cfg config.EvaluatorConfig) (*LLMLogprobEvaluator, e
 	}, nil
 }
 
+func newLogprobLabels(cfgs []config.LogprobLabelConfig) ([]logprobLabel, error) {
+	if len(cfgs) < 2 {
+		return nil, fmt.Errorf("at least two labels are required")
+	}
+	seen := make(map[string]string)
+	labels := make([]logprobLabel, 0, len(cfgs))
+	for i, lCfg := range cfgs {
+		l := logprobLabel{name: lCfg.Label, value: float64(i) / float64(len(cfgs)-1)}
+		if v, err := strconv.ParseFloat(lCfg.Label, 64); err == nil {
+			l.value = v
+		}
+		if lCfg.Value != nil {
+			l.value = *lCfg.Value
+		}
+		for _, spelling := range append([]string{lCfg.Label}, lCfg.Aliases...) {
+			norm := normalizeLabelToken(spelling)
+			if norm == "" {
+				return nil, fmt.Errorf("label %d has an empty spelling", i)
+			}
+			if prev, dup := seen[norm]; dup {
+				return nil, fmt.Errorf("labels %q and %q share the spelling %q", prev, lCfg.Label, spelling)
+			}
+			seen[norm] = lCfg.Label
+			l.spellings = append(l.spellings, norm)
+		}
+		labels = append(labels, l)
+	}
+	return labels, nil
+}
+
+// LabelDimensions returns the intent vector dimensions of a logprob evaluator with
+// configured labels: its expected value and the probability of every label.
+func LabelDimensions(name string, labels []config.LogprobLabelConfig) []string {
+	dims := []string{name}
+	for _, l := range labels {
+		dims = append(dims, name+"_"+l.Label)
+	}
+	return dims
+}
+
 func (e *LLMLogprobEvaluator) Name() string {
 	return e.name
 }
@@ -161,26 +233,14 @@ func (e *LLMLogprobEvaluator) evaluateOllama(ctx context.Context, prompt string)
 		return nil, fmt.Errorf("empty content in Ollama response")
 	}
 
-	// Fallback: parse content for "0" or "1" as discrete score
-	score, err := strconv.ParseFloat(content[:1], 64)
-	if err != nil {
-		// Try to find the first digit
-		for _, c := range content {
-			if c == '0' || c == '1' {
-				score = float64(c - '0')
-				err = nil
-				break
-			}
-		}
-		if err != nil {
-			return nil, fmt.Errorf("no '0' or '1' found in Ollama content: %q", content)
-		}
+	// Fallback: parse content for a label as discrete score
+	idx := e.labelInContent(content)
+	if idx < 0 {
+		return nil, fmt.Errorf("no label found in Ollama content: %q", content)
 	}
-
-	return &EvaluationResult{
-		Dimension: e.name,
-		Score:     score,
-	}, nil
+	probs := make([]float64, len(e.labels))
+	probs[idx] = 1
+	return e.result(probs), nil
 }
 
 // evaluateOpenAI uses the OpenAI-compatible /v1/chat/completions endpoint with logprobs
@@ -196,7 +256,7 @@ func (e *LLMLogprobEvaluator) evaluateOpenAI(ctx context.Context, prompt string)
 		"temperature":      0.0,
 		"max_tokens":       150, // Allow enough tokens for reasoning models
 		"logprobs":         true,
-		"top_logprobs":     2,
+		"top_logprobs":     e.topLogprobs,
 		"disable_thinking": true,
 		"think":            false,
 	}
@@ -260,51 +320,109 @@ func (e *LLMLogprobEvaluator) evaluateOpenAI(ctx context.Context, prompt string)
 	tokens := openAIResp.Choices[0].Logprobs.Content
 	var topLogprobs []TopLogprob
 
-	// Search backwards for the actual '0' or '1' output token
+	// Search backwards for the actual label output token
 	for i := len(tokens) - 1; i >= 0; i-- {
-		tk := strings.TrimSpace(tokens[i].Token)
-		if tk == "0" || tk == "1" {
+		if e.matchLabel(tokens[i].Token) >= 0 {
 			topLogprobs = tokens[i].TopLogprobs
 			break
 		}
 	}
 
 	if len(topLogprobs) == 0 {
-		return nil, fmt.Errorf("missing '0' or '1' token in logprobs context")
+		return nil, fmt.Errorf("missing label token in logprobs context")
 	}
 
-	val0 := math.Inf(-1)
-	val1 := math.Inf(-1)
-
+	// Softmax over the labels: spellings of the same label (" 1", "1") add up
+	probs := make([]float64, len(e.labels))
+	total := 0.0
 	for _, tlp := range topLogprobs {
-		tk := strings.TrimSpace(tlp.Token)
-		if tk == "0" {
-			val0 = tlp.Logprob
-		} else if tk == "1" {
-			val1 = tlp.Logprob
+		if idx := e.matchLabel(tlp.Token); idx >= 0 {
+			p := math.Exp(tlp.Logprob)
+			probs[idx] += p
+			total += p
 		}
 	}
+	if total == 0 {
+		return nil, fmt.Errorf("no label found in top logprobs")
+	}
+	for i := range probs {
+		probs[i] /= total
+	}
+
+	return e.result(probs), nil
+}
 
-	// Softmax calculation
+// result scores the label distribution probs by its expected value.
+func (e *LLMLogprobEvaluator) result(probs []float64) *EvaluationResult {
 	score := 0.0
-	if val0 == math.Inf(-1) && val1 == math.Inf(-1) {
-		return nil, fmt.Errorf("neither '0' nor '1' found in top logprobs")
-	} else if val0 == math.Inf(-1) {
-		score = 1.0
-	} else if val1 == math.Inf(-1) {
-		score = 0.0
-	} else {
-		maxVal := val0
-		if val1 > val0 {
-			maxVal = val1
+	for i, p := range probs {
+		score += p * e.labels[i].value
+	}
+	res := &EvaluationResult{Dimension: e.name, Score: score}
+	if e.perLabel {
+		res.Dimensions = map[string]float64{e.name: score}
+		for i, l := range e.labels {
+			res.Dimensions[e.name+"_"+l.name] = probs[i]
 		}
-		exp0 := math.Exp(val0 - maxVal)
-		exp1 := math.Exp(val1 - maxVal)
-		score = exp1 / (exp0 + exp1)
 	}
+	return res
+}
 
-	return &EvaluationResult{
-		Dimension: e.name,
-		Score:     score,
-	}, nil
+// normalizeLabelToken strips the word boundary markers of BPE and SentencePiece
+// vocabularies, surrounding whitespace, quotes and punctuation, and lowercases tok.
+func normalizeLabelToken(tok string) string {
+	for _, marker := range []string{"Ġ", "▁", "Ċ"} {
+		tok = strings.ReplaceAll(tok, marker, " ")
+	}
+	return strings.ToLower(strings.Trim(tok, " \t\r\n\"'`.,:;!?*()[]{}"))
+}
+
+// matchLabel returns the index of the label tok spells, or -1. A token may also be the
+// start of exactly one label, as tokenizers split longer words like "medium".
+func (e *LLMLogprobEvaluator) matchLabel(tok string) int {
+	norm := normalizeLabelToken(tok)
+	if norm == "" {
+		return -1
+	}
+	prefixOf := -1
+	for i, l := range e.labels {
+		for _, spelling := range l.spellings {
+			if spelling == norm {
+				return i
+			}
+			if len(norm) >= 2 && strings.HasPrefix(spelling, norm) {
+				if prefixOf >= 0 && prefixOf != i {
+					return -1 // ambiguous
+				}
+				prefixOf = i
+			}
+		}
+	}
+	return prefixOf
+}
+
+// labelInContent returns the index of the label a generated answer starts with, else of
+// the first word naming a label, or -1.
+func (e *LLMLogprobEvaluator) labelInContent(content string) int {
+	lower := strings.ToLower(content)
+	best, bestLen := -1, 0
+	for i, l := range e.labels {
+		for _, spelling := range l.spellings {
+			if len(spelling) > bestLen && strings.HasPrefix(lower, spelling) {
+				best, bestLen = i, len(spelling)
+			}
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	words := strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, w := range words {
+		for i, l := range e.labels {
+			if slices.Contains(l.spellings, w) {
+				return i
+			}
+		}
+	}
+	return -1
 }
@@ -3,8 +3,10 @@ package evaluator
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"agentic-llm-gateway/internal/config"
@@ -94,3 +96,124 @@ func TestLLMLogprobEvaluatorOllama(t *testing.T) {
 		t.Errorf("expected score 1.0, got %v", res.Score)
 	}
 }
+
+// logprobServer answers with the generated tokens, each offering the top alternatives
+// top, and records the requested top_logprobs.
+func logprobServer(t *testing.T, generated []string, top map[string]float64, requested *float64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		*requested, _ = req["top_logprobs"].(float64)
+
+		var alternatives []map[string]interface{}
+		for tok, lp := range top {
+			alternatives = append(alternatives, map[string]interface{}{"token": tok, "logprob": lp})
+		}
+		var content []map[string]interface{}
+		for _, tok := range generated {
+			content = append(content, map[string]interface{}{"token": tok, "top_logprobs": alternatives})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"logprobs": map[string]interface{}{"content": content}}},
+		})
+	}))
+}
+
+func TestLLMLogprobEvaluator_GradedLabels(t *testing.T) {
+	var requested float64
+	// " 7" and "7" are the same label; "Ġ8" is a BPE spelling of "8"
+	srv := logprobServer(t, []string{"Grade", ":", " 7"}, map[string]float64{
+		" 7": math.Log(0.3), "7": math.Log(0.2), "Ġ8": math.Log(0.4), "9": math.Log(0.1), "maybe": math.Log(0.5),
+	}, &requested)
+	defer srv.Close()
+
+	labels := make([]config.LogprobLabelConfig, 10)
+	for i := range labels {
+		labels[i].Label = strconv.Itoa(i)
+	}
+	ev, err := NewLLMLogprobEvaluator(config.EvaluatorConfig{
+		Name: "grade", Protocol: "openai", Endpoint: srv.URL, PromptTemplate: "{{.Current}}", Labels: labels,
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "Hello"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if requested != 20 {
+		t.Errorf("expected 20 top logprobs for 10 labels, requested %v", requested)
+	}
+	// 7*0.5 + 8*0.4 + 9*0.1
+	if math.Abs(res.Score-7.6) > 1e-9 || math.Abs(res.Dimensions["grade"]-7.6) > 1e-9 {
+		t.Errorf("expected expected value 7.6, got %v / %v", res.Score, res.Dimensions["grade"])
+	}
+	if math.Abs(res.Dimensions["grade_7"]-0.5) > 1e-9 || math.Abs(res.Dimensions["grade_8"]-0.4) > 1e-9 || res.Dimensions["grade_0"] != 0 {
+		t.Errorf("unexpected label probabilities %v", res.Dimensions)
+	}
+}
+
+func TestLLMLogprobEvaluator_NamedLabels(t *testing.T) {
+	var requested float64
+	// "med" starts only "medium"; "Hard" is matched case-insensitively
+	srv := logprobServer(t, []string{"med", "ium"}, map[string]float64{
+		"med": math.Log(0.6), "simple": math.Log(0.2), "Hard": math.Log(0.2),
+	}, &requested)
+	defer srv.Close()
+
+	ev, err := NewLLMLogprobEvaluator(config.EvaluatorConfig{
+		Name: "difficulty", Protocol: "openai", Endpoint: srv.URL, PromptTemplate: "{{.Current}}",
+		Labels: []config.LogprobLabelConfig{{Label: "simple"}, {Label: "medium"}, {Label: "hard"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "Hello"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	// values default to 0, 0.5 and 1
+	if math.Abs(res.Score-0.5) > 1e-9 || math.Abs(res.Dimensions["difficulty_medium"]-0.6) > 1e-9 {
+		t.Errorf("unexpected result %v / %v", res.Score, res.Dimensions)
+	}
+}
+
+func TestLLMLogprobEvaluator_OllamaLabels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": map[string]string{"role": "assistant", "content": "**Answer:** Hard."},
+		})
+	}))
+	defer srv.Close()
+
+	five := 5.0
+	ev, err := NewLLMLogprobEvaluator(config.EvaluatorConfig{
+		Name: "difficulty", Endpoint: srv.URL, PromptTemplate: "{{.Current}}",
+		Labels: []config.LogprobLabelConfig{{Label: "easy", Aliases: []string{"simple"}}, {Label: "hard", Value: &five}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "Hello"}})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if res.Score != 5 || res.Dimensions["difficulty_hard"] != 1 || res.Dimensions["difficulty_easy"] != 0 {
+		t.Errorf("unexpected result %v / %v", res.Score, res.Dimensions)
+	}
+}
+
+func TestNewLLMLogprobEvaluator_InvalidLabels(t *testing.T) {
+	for _, labels := range [][]config.LogprobLabelConfig{
+		{{Label: "yes"}},
+		{{Label: "yes"}, {Label: " Yes "}},
+		{{Label: "yes", Aliases: []string{"y"}}, {Label: "no", Aliases: []string{"y"}}},
+		{{Label: "yes"}, {Label: "..."}},
+	} {
+		if _, err := NewLLMLogprobEvaluator(config.EvaluatorConfig{Name: "x", Labels: labels}); err == nil {
+			t.Errorf("%v: expected error", labels)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package evaluator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// Response formats of LLMStructuredEvaluator
+const (
+	ResponseFormatJSONSchema = "json_schema"
+	ResponseFormatJSON       = "json"
+	ResponseFormatNone       = "none"
+)
+
+// LLMStructuredEvaluator asks a model once for a JSON object and turns its validated
+// fields into several intent vector dimensions. The reply can be constrained with the
+// OpenAI response_format or the Ollama format parameter.
+type LLMStructuredEvaluator struct {
+	name           string
+	endpoint       string
+	model          string
+	protocol       string // "ollama" or "openai"
+	historyRounds  int
+	responseFormat string
+	fields         []config.SchemaFieldConfig
+	schema         map[string]interface{}
+	promptTpl      *template.Template
+	client         *http.Client
+}
+
+type structuredTmplData struct {
+	History string
+	Current string
+	Schema  string // the JSON schema of the expected reply
+}
+
+func NewLLMStructuredEvaluator(cfg config.EvaluatorConfig) (*LLMStructuredEvaluator, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if err := validateSchema(cfg.Schema); err != nil {
+		return nil, err
+	}
+	tpl, err := template.New(cfg.Name).Parse(cfg.PromptTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template: %w", err)
+	}
+
+	timeout := 10 * time.Second
+	if cfg.TimeoutMs > 0 {
+		timeout = time.Duration(cfg.TimeoutMs) * time.Millisecond
+	}
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = "ollama"
+	}
+	responseFormat := cfg.ResponseFormat
+	if responseFormat == "" {
+		responseFormat = ResponseFormatJSONSchema
+	}
+	if responseFormat != ResponseFormatJSONSchema && responseFormat != ResponseFormatJSON && responseFormat != ResponseFormatNone {
+		return nil, fmt.Errorf("unknown response format %q", responseFormat)
+	}
+
+	return &LLMStructuredEvaluator{
+		name:           cfg.Name,
+		endpoint:       cfg.Endpoint,
+		model:          cfg.Model,
+		protocol:       protocol,
+		historyRounds:  cfg.HistoryRounds,
+		responseFormat: responseFormat,
+		fields:         cfg.Schema,
+		schema:         jsonSchema(cfg.Schema),
+		promptTpl:      tpl,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}, nil
+}
+
+func (e *LLMStructuredEvaluator) Name() string {
+	return e.name
+}
+
+func (e *LLMStructuredEvaluator) HistoryRounds() int {
+	return e.historyRounds
+}
+
+func (e *LLMStructuredEvaluator) Evaluate(ctx context.Context, messages []models.Message) (*EvaluationResult, error) {
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	schemaJSON, err := json.Marshal(e.schema)
+	if err != nil {
+		return nil, err
+	}
+	data := structuredTmplData{Schema: string(schemaJSON)}
+	data.History, data.Current = historyAndCurrent(messages, e.historyRounds)
+
+	var promptBuf bytes.Buffer
+	if err := e.promptTpl.Execute(&promptBuf, data); err != nil {
+		return nil, fmt.Errorf("template rendering failed: %w", err)
+	}
+
+	var reqBytes []byte
+	if e.protocol == "ollama" {
+		reqBytes, err = e.buildOllamaRequest(promptBuf.String())
+	} else {
+		reqBytes, err = e.buildOpenAIRequest(promptBuf.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("[Evaluator %s] Verbose Input: %s", e.name, string(reqBytes))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LLM API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	logger.Debugf("[Evaluator %s] Verbose Output: %s", e.name, string(bodyBytes))
+
+	var content string
+	if e.protocol == "ollama" {
+		content, err = parseOllamaContent(bodyBytes)
+	} else {
+		content, err = parseOpenAIContent(bodyBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dims, err := parseStructuredReply(content, e.fields)
+	if err != nil {
+		return nil, err
+	}
+	return &EvaluationResult{
+		Dimension:  e.name,
+		Dimensions: dims,
+	}, nil
+}
+
+// buildOllamaRequest constructs an Ollama /api/chat request, constraining the reply
+// with the format parameter
+func (e *LLMStructuredEvaluator) buildOllamaRequest(prompt string) ([]byte, error) {
+	reqBody := map[string]interface{}{
+		"model": e.model,
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"stream": false,
+		"think":  false, // Disable reasoning for Ollama thinking models
+		"options": map[string]interface{}{
+			"temperature": 0.0,
+		},
+	}
+	switch e.responseFormat {
+	case ResponseFormatJSONSchema:
+		reqBody["format"] = e.schema
+	case ResponseFormatJSON:
+		reqBody["format"] = "json"
+	}
+	return json.Marshal(reqBody)
+}
+
+// buildOpenAIRequest constructs an OpenAI /v1/chat/completions request, constraining
+// the reply with response_format
+func (e *LLMStructuredEvaluator) buildOpenAIRequest(prompt string) ([]byte, error) {
+	reqBody := map[string]interface{}{
+		"model": e.model,
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": prompt,
+			},
+		},
+		"temperature":      0.0,
+		"max_tokens":       300,
+		"disable_thinking": true,
+		"think":            false,
+	}
+	switch e.responseFormat {
+	case ResponseFormatJSONSchema:
+		reqBody["response_format"] = map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   e.name,
+				"schema": e.schema,
+				"strict": true,
+			},
+		}
+	case ResponseFormatJSON:
+		reqBody["response_format"] = map[string]string{"type": "json_object"}
+	}
+	return json.Marshal(reqBody)
+}
+
+// SchemaDimensions returns the intent vector dimensions produced by the schema fields.
+func SchemaDimensions(fields []config.SchemaFieldConfig) []string {
+	var dims []string
+	for _, f := range fields {
+		if f.Type == "enum" {
+			for _, v := range f.Values {
+				dims = append(dims, f.Name+"_"+v)
+			}
+			continue
+		}
+		dims = append(dims, f.Name)
+	}
+	return dims
+}
+
+func validateSchema(fields []config.SchemaFieldConfig) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("no schema fields configured")
+	}
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		if f.Name == "" {
+			return fmt.Errorf("schema field without name")
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("duplicate schema field %q", f.Name)
+		}
+		seen[f.Name] = true
+		switch f.Type {
+		case "number":
+			if lo, hi := fieldRange(f); lo >= hi {
+				return fmt.Errorf("schema field %q: min must be below max", f.Name)
+			}
+		case "boolean":
+		case "enum":
+			if len(f.Values) == 0 {
+				return fmt.Errorf("schema field %q: enum without values", f.Name)
+			}
+		default:
+			return fmt.Errorf("schema field %q: unknown type %q", f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+func fieldRange(f config.SchemaFieldConfig) (float64, float64) {
+	lo, hi := 0.0, 1.0
+	if f.Min != nil {
+		lo = *f.Min
+	}
+	if f.Max != nil {
+		hi = *f.Max
+	}
+	return lo, hi
+}
+
+// jsonSchema returns the JSON schema of an object with every field required.
+func jsonSchema(fields []config.SchemaFieldConfig) map[string]interface{} {
+	properties := make(map[string]interface{}, len(fields))
+	required := make([]string, 0, len(fields))
+	for _, f := range fields {
+		prop := map[string]interface{}{}
+		switch f.Type {
+		case "number":
+			lo, hi := fieldRange(f)
+			prop["type"], prop["minimum"], prop["maximum"] = "number", lo, hi
+		case "boolean":
+			prop["type"] = "boolean"
+		case "enum":
+			prop["type"], prop["enum"] = "string", f.Values
+		}
+		if f.Description != "" {
+			prop["description"] = f.Description
+		}
+		properties[f.Name] = prop
+		required = append(required, f.Name)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// parseStructuredReply extracts the JSON object of a reply, tolerating code fences and
+// surrounding prose, and converts the schema fields to dimensions. Missing or invalid
+// fields fail the whole evaluation.
+func parseStructuredReply(content string, fields []config.SchemaFieldConfig) (map[string]float64, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object found in content: %q", content)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &obj); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON object %q: %w", content[start:end+1], err)
+	}
+
+	dims := make(map[string]float64)
+	for _, f := range fields {
+		raw, ok := obj[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("field %q missing from reply", f.Name)
+		}
+		switch f.Type {
+		case "number":
+			v, ok := raw.(float64)
+			if lo, hi := fieldRange(f); !ok || v < lo || v > hi {
+				return nil, fmt.Errorf("field %q: %v is not a number within [%v, %v]", f.Name, raw, lo, hi)
+			}
+			dims[f.Name] = v
+		case "boolean":
+			switch v := raw.(type) {
+			case bool:
+				dims[f.Name] = 0
+				if v {
+					dims[f.Name] = 1
+				}
+			case float64:
+				if v != 0 && v != 1 {
+					return nil, fmt.Errorf("field %q: %v is not a boolean", f.Name, v)
+				}
+				dims[f.Name] = v
+			default:
+				return nil, fmt.Errorf("field %q: %v is not a boolean", f.Name, raw)
+			}
+		case "enum":
+			v, _ := raw.(string)
+			matched := false
+			for _, value := range f.Values {
+				dims[f.Name+"_"+value] = 0
+				if strings.EqualFold(v, value) {
+					dims[f.Name+"_"+value] = 1
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("field %q: %v is not one of %v", f.Name, raw, f.Values)
+			}
+		}
+	}
+	return dims, nil
+}
@@ -0,0 +1,214 @@
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+var structuredSchema = []config.SchemaFieldConfig{
+	{Name: "complexity", Type: "number", Description: "how hard the task is"},
+	{Name: "needs_tools", Type: "boolean"},
+	{Name: "domain", Type: "enum", Values: []string{"code", "math", "chat"}},
+}
+
+// structuredServer replies with content and records the last request body.
+func structuredServer(t *testing.T, protocol, content string, lastReq *map[string]interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(lastReq); err != nil {
+			t.Errorf("bad request body: %v", err)
+		}
+		quoted, _ := json.Marshal(content)
+		if protocol == "ollama" {
+			fmt.Fprintf(w, `{"message":{"role":"assistant","content":%s}}`, quoted)
+			return
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%s}}]}`, quoted)
+	}))
+}
+
+func TestLLMStructuredEvaluator_Protocols(t *testing.T) {
+	want := map[string]float64{
+		"complexity": 0.7, "needs_tools": 1,
+		"domain_code": 1, "domain_math": 0, "domain_chat": 0,
+	}
+	for _, protocol := range []string{"ollama", "openai"} {
+		t.Run(protocol, func(t *testing.T) {
+			var lastReq map[string]interface{}
+			srv := structuredServer(t, protocol, "```json\n{\"complexity\": 0.7, \"needs_tools\": true, \"domain\": \"code\"}\n```", &lastReq)
+			defer srv.Close()
+
+			ev, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{
+				Name:           "intent",
+				Protocol:       protocol,
+				Endpoint:       srv.URL,
+				PromptTemplate: "Reply with {{.Schema}} for: {{.Current}}",
+				Schema:         structuredSchema,
+			})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "fix my build"}})
+			if err != nil {
+				t.Fatalf("unexpected err: %v", err)
+			}
+			if !maps.Equal(res.Dimensions, want) {
+				t.Errorf("dimensions = %v, want %v", res.Dimensions, want)
+			}
+
+			if protocol == "ollama" {
+				if _, ok := lastReq["format"].(map[string]interface{}); !ok {
+					t.Errorf("expected the schema as format, got %v", lastReq["format"])
+				}
+				return
+			}
+			rf, _ := lastReq["response_format"].(map[string]interface{})
+			if rf["type"] != "json_schema" {
+				t.Errorf("expected a json_schema response_format, got %v", lastReq["response_format"])
+			}
+		})
+	}
+}
+
+func TestLLMStructuredEvaluator_ResponseFormats(t *testing.T) {
+	for format, check := range map[string]func(req map[string]interface{}) bool{
+		ResponseFormatJSON: func(req map[string]interface{}) bool {
+			rf, _ := req["response_format"].(map[string]interface{})
+			return rf["type"] == "json_object"
+		},
+		ResponseFormatNone: func(req map[string]interface{}) bool {
+			_, ok := req["response_format"]
+			return !ok
+		},
+	} {
+		var lastReq map[string]interface{}
+		srv := structuredServer(t, "openai", `{"complexity": 0, "needs_tools": 0, "domain": "Chat"}`, &lastReq)
+		ev, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{
+			Name: "intent", Protocol: "openai", Endpoint: srv.URL, ResponseFormat: format, Schema: structuredSchema,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", format, err)
+		}
+		res, err := ev.Evaluate(context.Background(), []models.Message{{Role: "user", Content: "hi"}})
+		srv.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", format, err)
+		}
+		if res.Dimensions["domain_chat"] != 1 || res.Dimensions["needs_tools"] != 0 {
+			t.Errorf("%s: unexpected dimensions %v", format, res.Dimensions)
+		}
+		if !check(lastReq) {
+			t.Errorf("%s: unexpected response_format %v", format, lastReq["response_format"])
+		}
+	}
+}
+
+func TestParseStructuredReply_Invalid(t *testing.T) {
+	for _, content := range []string{
+		`no json here`,
+		`{"complexity": 0.5, "needs_tools": true}`,                      // missing field
+		`{"complexity": 1.5, "needs_tools": true, "domain": "code"}`,    // out of range
+		`{"complexity": "high", "needs_tools": true, "domain": "code"}`, // not a number
+		`{"complexity": 0.5, "needs_tools": 2, "domain": "code"}`,       // not a boolean
+		`{"complexity": 0.5, "needs_tools": true, "domain": "poetry"}`,  // unknown enum value
+	} {
+		if dims, err := parseStructuredReply(content, structuredSchema); err == nil {
+			t.Errorf("%s: expected error, got %v", content, dims)
+		}
+	}
+}
+
+func TestNewLLMStructuredEvaluator_InvalidSchema(t *testing.T) {
+	lo, hi := 1.0, 1.0
+	for _, schema := range [][]config.SchemaFieldConfig{
+		nil,
+		{{Name: "a", Type: "number"}, {Name: "a", Type: "boolean"}},
+		{{Name: "a", Type: "text"}},
+		{{Name: "a", Type: "enum"}},
+		{{Name: "a", Type: "number", Min: &lo, Max: &hi}},
+	} {
+		if _, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{Name: "x", Endpoint: "http://localhost", Schema: schema}); err == nil {
+			t.Errorf("%v: expected error", schema)
+		}
+	}
+	if _, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{Name: "x", Endpoint: "http://localhost", Schema: structuredSchema, ResponseFormat: "xml"}); err == nil {
+		t.Error("expected error for unknown response format")
+	}
+	if _, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{Name: "x", Schema: structuredSchema}); err == nil {
+		t.Error("expected error for missing endpoint")
+	}
+}
+
+func TestLLMStructuredEvaluator_NegativeHistoryRounds(t *testing.T) {
+	var lastReq map[string]interface{}
+	srv := structuredServer(t, "openai", `{"complexity": 0.5, "needs_tools": false, "domain": "chat"}`, &lastReq)
+	defer srv.Close()
+	ev, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{
+		Name:           "intent",
+		Protocol:       "openai",
+		Endpoint:       srv.URL,
+		HistoryRounds:  -1,
+		PromptTemplate: "{{.History}}---{{.Current}}",
+		Schema:         structuredSchema,
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	msgs := []models.Message{
+		{Role: "user", Content: "first"},
+		{Role: "assistant", Content: "second"},
+		{Role: "user", Content: "third"},
+	}
+	if _, err := ev.Evaluate(context.Background(), msgs); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	sent, _ := lastReq["messages"].([]interface{})
+	prompt, _ := sent[0].(map[string]interface{})["content"].(string)
+	if want := "user: first\nassistant: second\n---third"; prompt != want {
+		t.Errorf("prompt = %q, want %q", prompt, want)
+	}
+}
+
+func TestDimensions(t *testing.T) {
+	got := Dimensions(Config{Name: "intent", Type: "llm_structured_api", Schema: structuredSchema})
+	want := []string{"complexity", "needs_tools", "domain_code", "domain_math", "domain_chat"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Dimensions = %v, want %v", got, want)
+	}
+	got = Dimensions(Config{Name: "difficulty", Type: "llm_logprob_api", Labels: []config.LogprobLabelConfig{{Label: "easy"}, {Label: "hard"}}})
+	if want := []string{"difficulty", "difficulty_easy", "difficulty_hard"}; !slices.Equal(got, want) {
+		t.Errorf("Dimensions = %v, want %v", got, want)
+	}
+	if got := Dimensions(Config{Name: "length", Type: "builtin"}); !slices.Equal(got, []string{"length"}) {
+		t.Errorf("Dimensions = %v, want [length]", got)
+	}
+}
+
+func TestEvaluateStaged_MergesDimensions(t *testing.T) {
+	var lastReq map[string]interface{}
+	srv := structuredServer(t, "openai", `{"complexity": 0.2, "needs_tools": false, "domain": "math"}`, &lastReq)
+	defer srv.Close()
+	ev, err := NewLLMStructuredEvaluator(config.EvaluatorConfig{Name: "intent", Protocol: "openai", Endpoint: srv.URL, Schema: structuredSchema})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	vector, outcomes := EvaluateAllWithOutcomes(context.Background(), []models.Message{{Role: "user", Content: "2+2"}}, 1000, []Evaluator{ev})
+	if _, ok := vector["intent"]; ok {
+		t.Errorf("the evaluator name should not be a dimension: %v", vector)
+	}
+	if vector["domain_math"] != 1 || vector["complexity"] != 0.2 || len(vector) != 5 {
+		t.Errorf("unexpected vector %v", vector)
+	}
+	if outcomes[0].Dimensions == nil {
+		t.Error("expected the outcome to carry the dimensions")
+	}
+}
@@ -0,0 +1,138 @@
+package evaluator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/tokenizer"
+)
+
+// Config is the configuration of one evaluator. It aliases the gateway's evaluator
+// config so that factories can be written outside this module.
+type Config = config.EvaluatorConfig
+
+// Deps carries the shared gateway services an evaluator may use.
+type Deps struct {
+	Counter *tokenizer.Counter // may be nil; counting then falls back to estimates
+}
+
+// Factory builds an evaluator from its configuration.
+type Factory func(cfg Config, deps Deps) (Evaluator, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes an evaluator type available to configurations under typ. It is meant
+// to be called from init functions and panics when typ is empty or already registered.
+func Register(typ string, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if typ == "" || f == nil {
+		panic("evaluator: Register needs a type and a factory")
+	}
+	if _, dup := factories[typ]; dup {
+		panic("evaluator: Register called twice for type " + typ)
+	}
+	factories[typ] = f
+}
+
+// Registered reports whether typ has a registered factory.
+func Registered(typ string) bool {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	_, ok := factories[typ]
+	return ok
+}
+
+// Types returns the registered evaluator types in sorted order.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// New builds the evaluator configured by cfg with the factory registered for cfg.Type,
+// applying its calibration.
+func New(cfg Config, deps Deps) (Evaluator, error) {
+	factoriesMu.RLock()
+	f, ok := factories[cfg.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown evaluator type %q (registered: %s)", cfg.Type, strings.Join(Types(), ", "))
+	}
+	if cfg.Calibration == nil {
+		return f(cfg, deps)
+	}
+	if dims := Dimensions(cfg); !slices.Equal(dims, []string{cfg.Name}) {
+		return nil, fmt.Errorf("calibration needs an evaluator with a single score, not dimensions %v", dims)
+	}
+	calibration, err := NewCalibration(*cfg.Calibration)
+	if err != nil {
+		return nil, err
+	}
+	ev, err := f(cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+	return Calibrate(ev, calibration), nil
+}
+
+// Dimensions returns the intent vector dimensions the evaluator configured by cfg emits:
+// one per schema field, and enum value, for structured evaluators, its name and one per
+// label for logprob evaluators with labels, and its name otherwise.
+func Dimensions(cfg Config) []string {
+	switch {
+	case len(cfg.Schema) > 0:
+		return SchemaDimensions(cfg.Schema)
+	case cfg.Type == "llm_logprob_api" && len(cfg.Labels) > 0:
+		return LabelDimensions(cfg.Name, cfg.Labels)
+	}
+	return []string{cfg.Name}
+}
+
+func init() {
+	Register("builtin", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewBuiltinLengthEvaluator(cfg), nil
+	})
+	Register("builtin_tokens", func(cfg Config, deps Deps) (Evaluator, error) {
+		return NewBuiltinTokensEvaluator(cfg, deps.Counter)
+	})
+	Register("builtin_keywords", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewBuiltinKeywordsEvaluator(cfg)
+	})
+	Register("builtin_code", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewBuiltinCodeEvaluator(cfg)
+	})
+	Register("builtin_language", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewBuiltinLanguageEvaluator(cfg)
+	})
+	Register("builtin_attachments", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewBuiltinAttachmentsEvaluator(cfg)
+	})
+	Register("llm_api", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewLLMAPIEvaluator(cfg)
+	})
+	Register("llm_logprob_api", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewLLMLogprobEvaluator(cfg)
+	})
+	Register("llm_structured_api", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewLLMStructuredEvaluator(cfg)
+	})
+	Register("embedding_knn", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewEmbeddingKNNEvaluator(cfg)
+	})
+	Register("classifier", func(cfg Config, _ Deps) (Evaluator, error) {
+		return NewClassifierEvaluator(cfg)
+	})
+}
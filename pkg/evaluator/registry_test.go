@@ -0,0 +1,56 @@
+package evaluator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/models"
+)
+
+type staticEvaluator struct{ name string }
+
+func (s *staticEvaluator) Name() string       { return s.name }
+func (s *staticEvaluator) HistoryRounds() int { return 0 }
+func (s *staticEvaluator) Evaluate(ctx context.Context, msgs []models.Message) (*EvaluationResult, error) {
+	return &EvaluationResult{Dimension: s.name, Score: 1}, nil
+}
+
+func TestRegistry_BuiltinTypes(t *testing.T) {
+	for _, typ := range []string{"builtin", "builtin_tokens", "llm_api", "llm_logprob_api"} {
+		if !Registered(typ) {
+			t.Errorf("expected %s to be registered", typ)
+		}
+	}
+	ev, err := New(Config{Name: "length", Type: "builtin"}, Deps{})
+	if err != nil || ev.Name() != "length" {
+		t.Fatalf("expected the builtin evaluator, got %v, %v", ev, err)
+	}
+	if _, err := New(Config{Name: "judge", Type: "llm_logprob_api", PromptTemplate: "{{"}, Deps{}); err == nil {
+		t.Error("expected the factory's own validation error to be returned")
+	}
+}
+
+func TestRegistry_UnknownType(t *testing.T) {
+	_, err := New(Config{Name: "x", Type: "nope"}, Deps{})
+	if err == nil || !strings.Contains(err.Error(), `"nope"`) || !strings.Contains(err.Error(), "llm_api") {
+		t.Errorf("expected the unknown type and the registered types in the error, got %v", err)
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	Register("test_static", func(cfg Config, _ Deps) (Evaluator, error) {
+		return &staticEvaluator{name: cfg.Name}, nil
+	})
+	ev, err := New(Config{Name: "custom", Type: "test_static"}, Deps{})
+	if err != nil || ev.Name() != "custom" {
+		t.Fatalf("expected the registered evaluator, got %v, %v", ev, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a duplicate registration to panic")
+		}
+	}()
+	Register("test_static", func(Config, Deps) (Evaluator, error) { return nil, nil })
+}
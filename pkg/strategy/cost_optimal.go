@@ -0,0 +1,91 @@
+package strategy
+
+import (
+	"fmt"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/catalog"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// Dimensions injected into the intent vector by the router rather than by evaluators.
+// needs_vision is set when a message embeds or links an image and may be overridden by an
+// evaluator of that name. needs_tools is the exception: requests carry no tool
+// definitions, so an evaluator must emit it, e.g. a structured evaluator field.
+const (
+	DimEstimatedTokens      = "estimated_tokens"
+	DimExpectedOutputTokens = "expected_output_tokens"
+	DimNeedsTools           = "needs_tools"
+	DimNeedsVision          = "needs_vision"
+
+	// Time of the request in the gateway timezone: hour 0-23, weekday 0 (Sunday) to 6,
+	// and the UTC offset of the timezone in hours.
+	DimHour           = "hour"
+	DimWeekday        = "weekday"
+	DimUTCOffsetHours = "utc_offset_hours"
+)
+
+// CostOptimalResolver picks the cheapest catalog model able to serve the request.
+// Capability constraints are read from the intent vector: the prompt estimate and
+// expected output must fit the context window, and needs_tools / needs_vision >= 0.5
+// require the corresponding capability.
+type CostOptimalResolver struct {
+	catalog              *catalog.Catalog
+	defaultProvider      string
+	expectedOutputTokens int
+}
+
+func NewCostOptimalResolver(cfg config.ResolutionStrategyConfig, cat *catalog.Catalog) *CostOptimalResolver {
+	expected := cfg.ExpectedOutputTokens
+	if expected <= 0 {
+		expected = catalog.DefaultOutputTokens
+	}
+	return &CostOptimalResolver{
+		catalog:              cat,
+		defaultProvider:      cfg.DefaultProvider,
+		expectedOutputTokens: expected,
+	}
+}
+
+func (r *CostOptimalResolver) Name() string {
+	return "cost_optimal"
+}
+
+func (r *CostOptimalResolver) Resolve(vector map[string]float64) string {
+	provider, _ := r.ResolveTarget(vector)
+	return provider
+}
+
+// ResolveTarget returns the cheapest satisfying provider/model pair, or the default
+// provider with an empty model when no catalog entry fits.
+func (r *CostOptimalResolver) ResolveTarget(vector map[string]float64) (string, string) {
+	provider, model, _ := r.resolve(vector)
+	return provider, model
+}
+
+// Explain returns the resolved provider and the requirements it was chosen for.
+func (r *CostOptimalResolver) Explain(vector map[string]float64) (string, string) {
+	provider, _, rule := r.resolve(vector)
+	return provider, rule
+}
+
+func (r *CostOptimalResolver) resolve(vector map[string]float64) (provider, model, rule string) {
+	req := catalog.Requirements{
+		PromptTokens: int(vector[DimEstimatedTokens]),
+		OutputTokens: r.expectedOutputTokens,
+		Tools:        vector[DimNeedsTools] >= 0.5,
+		Vision:       vector[DimNeedsVision] >= 0.5,
+	}
+	if v, ok := vector[DimExpectedOutputTokens]; ok && v > 0 {
+		req.OutputTokens = int(v)
+	}
+
+	e, ok := r.catalog.Cheapest(req, nil)
+	if !ok {
+		logger.Warnf("[Strategy] cost_optimal found no catalog model for %+v, using default provider %q", req, r.defaultProvider)
+		return r.defaultProvider, "", fmt.Sprintf("default_provider (no catalog model satisfies %+v)", req)
+	}
+	logger.Debugf("[Strategy] cost_optimal selected %s/%s (estimated $%.6f)",
+		e.Provider, e.Model, catalog.EstimateCost(e, req.PromptTokens, 0, req.OutputTokens))
+	return e.Provider, e.Model, fmt.Sprintf("cheapest catalog model satisfying %+v", req)
+}
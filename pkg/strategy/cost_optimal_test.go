@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/catalog"
+)
+
+func TestCostOptimalResolver(t *testing.T) {
+	cat := catalog.New([]config.ModelCatalogEntry{
+		{Provider: "local_vllm", Model: "qwen-32b", ContextWindow: 32768},
+		{Provider: "deepseek", Model: "deepseek-chat", InputPrice: 0.27, OutputPrice: 1.10, ContextWindow: 65536, Tools: true},
+		{Provider: "google", Model: "gemini-2.5-flash", InputPrice: 0.30, OutputPrice: 2.50, ContextWindow: 1048576, Tools: true, Vision: true},
+	})
+	resolver := NewCostOptimalResolver(config.ResolutionStrategyConfig{
+		Type:            "cost_optimal",
+		DefaultProvider: "openai",
+	}, cat)
+
+	if resolver.Name() != "cost_optimal" {
+		t.Errorf("unexpected name: %q", resolver.Name())
+	}
+
+	tests := []struct {
+		name             string
+		vector           map[string]float64
+		expectedProvider string
+		expectedModel    string
+	}{
+		{"Small prompt stays local", map[string]float64{DimEstimatedTokens: 500}, "local_vllm", "qwen-32b"},
+		{"Expected output overflows local", map[string]float64{DimEstimatedTokens: 30000, DimExpectedOutputTokens: 4096}, "deepseek", "deepseek-chat"},
+		{"Tools required", map[string]float64{DimEstimatedTokens: 500, DimNeedsTools: 1}, "deepseek", "deepseek-chat"},
+		{"Vision required", map[string]float64{DimEstimatedTokens: 500, DimNeedsVision: 0.9}, "google", "gemini-2.5-flash"},
+		{"Nothing fits", map[string]float64{DimEstimatedTokens: 5000000}, "openai", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, m := resolver.ResolveTarget(tc.vector)
+			if p != tc.expectedProvider || m != tc.expectedModel {
+				t.Errorf("expected %s/%s, got %s/%s", tc.expectedProvider, tc.expectedModel, p, m)
+			}
+			if got := resolver.Resolve(tc.vector); got != tc.expectedProvider {
+				t.Errorf("Resolve: expected %s, got %s", tc.expectedProvider, got)
+			}
+		})
+	}
+}
+
+func TestNewResolver_CostOptimal(t *testing.T) {
+	r, err := NewResolver(config.ResolutionStrategyConfig{Type: "cost_optimal"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.(TargetResolver); !ok {
+		t.Fatalf("expected cost_optimal to implement TargetResolver, got %T", r)
+	}
+}
@@ -1,9 +1,13 @@
 package strategy
 
 import (
+	"fmt"
+	"sort"
+
 	"agentic-llm-gateway/pkg/logger"
 
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
 	"github.com/expr-lang/expr/vm"
 
 	"agentic-llm-gateway/internal/config"
@@ -18,30 +22,35 @@ type ExpressionResolver struct {
 // CompiledRule caches the AST or byte code of the parsed condition
 type CompiledRule struct {
 	Program        *vm.Program
+	Condition      string
 	TargetProvider string
+	Dimensions     []string // intent vector dimensions the condition reads
 }
 
-func NewExpressionResolver(cfg config.ResolutionStrategyConfig) *ExpressionResolver {
+// NewExpressionResolver compiles the rule conditions of cfg once. A condition that fails
+// to compile is an error rather than a rule that never fires.
+func NewExpressionResolver(cfg config.ResolutionStrategyConfig) (*ExpressionResolver, error) {
 	var compiledRules []CompiledRule
 
-	for _, rule := range cfg.Rules {
+	for i, rule := range cfg.Rules {
 		// Compile expression once at startup
 		program, err := expr.Compile(rule.Condition, expr.AllowUndefinedVariables())
 		if err != nil {
-			logger.Errorf("[Strategy] Failed to compile expression %q: %v", rule.Condition, err)
-			continue
+			return nil, fmt.Errorf("rule %d (%s): %w", i, rule.Condition, err)
 		}
 
 		compiledRules = append(compiledRules, CompiledRule{
 			Program:        program,
+			Condition:      rule.Condition,
 			TargetProvider: rule.TargetProvider,
+			Dimensions:     conditionDimensions(program),
 		})
 	}
 
 	return &ExpressionResolver{
 		rules:           compiledRules,
 		defaultProvider: cfg.DefaultProvider,
-	}
+	}, nil
 }
 
 func (e *ExpressionResolver) Name() string {
@@ -49,6 +58,13 @@ func (e *ExpressionResolver) Name() string {
 }
 
 func (e *ExpressionResolver) Resolve(vector map[string]float64) string {
+	provider, _ := e.Explain(vector)
+	return provider
+}
+
+// Explain returns the target of the first matching rule and its condition, or the
+// default provider when no rule matches.
+func (e *ExpressionResolver) Explain(vector map[string]float64) (string, string) {
 	env := make(map[string]interface{}, len(vector))
 	for k, v := range vector {
 		env[k] = v
@@ -56,13 +72,62 @@ func (e *ExpressionResolver) Resolve(vector map[string]float64) string {
 
 	for _, rule := range e.rules {
 		matched, err := expr.Run(rule.Program, env)
-		if err == nil {
-			if b, ok := matched.(bool); ok && b {
-				return rule.TargetProvider
+		if err != nil {
+			// Typically a dimension whose evaluator failed or timed out; fall through to the next rule.
+			logger.Warnf("[Strategy] Rule %q failed to evaluate, skipping: %v", rule.Condition, err)
+			continue
+		}
+		if b, ok := matched.(bool); ok && b {
+			return rule.TargetProvider, rule.Condition
+		}
+	}
+
+	return e.defaultProvider, "default_provider (no rule matched)"
+}
+
+// ResolvePartial resolves vector when the dimensions it holds already determine the
+// outcome: every rule before the first matching one, or all rules when the default
+// applies, only read dimensions present in vector.
+func (e *ExpressionResolver) ResolvePartial(vector map[string]float64) (string, bool) {
+	env := make(map[string]interface{}, len(vector))
+	for k, v := range vector {
+		env[k] = v
+	}
+
+	for _, rule := range e.rules {
+		for _, dim := range rule.Dimensions {
+			if _, ok := vector[dim]; !ok {
+				return "", false
 			}
 		}
-		// If evaluation fails (e.g. unknown variable timeout), we just log and fall through to next rule
+		matched, err := expr.Run(rule.Program, env)
+		if err != nil {
+			return "", false
+		}
+		if b, ok := matched.(bool); ok && b {
+			return rule.TargetProvider, true
+		}
 	}
+	return e.defaultProvider, true
+}
 
-	return e.defaultProvider
+// dimensionCollector gathers the identifiers of a condition.
+type dimensionCollector map[string]struct{}
+
+func (c dimensionCollector) Visit(node *ast.Node) {
+	if id, ok := (*node).(*ast.IdentifierNode); ok {
+		c[id.Value] = struct{}{}
+	}
+}
+
+func conditionDimensions(program *vm.Program) []string {
+	c := dimensionCollector{}
+	node := program.Node()
+	ast.Walk(&node, c)
+	dims := make([]string, 0, len(c))
+	for dim := range c {
+		dims = append(dims, dim)
+	}
+	sort.Strings(dims)
+	return dims
 }
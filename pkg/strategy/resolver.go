@@ -1,6 +1,9 @@
 package strategy
 
-import "agentic-llm-gateway/internal/config"
+import (
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/pkg/catalog"
+)
 
 // Resolver defines the unified interface for intent vector resolution strategies
 type Resolver interface {
@@ -11,14 +14,45 @@ type Resolver interface {
 	Resolve(vector map[string]float64) string
 }
 
-// NewResolver initializes a resolver based on the configuration
-func NewResolver(cfg config.ResolutionStrategyConfig) Resolver {
+// TargetResolver is implemented by resolvers that choose a concrete model as well as a provider.
+type TargetResolver interface {
+	Resolver
+	// ResolveTarget returns the target provider and model. An empty model leaves
+	// model selection to the router.
+	ResolveTarget(vector map[string]float64) (provider, model string)
+}
+
+// Explainer is implemented by resolvers that can describe the rule behind a decision.
+type Explainer interface {
+	// Explain resolves vector like Resolve and also returns a human-readable
+	// description of the rule that produced the provider.
+	Explain(vector map[string]float64) (provider, rule string)
+}
+
+// PartialResolver is implemented by resolvers that can decide on an incomplete intent
+// vector, letting staged evaluation skip the remaining evaluators.
+type PartialResolver interface {
+	// ResolvePartial returns the provider and true when the dimensions present in vector
+	// determine the decision whatever the missing dimensions turn out to be.
+	ResolvePartial(vector map[string]float64) (provider string, decided bool)
+}
+
+// NewResolver initializes a resolver based on the configuration. It returns a nil
+// resolver for an unknown type and an error for rules that fail to compile.
+// cat supplies pricing and capabilities to catalog-aware strategies and may be nil.
+func NewResolver(cfg config.ResolutionStrategyConfig, cat *catalog.Catalog) (Resolver, error) {
 	switch cfg.Type {
 	case "dynamic_expression":
-		return NewExpressionResolver(cfg)
+		r, err := NewExpressionResolver(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
 	case "strict_local_first":
-		return NewStrictLocalResolver(cfg)
+		return NewStrictLocalResolver(cfg), nil
+	case "cost_optimal":
+		return NewCostOptimalResolver(cfg, cat), nil
 	default:
-		return nil
+		return nil, nil
 	}
 }
@@ -10,7 +10,10 @@ import (
 
 func TestNewResolver_DynamicExpression(t *testing.T) {
 	cfg := config.ResolutionStrategyConfig{Type: "dynamic_expression"}
-	r := NewResolver(cfg)
+	r, err := NewResolver(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if r == nil {
 		t.Fatal("expected non-nil resolver for dynamic_expression")
 	}
@@ -21,7 +24,10 @@ func TestNewResolver_DynamicExpression(t *testing.T) {
 
 func TestNewResolver_StrictLocalFirst(t *testing.T) {
 	cfg := config.ResolutionStrategyConfig{Type: "strict_local_first", DefaultProvider: "openai"}
-	r := NewResolver(cfg)
+	r, err := NewResolver(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if r == nil {
 		t.Fatal("expected non-nil resolver for strict_local_first")
 	}
@@ -32,7 +38,10 @@ func TestNewResolver_StrictLocalFirst(t *testing.T) {
 
 func TestNewResolver_UnknownType(t *testing.T) {
 	cfg := config.ResolutionStrategyConfig{Type: "nonexistent"}
-	r := NewResolver(cfg)
+	r, err := NewResolver(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	if r != nil {
 		t.Errorf("expected nil resolver for unknown type, got %v", r)
 	}
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"strings"
 	"testing"
 
 	"agentic-llm-gateway/internal/config"
@@ -16,7 +17,10 @@ func TestExpressionResolver(t *testing.T) {
 		DefaultProvider: "openai",
 	}
 
-	resolver := NewExpressionResolver(cfg)
+	resolver, err := NewExpressionResolver(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	tests := []struct {
 		name     string
@@ -59,3 +63,68 @@ func TestExpressionResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestExpressionResolver_Explain(t *testing.T) {
+	resolver, err := NewExpressionResolver(config.ResolutionStrategyConfig{
+		Rules:           []config.ResolutionRuleConfig{{Condition: "complexity == 1", TargetProvider: "claude"}},
+		DefaultProvider: "openai",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, rule := resolver.Explain(map[string]float64{"complexity": 1})
+	if provider != "claude" || rule != "complexity == 1" {
+		t.Errorf("expected claude via its condition, got %s via %q", provider, rule)
+	}
+	provider, rule = resolver.Explain(map[string]float64{"complexity": 0})
+	if provider != "openai" || rule == "" {
+		t.Errorf("expected openai via the default, got %s via %q", provider, rule)
+	}
+}
+
+func TestExpressionResolver_ResolvePartial(t *testing.T) {
+	resolver, err := NewExpressionResolver(config.ResolutionStrategyConfig{
+		Rules: []config.ResolutionRuleConfig{
+			{Condition: "has_code == 1 && estimated_tokens > 1000", TargetProvider: "claude"},
+			{Condition: "complexity > 0.7", TargetProvider: "openai"},
+		},
+		DefaultProvider: "local",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		vector   map[string]float64
+		provider string
+		decided  bool
+	}{
+		{map[string]float64{"has_code": 1, "estimated_tokens": 5000}, "claude", true},
+		{map[string]float64{"has_code": 1}, "", false},
+		{map[string]float64{"has_code": 0, "estimated_tokens": 5000}, "", false},
+		{map[string]float64{"has_code": 0, "estimated_tokens": 5000, "complexity": 0.1}, "local", true},
+		{map[string]float64{"has_code": 0, "estimated_tokens": 5000, "complexity": 0.9}, "openai", true},
+	}
+	for _, c := range cases {
+		provider, decided := resolver.ResolvePartial(c.vector)
+		if provider != c.provider || decided != c.decided {
+			t.Errorf("%v: expected %q/%v, got %q/%v", c.vector, c.provider, c.decided, provider, decided)
+		}
+	}
+}
+
+func TestExpressionResolver_InvalidCondition(t *testing.T) {
+	cfg := config.ResolutionStrategyConfig{
+		Type: "dynamic_expression",
+		Rules: []config.ResolutionRuleConfig{
+			{Condition: "complexity > 0.7", TargetProvider: "google"},
+			{Condition: "complexity >", TargetProvider: "openai"},
+		},
+	}
+	if _, err := NewExpressionResolver(cfg); err == nil || !strings.Contains(err.Error(), "rule 1") {
+		t.Errorf("expected rule 1 to be rejected, got %v", err)
+	}
+	if r, err := NewResolver(cfg, nil); err == nil || r != nil {
+		t.Errorf("expected no resolver and an error, got %v, %v", r, err)
+	}
+}
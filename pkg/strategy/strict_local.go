@@ -19,6 +19,12 @@ func (s *StrictLocalResolver) Name() string {
 }
 
 func (s *StrictLocalResolver) Resolve(vector map[string]float64) string {
+	provider, _ := s.Explain(vector)
+	return provider
+}
+
+// Explain returns the resolved provider and which branch of the built-in rule was taken.
+func (s *StrictLocalResolver) Explain(vector map[string]float64) (string, string) {
 	target := "local_vllm" // hardcoded for this simple built-in rule based on spec
 
 	comp, okC := vector["complexity"]
@@ -26,11 +32,11 @@ func (s *StrictLocalResolver) Resolve(vector map[string]float64) string {
 	lenCheck, okL := vector["length_check"]
 
 	if !okC || !okR || !okL {
-		return s.defaultProvider // Incomplete vector
+		return s.defaultProvider, "default_provider (incomplete vector)" // Incomplete vector
 	}
 
 	if comp == 0.0 && ctxRel == 0.0 && lenCheck == 0.0 {
-		return target
+		return target, "complexity == 0 && context_rel == 0 && length_check == 0"
 	}
-	return s.defaultProvider
+	return s.defaultProvider, "default_provider (non-zero complexity, context_rel or length_check)"
 }
@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/expr-lang/expr"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+// Validate checks a resolution strategy before it is used: rule conditions must compile
+// to booleans over the dimensions in dims, and every referenced provider must be one of
+// providers. All problems are reported together.
+func Validate(cfg config.ResolutionStrategyConfig, dims, providers []string) error {
+	var errs []error
+	checkProvider := func(what, name string) {
+		if name != "" && !slices.Contains(providers, name) {
+			errs = append(errs, fmt.Errorf("%s references unconfigured provider %q", what, name))
+		}
+	}
+
+	switch cfg.Type {
+	case "", "strict_local_first", "cost_optimal":
+	case "dynamic_expression":
+		env := make(map[string]interface{}, len(dims))
+		for _, d := range dims {
+			env[d] = 0.0
+		}
+		for i, rule := range cfg.Rules {
+			what := fmt.Sprintf("rule %d (%s)", i, rule.Condition)
+			if _, err := expr.Compile(rule.Condition, expr.Env(env), expr.AsBool()); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", what, err))
+			}
+			if rule.TargetProvider == "" {
+				errs = append(errs, fmt.Errorf("%s has no target_provider", what))
+			}
+			checkProvider(what, rule.TargetProvider)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown resolution strategy type %q", cfg.Type))
+	}
+	checkProvider("default_provider", cfg.DefaultProvider)
+	return errors.Join(errs...)
+}
+
+// RunTests resolves the vector of every test with r and reports those resolving to a
+// provider other than expected.
+func RunTests(r Resolver, tests []config.ResolutionTestConfig) error {
+	var errs []error
+	for i, tc := range tests {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i)
+		}
+		got := ""
+		if tr, ok := r.(TargetResolver); ok {
+			got, _ = tr.ResolveTarget(tc.Vector)
+		} else if r != nil {
+			got = r.Resolve(tc.Vector)
+		}
+		if got != tc.Expect {
+			errs = append(errs, fmt.Errorf("%s: vector %v resolved to %q, expected %q", name, tc.Vector, got, tc.Expect))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+)
+
+func TestValidate(t *testing.T) {
+	dims := []string{"complexity", "context_dependency"}
+	providers := []string{"local_vllm", "google"}
+
+	valid := config.ResolutionStrategyConfig{
+		Type: "dynamic_expression",
+		Rules: []config.ResolutionRuleConfig{
+			{Condition: "complexity > 0.7 && context_dependency > 0.5", TargetProvider: "google"},
+		},
+		DefaultProvider: "local_vllm",
+	}
+	if err := Validate(valid, dims, providers); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+
+	cases := []struct {
+		name string
+		rule config.ResolutionRuleConfig
+		want string
+	}{
+		{"syntax", config.ResolutionRuleConfig{Condition: "complexity >", TargetProvider: "google"}, "rule 0"},
+		{"typo", config.ResolutionRuleConfig{Condition: "complexty > 0.7", TargetProvider: "google"}, "complexty"},
+		{"not bool", config.ResolutionRuleConfig{Condition: "complexity * 2", TargetProvider: "google"}, "bool"},
+		{"unknown provider", config.ResolutionRuleConfig{Condition: "complexity > 0.7", TargetProvider: "openai"}, `"openai"`},
+		{"no provider", config.ResolutionRuleConfig{Condition: "complexity > 0.7"}, "no target_provider"},
+	}
+	for _, c := range cases {
+		cfg := valid
+		cfg.Rules = []config.ResolutionRuleConfig{c.rule}
+		err := Validate(cfg, dims, providers)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected an error mentioning %s, got %v", c.name, c.want, err)
+		}
+	}
+
+	if err := Validate(config.ResolutionStrategyConfig{Type: "magic"}, dims, providers); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+	if err := Validate(config.ResolutionStrategyConfig{Type: "strict_local_first", DefaultProvider: "openai"}, dims, providers); err == nil {
+		t.Error("expected an unknown default_provider to be rejected")
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	err := Validate(config.ResolutionStrategyConfig{
+		Type: "dynamic_expression",
+		Rules: []config.ResolutionRuleConfig{
+			{Condition: "complexty > 0.7", TargetProvider: "google"},
+			{Condition: "complexity > 0.2", TargetProvider: "openai"},
+		},
+	}, []string{"complexity"}, []string{"google"})
+	if err == nil || !strings.Contains(err.Error(), "rule 0") || !strings.Contains(err.Error(), "rule 1") {
+		t.Errorf("expected both rules to be reported, got %v", err)
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	cfg := config.ResolutionStrategyConfig{
+		Type:            "dynamic_expression",
+		Rules:           []config.ResolutionRuleConfig{{Condition: "complexity > 0.7", TargetProvider: "google"}},
+		DefaultProvider: "local_vllm",
+	}
+	r, err := NewResolver(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass := []config.ResolutionTestConfig{
+		{Name: "hard", Vector: map[string]float64{"complexity": 0.9}, Expect: "google"},
+		{Name: "easy", Vector: map[string]float64{"complexity": 0.1}, Expect: "local_vllm"},
+	}
+	if err := RunTests(r, pass); err != nil {
+		t.Errorf("expected the tests to pass, got %v", err)
+	}
+
+	fail := []config.ResolutionTestConfig{{Vector: map[string]float64{"complexity": 0.5}, Expect: "google"}}
+	if err := RunTests(r, fail); err == nil || !strings.Contains(err.Error(), "test 0") {
+		t.Errorf("expected test 0 to fail, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Pre-tokenizer patterns of the tiktoken encodings. Go's RE2 engine has no lookahead, so the
+// trailing `\s+(?!\S)` alternative is emulated in Encoding.split instead.
+const (
+	cl100kPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`
+	o200kPattern  = `[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?` +
+		`|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?` +
+		`|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+`
+)
+
+// maxPieceBytes bounds the input of a single merge loop. Longer pre-tokenized pieces
+// (base64 blobs, minified code) are merged in windows to keep counting linear.
+const maxPieceBytes = 512
+
+// Encoding is a byte-level BPE vocabulary in tiktoken format.
+type Encoding struct {
+	name    string
+	ranks   map[string]int
+	pattern *regexp.Regexp
+}
+
+// NewEncoding builds an encoding from mergeable ranks. The pre-tokenizer follows
+// o200k_base for encodings named "o200k*" and cl100k_base otherwise.
+func NewEncoding(name string, ranks map[string]int) *Encoding {
+	pattern := cl100kPattern
+	if strings.HasPrefix(name, "o200k") {
+		pattern = o200kPattern
+	}
+	return &Encoding{
+		name:    name,
+		ranks:   ranks,
+		pattern: regexp.MustCompile(pattern),
+	}
+}
+
+// LoadEncoding reads a .tiktoken rank file, where each line holds a base64 token and its rank.
+func LoadEncoding(name, path string) (*Encoding, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<base64 token> <rank>\"", path, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid token: %w", path, line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rank: %w", path, line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewEncoding(name, ranks), nil
+}
+
+// Name returns the encoding identifier, e.g. "cl100k_base".
+func (enc *Encoding) Name() string {
+	return enc.name
+}
+
+// Encode returns the token ranks of text. Special tokens are treated as plain text.
+func (enc *Encoding) Encode(text string) []int {
+	var out []int
+	for _, piece := range enc.split(text) {
+		for len(piece) > maxPieceBytes {
+			cut := maxPieceBytes
+			for cut > 0 && !utf8.RuneStart(piece[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				// invalid UTF-8 without a rune start in the window
+				cut = maxPieceBytes
+			}
+			out = enc.mergePiece(piece[:cut], out)
+			piece = piece[cut:]
+		}
+		out = enc.mergePiece(piece, out)
+	}
+	return out
+}
+
+// Count returns the number of tokens in text.
+func (enc *Encoding) Count(text string) int {
+	return len(enc.Encode(text))
+}
+
+// split applies the pre-tokenizer. A whitespace run without line breaks that is followed by
+// a non-space character gives up its last character to the next piece, mirroring
+// tiktoken's `\s+(?!\S)` alternative. Runs ending in a line break come from `\s*[\r\n]+`
+// and are kept whole.
+func (enc *Encoding) split(text string) []string {
+	var pieces []string
+	for pos := 0; pos < len(text); {
+		loc := enc.pattern.FindStringIndex(text[pos:])
+		if loc == nil {
+			pieces = append(pieces, text[pos:])
+			break
+		}
+		if loc[0] > 0 {
+			pieces = append(pieces, text[pos:pos+loc[0]])
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if end < len(text) && text[end-1] != '\n' && text[end-1] != '\r' && isSpaceRun(text[start:end]) {
+			_, last := utf8.DecodeLastRuneInString(text[start:end])
+			if end-last > start {
+				end -= last
+			}
+		}
+		pieces = append(pieces, text[start:end])
+		pos = end
+	}
+	return pieces
+}
+
+// mergePiece runs byte-pair merges over piece, appending the resulting ranks to out.
+func (enc *Encoding) mergePiece(piece string, out []int) []int {
+	if rank, ok := enc.ranks[piece]; ok {
+		return append(out, rank)
+	}
+
+	// parts holds the boundaries of the current symbols within piece.
+	parts := make([]int, len(piece)+1)
+	for i := range parts {
+		parts[i] = i
+	}
+	for len(parts) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(parts); i++ {
+			if rank, ok := enc.ranks[piece[parts[i]:parts[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	for i := 0; i+1 < len(parts); i++ {
+		rank, ok := enc.ranks[piece[parts[i]:parts[i+1]]]
+		if !ok {
+			// Every byte is a base token in a complete vocabulary; count unknown bytes as one token.
+			rank = -1
+		}
+		out = append(out, rank)
+	}
+	return out
+}
+
+func isSpaceRun(s string) bool {
+	for _, r := range s {
+		if !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// encodingCache shares loaded vocabularies between all counters, keyed by file path.
+var encodingCache sync.Map // map[string]*cachedEncoding
+
+type cachedEncoding struct {
+	once sync.Once
+	enc  *Encoding
+	err  error
+}
+
+// loadEncodingCached loads path at most once per process.
+func loadEncodingCached(name, path string) (*Encoding, error) {
+	v, _ := encodingCache.LoadOrStore(path, &cachedEncoding{})
+	c := v.(*cachedEncoding)
+	c.once.Do(func() {
+		c.enc, c.err = LoadEncoding(name, path)
+	})
+	return c.enc, c.err
+}
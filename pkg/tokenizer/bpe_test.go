@@ -0,0 +1,117 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testRanks returns a toy vocabulary: every single byte plus a few merges.
+func testRanks() map[string]int {
+	ranks := make(map[string]int, 260)
+	for b := 0; b < 256; b++ {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	ranks["he"] = 256
+	ranks["ll"] = 257
+	ranks["hell"] = 258
+	ranks["hello"] = 259
+	ranks[" w"] = 260
+	return ranks
+}
+
+func writeVocab(t *testing.T, dir, name string, ranks map[string]int) {
+	t.Helper()
+	var sb strings.Builder
+	for tok, rank := range ranks {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), rank)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".tiktoken"), []byte(sb.String()), 0644); err != nil {
+		t.Fatalf("failed to write vocab: %v", err)
+	}
+}
+
+func TestEncoding_Split(t *testing.T) {
+	enc := NewEncoding("cl100k_base", testRanks())
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"hello world", []string{"hello", " world"}},
+		{"a   b", []string{"a", "  ", " b"}},
+		{"hi\n\nthere", []string{"hi", "\n\n", "there"}},
+		{"x = 12345;", []string{"x", " =", " ", "123", "45", ";"}},
+		{"it's", []string{"it", "'s"}},
+		{"trailing  ", []string{"trailing", "  "}},
+	}
+	for _, tc := range tests {
+		if got := enc.split(tc.input); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("split(%q): expected %q, got %q", tc.input, tc.expected, got)
+		}
+	}
+}
+
+func TestEncoding_Encode(t *testing.T) {
+	enc := NewEncoding("cl100k_base", testRanks())
+
+	// "hello" is a single token; " world" merges " w" then leaves single bytes.
+	got := enc.Encode("hello world")
+	expected := []int{259, 260, 'o', 'r', 'l', 'd'}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// "hellx" merges he + ll then stops, since "hellx" is not in the vocabulary.
+	got = enc.Encode("hellx")
+	expected = []int{258, 'x'}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	if enc.Count("") != 0 {
+		t.Error("expected empty text to have no tokens")
+	}
+}
+
+func TestEncoding_LongPieceIsWindowed(t *testing.T) {
+	enc := NewEncoding("cl100k_base", testRanks())
+	long := strings.Repeat("z", 3*maxPieceBytes+7)
+	if got := enc.Count(long); got != len(long) {
+		t.Errorf("expected %d single-byte tokens, got %d", len(long), got)
+	}
+}
+
+func TestEncoding_LongInvalidUTF8(t *testing.T) {
+	enc := NewEncoding("cl100k_base", testRanks())
+	long := strings.Repeat("\x80", 3*maxPieceBytes)
+	if got := enc.Count(long); got != len(long) {
+		t.Errorf("expected %d single-byte tokens, got %d", len(long), got)
+	}
+}
+
+func TestLoadEncoding(t *testing.T) {
+	dir := t.TempDir()
+	writeVocab(t, dir, "toy", testRanks())
+
+	enc, err := LoadEncoding("toy", filepath.Join(dir, "toy.tiktoken"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enc.Name() != "toy" || enc.Count("hello") != 1 {
+		t.Errorf("unexpected encoding %s with count %d", enc.Name(), enc.Count("hello"))
+	}
+
+	bad := filepath.Join(dir, "bad.tiktoken")
+	os.WriteFile(bad, []byte("not-a-valid-line\n"), 0644)
+	if _, err := LoadEncoding("bad", bad); err == nil {
+		t.Error("expected error for malformed vocabulary")
+	}
+
+	if _, err := LoadEncoding("missing", filepath.Join(dir, "missing.tiktoken")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,91 @@
+package tokenizer
+
+import (
+	"path"
+	"path/filepath"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+	"agentic-llm-gateway/pkg/logger"
+)
+
+// defaultFamilies maps well-known model names to their tiktoken vocabulary.
+var defaultFamilies = []config.TokenizerFamilyConfig{
+	{Match: "gpt-4o*", Encoding: "o200k_base"},
+	{Match: "chatgpt-4o*", Encoding: "o200k_base"},
+	{Match: "gpt-4.1*", Encoding: "o200k_base"},
+	{Match: "gpt-4.5*", Encoding: "o200k_base"},
+	{Match: "gpt-5*", Encoding: "o200k_base"},
+	{Match: "o1*", Encoding: "o200k_base"},
+	{Match: "o3*", Encoding: "o200k_base"},
+	{Match: "o4*", Encoding: "o200k_base"},
+	{Match: "gpt-4*", Encoding: "cl100k_base"},
+	{Match: "gpt-3.5*", Encoding: "cl100k_base"},
+	{Match: "text-embedding-*", Encoding: "cl100k_base"},
+}
+
+// Counter counts prompt tokens with the vocabulary of the target model family and falls
+// back to EstimateMessages when no vocabulary is configured or available.
+// A nil *Counter always uses the character-based estimate.
+type Counter struct {
+	vocabDir string
+	families []config.TokenizerFamilyConfig
+}
+
+// NewCounter builds a counter from the tokenizer section of the config.
+// Vocabularies are loaded lazily and shared process-wide.
+func NewCounter(cfg config.TokenizerConfig) *Counter {
+	families := make([]config.TokenizerFamilyConfig, 0, len(cfg.Families)+len(defaultFamilies))
+	families = append(families, cfg.Families...)
+	families = append(families, defaultFamilies...)
+	return &Counter{
+		vocabDir: cfg.VocabDir,
+		families: families,
+	}
+}
+
+// EncodingFor returns the vocabulary used for model, or nil when counting falls back
+// to the character estimate.
+func (c *Counter) EncodingFor(model string) *Encoding {
+	if c == nil || c.vocabDir == "" {
+		return nil
+	}
+	for _, f := range c.families {
+		if ok, _ := path.Match(f.Match, model); !ok {
+			continue
+		}
+		enc, err := loadEncodingCached(f.Encoding, filepath.Join(c.vocabDir, f.Encoding+".tiktoken"))
+		if err != nil {
+			logger.Debugf("[Tokenizer] Vocabulary %s unavailable for model %s, using estimate: %v", f.Encoding, model, err)
+			return nil
+		}
+		return enc
+	}
+	return nil
+}
+
+// CountText returns the token count of text for model.
+func (c *Counter) CountText(model, text string) int {
+	if enc := c.EncodingFor(model); enc != nil {
+		return enc.Count(text)
+	}
+	return EstimateText(text)
+}
+
+// CountMessages returns the prompt token count of msgs for model, including the
+// chat template overhead of every message.
+func (c *Counter) CountMessages(model string, msgs []models.Message) int {
+	enc := c.EncodingFor(model)
+	if enc == nil {
+		return EstimateMessages(msgs)
+	}
+	if len(msgs) == 0 {
+		return 0
+	}
+	total := replyPrimingTokens
+	for _, m := range msgs {
+		// The template wraps each message in start/end markers around its role.
+		total += messageOverheadTokens - 1 + enc.Count(m.Role) + enc.Count(m.Content)
+	}
+	return total
+}
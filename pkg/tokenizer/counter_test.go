@@ -0,0 +1,66 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"agentic-llm-gateway/internal/config"
+	"agentic-llm-gateway/internal/models"
+)
+
+func TestCounter_FallsBackWithoutVocabDir(t *testing.T) {
+	c := NewCounter(config.TokenizerConfig{})
+	if c.EncodingFor("gpt-4o") != nil {
+		t.Error("expected no encoding without a vocab dir")
+	}
+	msgs := []models.Message{{Role: "user", Content: "hello world"}}
+	if got, want := c.CountMessages("gpt-4o", msgs), EstimateMessages(msgs); got != want {
+		t.Errorf("expected estimate %d, got %d", want, got)
+	}
+
+	var nilCounter *Counter
+	if got := nilCounter.CountText("gpt-4o", "abcd"); got != 1 {
+		t.Errorf("expected nil counter to estimate, got %d", got)
+	}
+}
+
+func TestCounter_FamilyMapping(t *testing.T) {
+	dir := t.TempDir()
+	writeVocab(t, dir, "o200k_base", testRanks())
+	writeVocab(t, dir, "qwen", testRanks())
+
+	c := NewCounter(config.TokenizerConfig{
+		VocabDir: dir,
+		Families: []config.TokenizerFamilyConfig{{Match: "qwen*", Encoding: "qwen"}},
+	})
+
+	tests := []struct {
+		model    string
+		encoding string
+	}{
+		{"gpt-4o-mini", "o200k_base"},
+		{"gpt-5", "o200k_base"},
+		{"qwen3-14b-awq", "qwen"},
+		{"gpt-4-turbo", ""}, // cl100k_base is mapped but not present in the vocab dir
+		{"llama-3", ""},     // unknown family
+	}
+	for _, tc := range tests {
+		enc := c.EncodingFor(tc.model)
+		got := ""
+		if enc != nil {
+			got = enc.Name()
+		}
+		if got != tc.encoding {
+			t.Errorf("model %s: expected encoding %q, got %q", tc.model, tc.encoding, got)
+		}
+	}
+
+	if got := c.CountText("qwen3", "hello"); got != 1 {
+		t.Errorf("expected BPE count 1, got %d", got)
+	}
+
+	msgs := []models.Message{{Role: "user", Content: "hello"}}
+	// reply priming (3) + template overhead (3) + "user" (4 single bytes) + "hello" (1)
+	if got := c.CountMessages("qwen3", msgs); got != 11 {
+		t.Errorf("expected 11, got %d", got)
+	}
+}
@@ -0,0 +1,49 @@
+package tokenizer
+
+import (
+	"unicode/utf8"
+
+	"agentic-llm-gateway/internal/models"
+)
+
+const (
+	// messageOverheadTokens approximates the role/separator tokens chat templates add per message.
+	messageOverheadTokens = 4
+	// replyPrimingTokens approximates the tokens that prime the assistant reply.
+	replyPrimingTokens = 3
+	// asciiCharsPerToken is the usual ratio of English text and code to BPE tokens.
+	asciiCharsPerToken = 4
+)
+
+// EstimateText approximates the token count of s without a vocabulary.
+// ASCII runs are counted at roughly four characters per token, while every
+// non-ASCII rune (CJK, emoji, ...) is counted as one token, which matches
+// common BPE vocabularies within a reasonable margin.
+func EstimateText(s string) int {
+	ascii := 0
+	other := 0
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		other++
+		i += size
+	}
+	return (ascii+asciiCharsPerToken-1)/asciiCharsPerToken + other
+}
+
+// EstimateMessages approximates the prompt token count of a whole conversation,
+// including the per-message chat template overhead.
+func EstimateMessages(msgs []models.Message) int {
+	if len(msgs) == 0 {
+		return 0
+	}
+	total := replyPrimingTokens
+	for _, m := range msgs {
+		total += messageOverheadTokens + EstimateText(m.Content)
+	}
+	return total
+}
@@ -0,0 +1,44 @@
+package tokenizer
+
+import (
+	"testing"
+
+	"agentic-llm-gateway/internal/models"
+)
+
+func TestEstimateText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected int
+	}{
+		{"Empty", "", 0},
+		{"Short ASCII", "hi", 1},
+		{"Exact multiple", "abcdefgh", 2},
+		{"Rounded up", "abcdefghi", 3},
+		{"CJK runes", "你好世界", 4},
+		{"Mixed", "hello 世界", 2 + 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EstimateText(tc.input); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestEstimateMessages(t *testing.T) {
+	if got := EstimateMessages(nil); got != 0 {
+		t.Errorf("expected 0 for no messages, got %d", got)
+	}
+
+	msgs := []models.Message{
+		{Role: "system", Content: "abcd"},
+		{Role: "user", Content: "abcdefgh"},
+	}
+	// reply priming (3) + 2 * overhead (4) + 1 + 2
+	if got := EstimateMessages(msgs); got != 14 {
+		t.Errorf("expected 14, got %d", got)
+	}
+}